@@ -0,0 +1,100 @@
+// Command rest-layer-gen generates a typed Go client for a REST Layer API.
+//
+// The resource index is loaded from a Go package of the current module, which
+// must expose it either as a package level variable or as a function returning
+// it. The command is meant to be used with go generate:
+//
+//	//go:generate go run github.com/rs/rest-layer/cmd/rest-layer-gen -index github.com/me/api.Index() -package apiclient -o client_gen.go
+//
+// Under the hood, a temporary program importing the package is built and run
+// in the current directory, so the package is resolved by the current module.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var program = template.Must(template.New("main").Parse(`package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/rest/client/clientgen"
+
+	api {{printf "%q" .Import}}
+)
+
+func main() {
+	var index resource.Index = api.{{.Symbol}}
+	conf := clientgen.Config{Package: {{printf "%q" .Package}}}
+	if err := clientgen.Generate(os.Stdout, index, conf); err != nil {
+		log.Fatal(err)
+	}
+}
+`))
+
+func main() {
+	index := flag.String("index", "", "The `import/path.Symbol` of the resource index; append () if Symbol is a function.")
+	pkg := flag.String("package", "client", "The name of the generated package.")
+	out := flag.String("o", "", "The output file (default stdout).")
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("rest-layer-gen: ")
+
+	i := strings.LastIndexByte(*index, '.')
+	if i <= 0 || i == len(*index)-1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	src, err := generate((*index)[:i], (*index)[i+1:], *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate builds and runs a program calling clientgen.Generate on the index
+// exposed as symbol by the package at importPath.
+func generate(importPath, symbol, pkg string) ([]byte, error) {
+	dir, err := ioutil.TempDir(".", "rest-layer-gen")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	var prog bytes.Buffer
+	err = program.Execute(&prog, struct {
+		Import, Symbol, Package string
+	}{importPath, symbol, pkg})
+	if err != nil {
+		return nil, err
+	}
+	main := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(main, prog.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", main)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
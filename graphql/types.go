@@ -65,7 +65,7 @@ func getSubFieldResolver(parentField string, r *resource.Resource, f schema.Fiel
 	s, serialize := f.Validator.(schema.FieldSerializer)
 	return func(p graphql.ResolveParams) (data interface{}, err error) {
 		parent, ok := p.Source.(map[string]interface{})
-		if !ok {
+		if !ok || !f.Readable.Allowed(p.Context) {
 			return nil, nil
 		}
 		var item *resource.Item
@@ -155,12 +155,12 @@ func getFArgs(p schema.Params) graphql.FieldConfigArgument {
 // getFResolver returns a GraphQL field resolver for REST layer field handler.
 func getFResolver(fieldName string, f schema.Field) graphql.FieldResolveFn {
 	s, serialize := f.Validator.(schema.FieldSerializer)
-	if !serialize && f.Handler == nil {
+	if !serialize && f.Handler == nil && f.Readable == nil {
 		return nil
 	}
 	return func(rp graphql.ResolveParams) (interface{}, error) {
 		data, ok := rp.Source.(map[string]interface{})
-		if !ok {
+		if !ok || !f.Readable.Allowed(rp.Context) {
 			return nil, nil
 		}
 		var err error
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// scanPageSize is the number of items fetched at once when the items of a
+// storer are scanned, for instance to aggregate them in memory.
+const scanPageSize = 1000
+
+// scan calls fn with the items matching the predicate of q, fetched page by
+// page using Find and sorted as q. The items are also sorted by id so pages
+// neither overlap nor miss items. If more than maxScan items match, ErrScanLimit
+// is returned.
+func (s storageWrapper) scan(ctx context.Context, q *query.Query, fn func(item *Item)) error {
+	sq := &query.Query{Predicate: q.Predicate, Sort: q.Sort}
+	hasID := false
+	for _, sf := range q.Sort {
+		hasID = hasID || sf.Name == "id"
+	}
+	if !hasID {
+		sq.Sort = append(sq.Sort[:len(sq.Sort):len(sq.Sort)], query.SortField{Name: "id"})
+	}
+	pageSize := scanPageSize
+	if s.maxScan > 0 && s.maxScan < pageSize {
+		// Fetch one more item to detect when the limit is exceeded.
+		pageSize = s.maxScan + 1
+	}
+	for offset := 0; ; offset += pageSize {
+		sq.Window = &query.Window{Offset: offset, Limit: pageSize}
+		list, err := s.Storer.Find(ctx, sq)
+		if err != nil {
+			return err
+		}
+		if s.maxScan > 0 && offset+len(list.Items) > s.maxScan {
+			return ErrScanLimit
+		}
+		for _, item := range list.Items {
+			fn(item)
+		}
+		if len(list.Items) < pageSize {
+			return nil
+		}
+	}
+}
+
+// Aggregator is an optional interface a Storer can implement to compute
+// aggregations natively.
+//
+// When the storer doesn't implement this interface or returns
+// ErrNotImplemented, the items matching the query are fetched page by page
+// using Find, sorted by id, and aggregated in memory. The number of items
+// scanned is capped by the MaxScan query limit of the resource.
+type Aggregator interface {
+	// Aggregate computes the aggregation a over the items matching the
+	// predicate of q. The result must follow the format described by
+	// query.Aggregation, groups being filtered by its Having predicate.
+	Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error)
+}
+
+// Aggregate computes the aggregation a over the items matching q, with the
+// same hooks and restrictions as Find. Only the predicate of q is considered.
+// As aggregations don't return items, the OnFound hooks get an empty list.
+func (r *Resource) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) (groups []map[string]interface{}, err error) {
+	if LoggerLevel <= LogLevelDebug && Logger != nil {
+		defer func(t time.Time) {
+			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.Aggregate(...)", r.path), map[string]interface{}{
+				"duration": time.Since(t),
+				"groups":   len(groups),
+				"error":    err,
+			})
+		}(time.Now())
+	}
+	if err = r.hooks.onFind(ctx, q); err == nil {
+		var visible query.Predicate
+		if visible, err = r.restriction(ctx, Read); err == nil {
+			groups, err = r.storage.Aggregate(ctx, scope(q, visible), a)
+		}
+	}
+	list := &ItemList{Total: -1, Items: []*Item{}}
+	r.hooks.onFound(ctx, q, &list, &err)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Aggregate uses the storer Aggregate method if implemented, or aggregates the
+// items returned by Find otherwise.
+func (s storageWrapper) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) (groups []map[string]interface{}, err error) {
+	if s.Storer == nil {
+		return nil, ErrNoStorage
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if ctx, _, err = s.session(ctx); err != nil {
+		return nil, err
+	}
+	if ag, ok := s.Storer.(Aggregator); ok {
+		groups, err := ag.Aggregate(ctx, &query.Query{Predicate: q.Predicate}, a)
+		if err != ErrNotImplemented {
+			return groups, err
+		}
+	}
+	acc := a.Accumulate()
+	err = s.scan(ctx, &query.Query{Predicate: q.Predicate}, func(item *Item) {
+		acc.Add(item.Payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acc.Results(), nil
+}
@@ -0,0 +1,102 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAggregator struct {
+	*testMStorer
+	queries []*query.Query
+}
+
+func (s *testAggregator) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error) {
+	s.queries = append(s.queries, q)
+	return []map[string]interface{}{{"count": 42}}, nil
+}
+
+func TestAggregate(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "org": "acme", "status": "paid"},
+		map[string]interface{}{"id": "2", "org": "acme", "status": "paid"},
+		map[string]interface{}{"id": "3", "org": "acme", "status": "draft"},
+		map[string]interface{}{"id": "4", "org": "initech", "status": "paid"},
+	)
+	s := schema.Schema{Fields: schema.Fields{
+		"id":     {},
+		"org":    {ReadOnly: true},
+		"status": {Filterable: true},
+	}}
+	conf := Conf{AllowedModes: ReadWrite, TenantField: "org", TenantFunc: tenantFromContext}
+	native := &testAggregator{testMStorer: newTestMStorer()}
+	index := NewIndex()
+	orders := index.Bind("orders", s, newIntegrityTestStorer(items), conf)
+	invoices := index.Bind("invoices", s, native, conf)
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	a := &query.Aggregation{
+		Group:        []string{"status"},
+		Accumulators: []query.Accumulator{{Func: query.AccumulatorCount}},
+	}
+
+	// Items are aggregated in memory within the visible items.
+	groups, err := orders.Aggregate(ctx, &query.Query{}, a)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"status": "draft", "count": 1},
+		{"status": "paid", "count": 2},
+	}, groups)
+	groups, err = orders.Aggregate(ctx, &query.Query{Predicate: query.MustParsePredicate(`{status: "paid"}`)}, a)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"status": "paid", "count": 2}}, groups)
+
+	// Storers implementing Aggregator get the restricted query.
+	groups, err = invoices.Aggregate(ctx, &query.Query{Predicate: query.MustParsePredicate(`{status: "paid"}`)}, a)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"count": 42}}, groups)
+	if assert.Len(t, native.queries, 1) {
+		assert.Equal(t, `{status: "paid", org: "acme"}`, native.queries[0].Predicate.String())
+	}
+}
+
+func TestAggregateScan(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "status": "paid"},
+		map[string]interface{}{"id": "2", "status": "paid"},
+		map[string]interface{}{"id": "3", "status": "draft"},
+	)
+	var queries []*query.Query
+	s := newIntegrityTestStorer(items)
+	find := s.find
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		queries = append(queries, q)
+		return find(ctx, q)
+	}
+	conf := Conf{AllowedModes: ReadWrite, Limits: QueryLimits{MaxScan: 2}}
+	orders := NewIndex().Bind("orders", schema.Schema{Fields: schema.Fields{"id": {}, "status": {}}}, s, conf)
+	found := 0
+	orders.Use(FoundEventHandlerFunc(func(ctx context.Context, q *query.Query, list **ItemList, err *error) {
+		found++
+	}))
+	a := &query.Aggregation{Accumulators: []query.Accumulator{{Func: query.AccumulatorCount}}}
+
+	// Items are scanned sorted by id, up to the MaxScan limit.
+	_, err := orders.Aggregate(context.Background(), &query.Query{}, a)
+	assert.Equal(t, ErrScanLimit, err)
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, "id", queries[0].Sort.String())
+		assert.Equal(t, &query.Window{Limit: 3}, queries[0].Window)
+	}
+	groups, err := orders.Aggregate(context.Background(), &query.Query{Predicate: query.MustParsePredicate(`{status: "paid"}`)}, a)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"count": 2}}, groups)
+
+	// OnFound hooks are called.
+	assert.Equal(t, 2, found)
+}
@@ -0,0 +1,500 @@
+// Package cache provides a read-through caching layer for REST Layer storage
+// handlers.
+//
+// The Storer type wraps any resource.Storer, caching the items retrieved by id
+// and optionally the results of Find queries, and invalidating them whenever
+// the wrapped storer is written thru the cache:
+//
+//	index.Bind("posts", post, cache.Wrap(mem.NewHandler(), cache.NewLRU(10000), cache.Options{
+//		Name: "posts",
+//		TTL:  10 * time.Minute,
+//	}), resource.DefaultConf)
+//
+// Writes performed on the underlying storage without going thru the cache are
+// only seen once the cached entries expire or are revalidated (see Options).
+//
+// The optional interfaces of the resource package (Counter, Transactor,
+// Aggregator...) are forwarded to the wrapped storer, returning
+// resource.ErrNotImplemented when it doesn't implement them so the resource
+// falls back on its generic implementation. Reads performed within a
+// transaction bypass the cache, as they may see uncommitted changes.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// Backend stores the cache entries. Implementations must be safe for
+// concurrent use. Values are either strings or *Entry; backends serializing
+// them must preserve their type.
+type Backend interface {
+	// Get returns the value stored for key. If the key is not found or has
+	// expired, false is returned.
+	Get(ctx context.Context, key string) (value interface{}, found bool)
+	// Set stores value for key. If ttl is greater than 0, the entry must
+	// expire after ttl.
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration)
+	// Delete removes the value stored for key, if any.
+	Delete(ctx context.Context, key string)
+}
+
+// Entry is a cached item or Find result.
+type Entry struct {
+	// Item is the cached item for entries stored by id.
+	Item *resource.Item
+	// List is the cached result for entries stored by query.
+	List *resource.ItemList
+	// Time is the time at which the entry has been fetched from the storer.
+	Time time.Time
+}
+
+// ETagger is an optional interface a Storer can implement to return the
+// current ETags of items without fetching them, allowing the cache to cheaply
+// revalidate the items it holds.
+type ETagger interface {
+	// ETags returns the ETags of the items with ids in the same order. The
+	// ETag of an item not found must be an empty string.
+	ETags(ctx context.Context, ids []interface{}) ([]string, error)
+}
+
+// Options configures a caching Storer.
+type Options struct {
+	// Name prefixes the keys of the entries so a backend can be shared by
+	// several storers. It must be unique among the storers sharing a backend.
+	Name string
+	// TTL is the duration after which entries expire. If 0, entries never
+	// expire and are only removed on writes or evicted by the backend.
+	TTL time.Duration
+	// MaxAge is the duration after which entries are revalidated before being
+	// served. Items are revalidated using their ETag when the wrapped storer
+	// implements ETagger and fetched again otherwise. Find results are always
+	// fetched again. If 0, entries are served until they expire.
+	MaxAge time.Duration
+	// Find enables the caching of the results of Find queries. All the cached
+	// results are invalidated by any write.
+	Find bool
+}
+
+// Storer is a resource.Storer decorator caching the items of the storer it
+// wraps.
+type Storer struct {
+	storer  resource.Storer
+	backend Backend
+	opts    Options
+}
+
+// generation is used to build unique generation tokens within the process.
+var generation int64
+
+// txKey is the key of the transaction begun thru a Storer in the context.
+type txKey struct {
+	s *Storer
+}
+
+// tx tracks the items written within a transaction, so their entries can be
+// invalidated once the transaction is over.
+type tx struct {
+	mu    sync.Mutex
+	items []*resource.Item
+}
+
+// Wrap returns a caching Storer wrapping s, storing its entries in b.
+func Wrap(s resource.Storer, b Backend, opts Options) *Storer {
+	return &Storer{storer: s, backend: b, opts: opts}
+}
+
+// Find implements resource.Storer. If Options.Find is set, the result is
+// served from the cache when present.
+func (s *Storer) Find(ctx context.Context, q *query.Query) (*resource.ItemList, error) {
+	if !s.opts.Find || s.inTx(ctx) {
+		return s.storer.Find(ctx, q)
+	}
+	key := s.findKey(ctx, q)
+	if e := s.get(ctx, key); e != nil && e.List != nil && !s.stale(e) {
+		return copyList(e.List), nil
+	}
+	l, err := s.storer.Find(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	s.set(ctx, key, &Entry{List: copyList(l), Time: time.Now()})
+	return l, nil
+}
+
+// MultiGet implements resource.MultiGetter. Items found in the cache are
+// served from there, the others are fetched from the wrapped storer.
+func (s *Storer) MultiGet(ctx context.Context, ids []interface{}) ([]*resource.Item, error) {
+	if s.inTx(ctx) {
+		return s.fetch(ctx, ids)
+	}
+	// The generation is read before fetching so entries aren't refilled with
+	// items written in the meantime.
+	gen := s.generation(ctx)
+	items := make([]*resource.Item, len(ids))
+	var stale []int
+	var missing []interface{}
+	var missingIdx []int
+	for i, id := range ids {
+		e := s.get(ctx, s.itemKey(id))
+		if e == nil || e.Item == nil {
+			missing = append(missing, id)
+			missingIdx = append(missingIdx, i)
+			continue
+		}
+		items[i] = copyItem(e.Item)
+		if s.stale(e) {
+			stale = append(stale, i)
+		}
+	}
+	if len(stale) > 0 {
+		// Stale items whose ETag didn't change are kept, others are fetched.
+		for _, i := range s.revalidate(ctx, gen, ids, items, stale) {
+			items[i] = nil
+			missing = append(missing, ids[i])
+			missingIdx = append(missingIdx, i)
+		}
+	}
+	if len(missing) == 0 {
+		return items, nil
+	}
+	fetched, err := s.fetch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	var refilled []*resource.Item
+	for j, item := range fetched {
+		items[missingIdx[j]] = item
+		if item != nil {
+			refilled = append(refilled, item)
+		}
+	}
+	s.refill(ctx, gen, refilled...)
+	return items, nil
+}
+
+// revalidate checks the ETags of the stale items with the wrapped storer and
+// returns the indexes of the items to fetch again. The entries of the items
+// still valid are refreshed.
+func (s *Storer) revalidate(ctx context.Context, gen string, ids []interface{}, items []*resource.Item, stale []int) []int {
+	e, ok := s.storer.(ETagger)
+	if !ok {
+		return stale
+	}
+	staleIDs := make([]interface{}, len(stale))
+	for j, i := range stale {
+		staleIDs[j] = ids[i]
+	}
+	etags, err := e.ETags(ctx, staleIDs)
+	if err != nil || len(etags) != len(stale) {
+		return stale
+	}
+	var changed []int
+	var valid []*resource.Item
+	for j, i := range stale {
+		if etags[j] != items[i].ETag {
+			changed = append(changed, i)
+			continue
+		}
+		valid = append(valid, items[i])
+	}
+	s.refill(ctx, gen, valid...)
+	return changed
+}
+
+// fetch gets the items with ids from the wrapped storer, emulating MultiGet
+// with a Find query if not supported. Items not found are set to nil.
+func (s *Storer) fetch(ctx context.Context, ids []interface{}) ([]*resource.Item, error) {
+	if mg, ok := s.storer.(resource.MultiGetter); ok {
+		return mg.MultiGet(ctx, ids)
+	}
+	values := make([]query.Value, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	l, err := s.storer.Find(ctx, &query.Query{
+		Predicate: query.Predicate{&query.In{Field: "id", Values: values}},
+		Window:    &query.Window{Limit: len(ids)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*resource.Item, len(ids))
+	for i, id := range ids {
+		for _, item := range l.Items {
+			if item.ID == id {
+				items[i] = item
+				break
+			}
+		}
+	}
+	return items, nil
+}
+
+// Count implements resource.Counter. Counts are not cached.
+func (s *Storer) Count(ctx context.Context, q *query.Query) (int, error) {
+	if c, ok := s.storer.(resource.Counter); ok {
+		return c.Count(ctx, q)
+	}
+	return -1, resource.ErrNotImplemented
+}
+
+// SupportsTextSearch implements resource.TextSearcher, forwarding the
+// capability of the wrapped storer.
+func (s *Storer) SupportsTextSearch() bool {
+	ts, ok := s.storer.(resource.TextSearcher)
+	return ok && ts.SupportsTextSearch()
+}
+
+// Explain implements resource.Explainer, forwarding to the wrapped storer. It
+// returns nil if the wrapped storer doesn't implement resource.Explainer.
+func (s *Storer) Explain(ctx context.Context, q *query.Query) (interface{}, error) {
+	if e, ok := s.storer.(resource.Explainer); ok {
+		return e.Explain(ctx, q)
+	}
+	return nil, nil
+}
+
+// Aggregate implements resource.Aggregator, forwarding to the wrapped storer.
+// Aggregations are not cached.
+func (s *Storer) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error) {
+	if ag, ok := s.storer.(resource.Aggregator); ok {
+		return ag.Aggregate(ctx, q, a)
+	}
+	return nil, resource.ErrNotImplemented
+}
+
+// Distinct implements resource.Distincter, forwarding to the wrapped storer.
+// Distinct values are not cached.
+func (s *Storer) Distinct(ctx context.Context, q *query.Query, field string) ([]resource.DistinctValue, error) {
+	if d, ok := s.storer.(resource.Distincter); ok {
+		return d.Distinct(ctx, q, field)
+	}
+	return nil, resource.ErrNotImplemented
+}
+
+// EnforceUnique implements resource.UniqueEnforcer, forwarding to the wrapped
+// storer.
+func (s *Storer) EnforceUnique(constraints []resource.UniqueConstraint) error {
+	if e, ok := s.storer.(resource.UniqueEnforcer); ok {
+		return e.EnforceUnique(constraints)
+	}
+	return resource.ErrNotImplemented
+}
+
+// Begin implements resource.Transactor, forwarding to the wrapped storer.
+func (s *Storer) Begin(ctx context.Context) (context.Context, error) {
+	t, ok := s.storer.(resource.Transactor)
+	if !ok {
+		return nil, resource.ErrNotImplemented
+	}
+	ctx, err := t.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, txKey{s}, &tx{}), nil
+}
+
+// Commit implements resource.Transactor, forwarding to the wrapped storer. The
+// entries of the items written within the transaction are invalidated again, as
+// they may have been refilled with their previous version in the meantime.
+func (s *Storer) Commit(ctx context.Context) error {
+	t, ok := s.storer.(resource.Transactor)
+	if !ok {
+		return resource.ErrNotImplemented
+	}
+	defer s.endTx(ctx)
+	return t.Commit(ctx)
+}
+
+// Rollback implements resource.Transactor, forwarding to the wrapped storer.
+func (s *Storer) Rollback(ctx context.Context) error {
+	t, ok := s.storer.(resource.Transactor)
+	if !ok {
+		return resource.ErrNotImplemented
+	}
+	defer s.endTx(ctx)
+	return t.Rollback(ctx)
+}
+
+// inTx returns true if ctx carries a transaction begun thru s.
+func (s *Storer) inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{s}).(*tx)
+	return ok
+}
+
+// endTx invalidates the entries of the items written within the transaction
+// carried by ctx.
+func (s *Storer) endTx(ctx context.Context) {
+	t, ok := ctx.Value(txKey{s}).(*tx)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	items := t.items
+	t.items = nil
+	t.mu.Unlock()
+	s.invalidate(ctx, items...)
+}
+
+// Insert implements resource.Storer.
+func (s *Storer) Insert(ctx context.Context, items []*resource.Item) error {
+	defer s.invalidate(ctx, items...)
+	return s.storer.Insert(ctx, items)
+}
+
+// Update implements resource.Storer.
+func (s *Storer) Update(ctx context.Context, item *resource.Item, original *resource.Item) error {
+	defer s.invalidate(ctx, item)
+	return s.storer.Update(ctx, item, original)
+}
+
+// Delete implements resource.Storer.
+func (s *Storer) Delete(ctx context.Context, item *resource.Item) error {
+	defer s.invalidate(ctx, item)
+	return s.storer.Delete(ctx, item)
+}
+
+// Clear implements resource.Storer. The matching items are looked up first so
+// their entries can be invalidated.
+func (s *Storer) Clear(ctx context.Context, q *query.Query) (int, error) {
+	l, err := s.storer.Find(ctx, &query.Query{Predicate: q.Predicate, Sort: q.Sort, Window: q.Window})
+	if err != nil {
+		return 0, err
+	}
+	defer s.invalidate(ctx, l.Items...)
+	return s.storer.Clear(ctx, q)
+}
+
+// invalidate removes the entries of items and the cached Find results. It is
+// called whether the write succeeded or not, as a failed write may still have
+// been partially applied.
+//
+// The generation is renewed before the entries are removed, so a concurrent
+// refill either sees the new generation or has its entries removed (see
+// refill).
+func (s *Storer) invalidate(ctx context.Context, items ...*resource.Item) {
+	s.backend.Set(ctx, s.key("gen"), newGeneration(), 0)
+	if t, ok := ctx.Value(txKey{s}).(*tx); ok {
+		t.mu.Lock()
+		t.items = append(t.items, items...)
+		t.mu.Unlock()
+	}
+	for _, item := range items {
+		s.backend.Delete(ctx, s.itemKey(item.ID))
+	}
+}
+
+// refill stores the entries of items fetched from the wrapped storer while the
+// generation was gen. If the generation changed, an item may have been written
+// and invalidated while it was fetched, so the entries are removed again.
+func (s *Storer) refill(ctx context.Context, gen string, items ...*resource.Item) {
+	if len(items) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, item := range items {
+		s.set(ctx, s.itemKey(item.ID), &Entry{Item: copyItem(item), Time: now})
+	}
+	if s.generation(ctx) != gen {
+		for _, item := range items {
+			s.backend.Delete(ctx, s.itemKey(item.ID))
+		}
+	}
+}
+
+// stale returns true if e must be revalidated before being served.
+func (s *Storer) stale(e *Entry) bool {
+	return s.opts.MaxAge > 0 && time.Since(e.Time) > s.opts.MaxAge
+}
+
+func (s *Storer) get(ctx context.Context, key string) *Entry {
+	v, found := s.backend.Get(ctx, key)
+	if !found {
+		return nil
+	}
+	e, _ := v.(*Entry)
+	return e
+}
+
+func (s *Storer) set(ctx context.Context, key string, e *Entry) {
+	s.backend.Set(ctx, key, e, s.opts.TTL)
+}
+
+func (s *Storer) key(parts ...string) string {
+	return s.opts.Name + ":" + strings.Join(parts, ":")
+}
+
+func (s *Storer) itemKey(id interface{}) string {
+	return s.key("item", fmt.Sprintf("%T:%v", id, id))
+}
+
+// findKey returns the key of the result of q. The key includes the current
+// generation of the Find results, renewed on each write, and the normalized
+// predicate so equivalent queries share the same entry.
+func (s *Storer) findKey(ctx context.Context, q *query.Query) string {
+	g := s.generation(ctx)
+	if g == "" {
+		g = newGeneration()
+		s.backend.Set(ctx, s.key("gen"), g, 0)
+	}
+	window := ""
+	if q.Window != nil {
+		window = fmt.Sprintf("%d,%d", q.Window.Offset, q.Window.Limit)
+	}
+	return s.key("find", g, q.Predicate.Normalize().String(), q.Sort.String(), window)
+}
+
+// generation returns the current generation, renewed on each write, or an
+// empty string if not set yet.
+func (s *Storer) generation(ctx context.Context) string {
+	gen, _ := s.backend.Get(ctx, s.key("gen"))
+	g, _ := gen.(string)
+	return g
+}
+
+func newGeneration() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatInt(atomic.AddInt64(&generation, 1), 36)
+}
+
+// copyList returns a copy of l so cached entries can't be altered by callers.
+func copyList(l *resource.ItemList) *resource.ItemList {
+	c := *l
+	c.Items = make([]*resource.Item, len(l.Items))
+	for i, item := range l.Items {
+		c.Items[i] = copyItem(item)
+	}
+	return &c
+}
+
+func copyItem(i *resource.Item) *resource.Item {
+	c := *i
+	c.Payload = copyValue(i.Payload).(map[string]interface{})
+	return &c
+}
+
+func copyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			c[k] = copyValue(v)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(t))
+		for i, v := range t {
+			c[i] = copyValue(v)
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingStorer counts the reads performed on the wrapped memory handler.
+type countingStorer struct {
+	*mem.MemoryHandler
+	finds, etags int
+}
+
+func (s *countingStorer) Find(ctx context.Context, q *query.Query) (*resource.ItemList, error) {
+	s.finds++
+	return s.MemoryHandler.Find(ctx, q)
+}
+
+type etaggingStorer struct {
+	*countingStorer
+}
+
+func (s etaggingStorer) ETags(ctx context.Context, ids []interface{}) ([]string, error) {
+	s.etags++
+	etags := make([]string, len(ids))
+	for i, id := range ids {
+		l, err := s.MemoryHandler.Find(ctx, &query.Query{Predicate: query.Predicate{&query.Equal{Field: "id", Value: id}}})
+		if err != nil {
+			return nil, err
+		}
+		if len(l.Items) > 0 {
+			etags[i] = l.Items[0].ETag
+		}
+	}
+	return etags, nil
+}
+
+// racingStorer runs onFind once, after the items are fetched but before they
+// are returned.
+type racingStorer struct {
+	*mem.MemoryHandler
+	onFind func()
+}
+
+func (s *racingStorer) Find(ctx context.Context, q *query.Query) (*resource.ItemList, error) {
+	l, err := s.MemoryHandler.Find(ctx, q)
+	if f := s.onFind; f != nil {
+		s.onFind = nil
+		f()
+	}
+	return l, err
+}
+
+type txStorerKey struct{}
+
+// txStorer is a Transactor counting the commits and rollbacks.
+type txStorer struct {
+	*countingStorer
+	commits, rollbacks int
+}
+
+func (s *txStorer) Begin(ctx context.Context) (context.Context, error) {
+	return context.WithValue(ctx, txStorerKey{}, true), nil
+}
+
+func (s *txStorer) Commit(ctx context.Context) error {
+	s.commits++
+	return nil
+}
+
+func (s *txStorer) Rollback(ctx context.Context) error {
+	s.rollbacks++
+	return nil
+}
+
+func newItem(id, etag string) *resource.Item {
+	return &resource.Item{ID: id, ETag: etag, Payload: map[string]interface{}{"id": id, "foo": etag}}
+}
+
+func TestStorerMultiGet(t *testing.T) {
+	ctx := context.Background()
+	s := &countingStorer{MemoryHandler: mem.NewHandler()}
+	c := Wrap(s, NewLRU(0), Options{Name: "foo"})
+	assert.NoError(t, c.Insert(ctx, []*resource.Item{newItem("1", "a"), newItem("2", "a")}))
+
+	items, err := c.MultiGet(ctx, []interface{}{"1", "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Item{newItem("1", "a"), nil}, items)
+	assert.Equal(t, 1, s.finds)
+
+	// Cached items are served without hitting the storer and can't be
+	// altered by the caller.
+	items[0].Payload["foo"] = "altered"
+	items, err = c.MultiGet(ctx, []interface{}{"1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Item{newItem("1", "a")}, items)
+	assert.Equal(t, 1, s.finds)
+
+	// Only the missing items are fetched.
+	items, err = c.MultiGet(ctx, []interface{}{"2", "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Item{newItem("2", "a"), newItem("1", "a")}, items)
+	assert.Equal(t, 2, s.finds)
+
+	// Writes invalidate the items.
+	assert.NoError(t, c.Update(ctx, newItem("1", "b"), newItem("1", "a")))
+	items, _ = c.MultiGet(ctx, []interface{}{"1"})
+	assert.Equal(t, []*resource.Item{newItem("1", "b")}, items)
+	assert.Equal(t, 3, s.finds)
+	assert.NoError(t, c.Delete(ctx, newItem("1", "b")))
+	items, _ = c.MultiGet(ctx, []interface{}{"1"})
+	assert.Equal(t, []*resource.Item{nil}, items)
+	_, err = c.Clear(ctx, &query.Query{})
+	assert.NoError(t, err)
+	items, _ = c.MultiGet(ctx, []interface{}{"2"})
+	assert.Equal(t, []*resource.Item{nil}, items)
+}
+
+func TestStorerFind(t *testing.T) {
+	ctx := context.Background()
+	s := &countingStorer{MemoryHandler: mem.NewHandler()}
+	c := Wrap(s, NewLRU(0), Options{Name: "foo", Find: true})
+	assert.NoError(t, c.Insert(ctx, []*resource.Item{newItem("1", "a"), newItem("2", "b")}))
+	q := &query.Query{Predicate: query.MustParsePredicate(`{foo:"a"}`)}
+
+	for i := 0; i < 2; i++ {
+		l, err := c.Find(ctx, q)
+		assert.NoError(t, err)
+		assert.Equal(t, []*resource.Item{newItem("1", "a")}, l.Items)
+	}
+	assert.Equal(t, 1, s.finds)
+
+	// Equivalent predicates share the same entry.
+	c.Find(ctx, &query.Query{Predicate: query.MustParsePredicate(`{foo: {$in: ["a"]}}`)})
+	assert.Equal(t, 1, s.finds)
+
+	// Queries are cached separately.
+	l, _ := c.Find(ctx, &query.Query{Predicate: query.MustParsePredicate(`{foo:"a"}`), Window: &query.Window{Limit: 1}})
+	assert.Len(t, l.Items, 1)
+	assert.Equal(t, 2, s.finds)
+
+	// Any write invalidates the results.
+	assert.NoError(t, c.Insert(ctx, []*resource.Item{newItem("3", "a")}))
+	l, _ = c.Find(ctx, q)
+	assert.Len(t, l.Items, 2)
+	assert.Equal(t, 3, s.finds)
+}
+
+func TestStorerRevalidate(t *testing.T) {
+	ctx := context.Background()
+	s := etaggingStorer{&countingStorer{MemoryHandler: mem.NewHandler()}}
+	c := Wrap(s, NewLRU(0), Options{Name: "foo", MaxAge: time.Millisecond})
+	assert.NoError(t, s.Insert(ctx, []*resource.Item{newItem("1", "a"), newItem("2", "a")}))
+	c.MultiGet(ctx, []interface{}{"1", "2"})
+	assert.Equal(t, 1, s.finds)
+
+	// Changed items are fetched, unchanged ones are served from the cache.
+	assert.NoError(t, s.Update(ctx, newItem("2", "b"), newItem("2", "a")))
+	time.Sleep(2 * time.Millisecond)
+	items, err := c.MultiGet(ctx, []interface{}{"1", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Item{newItem("1", "a"), newItem("2", "b")}, items)
+	assert.Equal(t, 1, s.etags)
+	assert.Equal(t, 2, s.finds)
+}
+
+func TestStorerStaleRefill(t *testing.T) {
+	ctx := context.Background()
+	s := &racingStorer{MemoryHandler: mem.NewHandler()}
+	c := Wrap(s, NewLRU(0), Options{Name: "foo"})
+	assert.NoError(t, c.Insert(ctx, []*resource.Item{newItem("1", "a")}))
+
+	// The item is updated while its previous version is fetched.
+	s.onFind = func() {
+		assert.NoError(t, c.Update(ctx, newItem("1", "b"), newItem("1", "a")))
+	}
+	items, err := c.MultiGet(ctx, []interface{}{"1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Item{newItem("1", "a")}, items)
+
+	// The previous version is not cached.
+	items, err = c.MultiGet(ctx, []interface{}{"1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Item{newItem("1", "b")}, items)
+}
+
+func TestStorerForward(t *testing.T) {
+	ctx := context.Background()
+	c := Wrap(&countingStorer{MemoryHandler: mem.NewHandler()}, NewLRU(0), Options{Name: "foo"})
+	_, err := c.Aggregate(ctx, &query.Query{}, &query.Aggregation{})
+	assert.Equal(t, resource.ErrNotImplemented, err)
+	_, err = c.Distinct(ctx, &query.Query{}, "foo")
+	assert.Equal(t, resource.ErrNotImplemented, err)
+	_, err = c.Begin(ctx)
+	assert.Equal(t, resource.ErrNotImplemented, err)
+	assert.NoError(t, c.EnforceUnique(nil))
+
+	s := &txStorer{countingStorer: &countingStorer{MemoryHandler: mem.NewHandler()}}
+	c = Wrap(s, NewLRU(0), Options{Name: "foo"})
+	assert.NoError(t, c.Insert(ctx, []*resource.Item{newItem("1", "a")}))
+	c.MultiGet(ctx, []interface{}{"1"})
+	assert.Equal(t, 1, s.finds)
+
+	// Reads within a transaction bypass the cache.
+	tctx, err := c.Begin(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Update(tctx, newItem("1", "b"), newItem("1", "a")))
+	items, _ := c.MultiGet(tctx, []interface{}{"1"})
+	assert.Equal(t, []*resource.Item{newItem("1", "b")}, items)
+	assert.Equal(t, 2, s.finds)
+	c.MultiGet(tctx, []interface{}{"1"})
+	assert.Equal(t, 3, s.finds)
+
+	// Written items are invalidated on commit.
+	c.MultiGet(ctx, []interface{}{"1"})
+	assert.Equal(t, 4, s.finds)
+	assert.NoError(t, c.Commit(tctx))
+	assert.Equal(t, 1, s.commits)
+	items, _ = c.MultiGet(ctx, []interface{}{"1"})
+	assert.Equal(t, []*resource.Item{newItem("1", "b")}, items)
+	assert.Equal(t, 5, s.finds)
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	c.Set(ctx, "a", 1, 0)
+	c.Set(ctx, "b", 2, 0)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", 3, 0)
+	assert.Equal(t, 2, c.Len())
+	_, found := c.Get(ctx, "b")
+	assert.False(t, found, "least recently used entry evicted")
+	v, found := c.Get(ctx, "a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+
+	c.Set(ctx, "d", 4, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	_, found = c.Get(ctx, "d")
+	assert.False(t, found, "expired entry")
+	c.Delete(ctx, "a")
+	_, found = c.Get(ctx, "a")
+	assert.False(t, found)
+	assert.Equal(t, 0, c.Len())
+}
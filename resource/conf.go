@@ -1,5 +1,11 @@
 package resource
 
+import (
+	"context"
+
+	"github.com/rs/rest-layer/schema"
+)
+
 // Conf defines the configuration for a given resource.
 type Conf struct {
 	// AllowedModes is the list of Mode allowed for the resource.
@@ -21,6 +27,45 @@ type Conf struct {
 	//
 	// TotalDenied prevents the user from requesting the total.
 	ForceTotal ForceTotalMode
+	// Policy defines the access control rules of the resource. If nil, no
+	// access control is performed. See Policy for more info.
+	Policy Policy
+	// TenantField is the name of the field holding the tenant owning each
+	// item. When set, all operations on the resource are scoped to the
+	// tenant returned by TenantFunc for the request context. See TenantFunc
+	// for more info.
+	TenantField string
+	// TenantFunc extracts the tenant of the request from the context. It
+	// must be set when TenantField is set. When it returns false, operations
+	// are denied with ErrForbidden.
+	TenantFunc TenantFunc
+	// SoftDeleteField is the name of the field set to the deletion time of
+	// soft deleted items. When set, Delete and Clear mark the items as
+	// deleted instead of removing them from the storage, and soft deleted
+	// items are hidden from lookups unless requested otherwise through the
+	// context (see NewContextWithDeletedFilter). The Restore and Purge modes
+	// can then be used to restore or permanently delete soft deleted items.
+	// The field must be ReadOnly. Except for DeleteRestrict, the OnDelete
+	// actions of the referencing items are only performed on purge.
+	SoftDeleteField string
+	// History stores a revision of the items on each insert, update and
+	// delete when set. See HistoryStorer for more info.
+	History HistoryStorer
+	// HistoryActor returns the actor recorded with the revisions from the
+	// request context. If not set, the principal stored in the context, if
+	// any, is recorded.
+	HistoryActor func(ctx context.Context) interface{}
+	// OnParentDelete defines what happens to the items of a sub-resource when
+	// their parent item is deleted. It is ignored for root resources.
+	OnParentDelete schema.DeleteAction
+	// Unique lists compound unique constraints, each being a list of fields
+	// whose combined values must be unique among the items of the resource.
+	// Single field constraints can be declared with schema.Field.Unique.
+	// See UniqueEnforcer for more info.
+	Unique []UniqueConstraint
+	// Limits restricts the cost of the queries clients can send on the
+	// resource. See QueryLimits for more info.
+	Limits QueryLimits
 }
 
 // ForceTotalMode defines Conf.ForceTotal modes.
@@ -57,6 +102,18 @@ const (
 	Clear
 	// List mode represents the GET method on a collection URL.
 	List
+	// Restore mode represents the POST method on the _restore URL of a soft
+	// deleted item.
+	Restore
+	// Purge mode represents the DELETE method on the _purge URL of an item
+	// or a collection, permanently deleting soft deleted items.
+	Purge
+	// Aggregate mode represents the GET method on the _aggregate URL of a
+	// collection.
+	Aggregate
+	// Distinct mode represents the GET method on the _distinct URL of a
+	// collection.
+	Distinct
 )
 
 var (
@@ -66,6 +123,12 @@ var (
 	ReadOnly = []Mode{Read, List}
 	// WriteOnly is a shortcut for Create, Update, Delete modes.
 	WriteOnly = []Mode{Create, Update, Replace, Delete, Clear}
+	// SoftDeleteModes is a shortcut for the Restore and Purge modes managing
+	// soft deleted items. They are not part of the other shortcuts and must
+	// be allowed explicitly, i.e.:
+	//
+	//	AllowedModes: append(resource.ReadWrite, resource.SoftDeleteModes...)
+	SoftDeleteModes = []Mode{Restore, Purge}
 
 	// DefaultConf defines a configuration with some sensible default parameters.
 	// Mode is read/write and default pagination limit is set to 20 items.
@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// DistinctValue is a value of a field with the number of items holding it.
+type DistinctValue struct {
+	Value interface{} `json:"value"`
+	Count int         `json:"count"`
+}
+
+// Distincter is an optional interface a Storer can implement to natively list
+// the distinct values of a field.
+//
+// When the storer doesn't implement this interface or returns
+// ErrNotImplemented, the items matching the query are fetched page by page
+// using Find, sorted by id, and their values are counted in memory. The number
+// of items scanned is capped by the MaxScan query limit of the resource.
+type Distincter interface {
+	// Distinct returns the distinct values of field among the items matching
+	// the predicate of q, with the number of items holding each value. The
+	// elements of array fields are counted individually, and items lacking the
+	// field or holding a null value are ignored. Values must be ordered by
+	// descending count, and the Window of the query must be applied on the
+	// values.
+	Distinct(ctx context.Context, q *query.Query, field string) ([]DistinctValue, error)
+}
+
+// Distinct returns the distinct values of field among the items matching q,
+// with the same hooks and restrictions as Find. The Window of q is applied on
+// the values, ordered by descending count. As distinct values are not items,
+// the OnFound hooks get an empty list.
+func (r *Resource) Distinct(ctx context.Context, q *query.Query, field string) (values []DistinctValue, err error) {
+	if LoggerLevel <= LogLevelDebug && Logger != nil {
+		defer func(t time.Time) {
+			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.Distinct(%s)", r.path, field), map[string]interface{}{
+				"duration": time.Since(t),
+				"values":   len(values),
+				"error":    err,
+			})
+		}(time.Now())
+	}
+	if err = r.hooks.onFind(ctx, q); err == nil {
+		var visible query.Predicate
+		if visible, err = r.restriction(ctx, Read); err == nil {
+			values, err = r.storage.Distinct(ctx, scope(q, visible), field)
+		}
+	}
+	list := &ItemList{Total: -1, Items: []*Item{}}
+	r.hooks.onFound(ctx, q, &list, &err)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Distinct uses the storer Distinct method if implemented, or counts the
+// values of the items returned by Find otherwise.
+func (s storageWrapper) Distinct(ctx context.Context, q *query.Query, field string) (values []DistinctValue, err error) {
+	if s.Storer == nil {
+		return nil, ErrNoStorage
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if ctx, _, err = s.session(ctx); err != nil {
+		return nil, err
+	}
+	if d, ok := s.Storer.(Distincter); ok {
+		values, err := d.Distinct(ctx, &query.Query{Predicate: q.Predicate, Window: q.Window}, field)
+		if err != ErrNotImplemented {
+			return values, err
+		}
+	}
+	counts := map[string]*DistinctValue{}
+	add := func(v interface{}) {
+		key := fmt.Sprintf("%T:%v", v, v)
+		if c, found := counts[key]; found {
+			c.Count++
+			return
+		}
+		counts[key] = &DistinctValue{Value: v, Count: 1}
+	}
+	err = s.scan(ctx, &query.Query{Predicate: q.Predicate}, func(item *Item) {
+		switch v := item.GetField(field).(type) {
+		case nil:
+		case []interface{}:
+			for _, e := range v {
+				if e != nil {
+					add(e)
+				}
+			}
+		default:
+			add(v)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ci, cj := counts[keys[i]].Count, counts[keys[j]].Count; ci != cj {
+			return ci > cj
+		}
+		return keys[i] < keys[j]
+	})
+	values = make([]DistinctValue, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, *counts[key])
+	}
+	if w := q.Window; w != nil {
+		if w.Offset >= len(values) {
+			return []DistinctValue{}, nil
+		}
+		values = values[w.Offset:]
+		if w.Limit >= 0 && w.Limit < len(values) {
+			values = values[:w.Limit]
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDistincter struct {
+	*testMStorer
+	queries []*query.Query
+}
+
+func (s *testDistincter) Distinct(ctx context.Context, q *query.Query, field string) ([]DistinctValue, error) {
+	s.queries = append(s.queries, q)
+	return []DistinctValue{{Value: field, Count: 1}}, nil
+}
+
+func TestDistinct(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "org": "acme", "country": "fr"},
+		map[string]interface{}{"id": "2", "org": "acme", "country": "us"},
+		map[string]interface{}{"id": "3", "org": "acme", "country": "us"},
+		map[string]interface{}{"id": "4", "org": "acme", "country": nil},
+		map[string]interface{}{"id": "5", "org": "initech", "country": "fr"},
+	)
+	s := schema.Schema{Fields: schema.Fields{
+		"id":      {},
+		"org":     {ReadOnly: true},
+		"country": {Filterable: true},
+	}}
+	conf := Conf{AllowedModes: ReadWrite, TenantField: "org", TenantFunc: tenantFromContext}
+	native := &testDistincter{testMStorer: newTestMStorer()}
+	index := NewIndex()
+	users := index.Bind("users", s, newIntegrityTestStorer(items), conf)
+	accounts := index.Bind("accounts", s, native, conf)
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	// Values are counted in memory within the visible items.
+	values, err := users.Distinct(ctx, &query.Query{}, "country")
+	assert.NoError(t, err)
+	assert.Equal(t, []DistinctValue{{"us", 2}, {"fr", 1}}, values)
+	values, err = users.Distinct(ctx, &query.Query{Window: &query.Window{Offset: 1, Limit: 5}}, "country")
+	assert.NoError(t, err)
+	assert.Equal(t, []DistinctValue{{"fr", 1}}, values)
+
+	// Storers implementing Distincter get the restricted query.
+	values, err = accounts.Distinct(ctx, &query.Query{}, "country")
+	assert.NoError(t, err)
+	assert.Equal(t, []DistinctValue{{"country", 1}}, values)
+	if assert.Len(t, native.queries, 1) {
+		assert.Equal(t, `{org: "acme"}`, native.queries[0].Predicate.String())
+	}
+}
+
+func TestDistinctScan(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "country": "fr"},
+		map[string]interface{}{"id": "2", "country": "us"},
+		map[string]interface{}{"id": "3", "country": "us"},
+	)
+	var queries []*query.Query
+	s := newIntegrityTestStorer(items)
+	find := s.find
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		queries = append(queries, q)
+		return find(ctx, q)
+	}
+	conf := Conf{AllowedModes: ReadWrite, Limits: QueryLimits{MaxScan: 2}}
+	users := NewIndex().Bind("users", schema.Schema{Fields: schema.Fields{"id": {}, "country": {}}}, s, conf)
+	found := 0
+	users.Use(FoundEventHandlerFunc(func(ctx context.Context, q *query.Query, list **ItemList, err *error) {
+		found++
+	}))
+
+	// Items are scanned sorted by id, up to the MaxScan limit.
+	_, err := users.Distinct(context.Background(), &query.Query{}, "country")
+	assert.Equal(t, ErrScanLimit, err)
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, "id", queries[0].Sort.String())
+		assert.Equal(t, &query.Window{Limit: 3}, queries[0].Window)
+	}
+	values, err := users.Distinct(context.Background(), &query.Query{Predicate: query.MustParsePredicate(`{country: "us"}`)}, "country")
+	assert.NoError(t, err)
+	assert.Equal(t, []DistinctValue{{"us", 2}}, values)
+
+	// OnFound hooks are called.
+	assert.Equal(t, 2, found)
+}
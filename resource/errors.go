@@ -1,6 +1,9 @@
 package resource
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	// ErrNotFound is returned when the requested resource can't be found.
@@ -18,4 +21,36 @@ var (
 	// ErrNoStorage is returned when not storage handler has been set on the
 	// resource.
 	ErrNoStorage = errors.New("No Storage Defined")
+	// ErrTransactionDone is returned when a storer is used with the context of
+	// a transaction already committed or rolled back.
+	ErrTransactionDone = errors.New("Transaction Done")
+	// ErrScanLimit is returned when an operation performed in memory would
+	// scan more items than allowed by QueryLimits.MaxScan.
+	ErrScanLimit = errors.New("Too Many Items To Scan")
+	// ErrReferenced is returned when deleting an item still referenced by
+	// items of a resource with the DeleteRestrict action.
+	ErrReferenced = errors.New("Item Is Referenced")
 )
+
+// UniqueError is returned when an item violates a unique constraint.
+type UniqueError struct {
+	// Constraint is the violated constraint.
+	Constraint UniqueConstraint
+}
+
+// Error implements the error interface.
+func (e *UniqueError) Error() string {
+	return "Duplicate value for " + strings.Join(e.Constraint, ", ")
+}
+
+// ValidationError is returned when the items passed to a write operation are
+// rejected, with the issues found on their fields. Issues use the same format
+// as the errors returned by schema.Validator.
+type ValidationError struct {
+	Issues map[string][]interface{}
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return "Document contains error(s)"
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// Explainer is an optional interface a Storer can implement to describe how
+// it executes a query, i.e.: the query plan of the database.
+type Explainer interface {
+	// Explain returns the native execution plan of q. The returned value must
+	// be serializable to JSON.
+	Explain(ctx context.Context, q *query.Query) (interface{}, error)
+}
+
+// Explanation describes how a Find has been performed. When an Explanation is
+// stored in the context with NewContextWithExplanation, Resource.Find and
+// Resource.FindWithTotal fill it in.
+type Explanation struct {
+	// Query is the query sent to the storage handler, after the hooks, the
+	// access restrictions and the normalization were applied.
+	Query *query.Query
+	// Hooks lists the Go types of the FindEventHandler hooks which modified
+	// the query, in their order of execution.
+	Hooks []string
+	// Stages lists the stages of the request in their order of execution.
+	Stages []ExplainStage
+	// Plan is the native execution plan returned by the storage handler if it
+	// implements Explainer.
+	Plan interface{}
+}
+
+// ExplainStage is the duration of a stage of a request.
+type ExplainStage struct {
+	Name     string
+	Duration time.Duration
+}
+
+// AddStage records the duration of a stage started at start.
+func (e *Explanation) AddStage(name string, start time.Time) {
+	e.Stages = append(e.Stages, ExplainStage{Name: name, Duration: time.Since(start)})
+}
+
+type explanationKey struct{}
+
+// NewContextWithExplanation returns a new context carrying e to be filled in
+// by the Find operations performed with it.
+func NewContextWithExplanation(ctx context.Context, e *Explanation) context.Context {
+	return context.WithValue(ctx, explanationKey{}, e)
+}
+
+// ExplanationFromContext returns the Explanation stored in ctx, if any.
+func ExplanationFromContext(ctx context.Context) (*Explanation, bool) {
+	e, _ := ctx.Value(explanationKey{}).(*Explanation)
+	return e, e != nil
+}
+
+// explainFind calls the FindEventHandler hooks like eventHandler.onFind,
+// recording the hooks modifying q in e.
+func (h *eventHandler) explainFind(ctx context.Context, q *query.Query, e *Explanation) error {
+	for _, hook := range h.onFindH {
+		before := queryString(q)
+		if err := hook.OnFind(ctx, q); err != nil {
+			return err
+		}
+		if queryString(q) != before {
+			e.Hooks = append(e.Hooks, fmt.Sprintf("%T", hook))
+		}
+	}
+	return nil
+}
+
+// queryString returns a representation of q changing with any of its parts.
+func queryString(q *query.Query) string {
+	var b strings.Builder
+	b.WriteString(q.Predicate.String())
+	b.WriteString(" | ")
+	b.WriteString(q.Sort.String())
+	b.WriteString(" | ")
+	if q.Window != nil {
+		fmt.Fprintf(&b, "%d,%d", q.Window.Offset, q.Window.Limit)
+	}
+	b.WriteString(" | ")
+	b.WriteString(q.Projection.String())
+	return b.String()
+}
+
+// Explain implements storageHandler. It returns nil if the storer doesn't
+// implement Explainer.
+func (s storageWrapper) Explain(ctx context.Context, q *query.Query) (interface{}, error) {
+	e, ok := s.Storer.(Explainer)
+	if !ok {
+		return nil, nil
+	}
+	ctx, _, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return e.Explain(ctx, q)
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+type explainTestStorer struct {
+	*testMStorer
+}
+
+func (s explainTestStorer) Explain(ctx context.Context, q *query.Query) (interface{}, error) {
+	return map[string]interface{}{"filter": q.Predicate.String()}, nil
+}
+
+func TestResourceFindExplain(t *testing.T) {
+	sch := schema.Schema{Fields: schema.Fields{
+		"id":  {},
+		"age": {Filterable: true, Validator: &schema.Integer{}},
+	}}
+	newQuery := func(predicate string) *query.Query {
+		q, err := query.New("", predicate, "", query.Page(1, 10, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = q.Validate(sch); err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+	s := newIntegrityTestStorer(newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "age": 10},
+		map[string]interface{}{"id": "2", "age": 20},
+	))
+	r := NewIndex().Bind("users", sch, explainTestStorer{s}, DefaultConf)
+	r.Use(FindEventHandlerFunc(func(ctx context.Context, q *query.Query) error {
+		return nil
+	}))
+	r.Use(FindEventHandlerFunc(func(ctx context.Context, q *query.Query) error {
+		p, err := query.ParsePredicate(`{age: {$gt: 15}}`)
+		if err == nil {
+			err = p.Prepare(sch)
+		}
+		q.Predicate = append(q.Predicate, p...)
+		return err
+	}))
+
+	e := &Explanation{}
+	ctx := NewContextWithExplanation(context.Background(), e)
+	l, err := r.Find(ctx, newQuery(`{age: {$gt: 5}}`))
+	assert.NoError(t, err)
+	assert.Len(t, l.Items, 1)
+	if assert.NotNil(t, e.Query) {
+		assert.Equal(t, `{age: {$gt: 15}}`, e.Query.Predicate.String())
+	}
+	assert.Equal(t, []string{"resource.FindEventHandlerFunc"}, e.Hooks)
+	names := []string{}
+	for _, s := range e.Stages {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"find hooks", "restriction", "storage", "found hooks"}, names)
+	assert.Equal(t, map[string]interface{}{"filter": `{age: {$gt: 15}}`}, e.Plan)
+
+	// Predicates that can't match have no plan.
+	e = &Explanation{}
+	ctx = NewContextWithExplanation(context.Background(), e)
+	_, err = r.Find(ctx, newQuery(`{age: {$gt: 5}, age: {$lt: 5}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, e.Plan)
+
+	// Without an explanation in the context, nothing is recorded.
+	_, ok := ExplanationFromContext(context.Background())
+	assert.False(t, ok)
+}
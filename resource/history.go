@@ -0,0 +1,177 @@
+package resource
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// RevisionOp is the operation which produced a revision.
+type RevisionOp string
+
+const (
+	// RevisionInsert is the revision of an inserted item.
+	RevisionInsert RevisionOp = "insert"
+	// RevisionUpdate is the revision of an updated item.
+	RevisionUpdate RevisionOp = "update"
+	// RevisionDelete is the revision of a deleted item, holding its last
+	// payload.
+	RevisionDelete RevisionOp = "delete"
+)
+
+// Revision is a recorded version of an item.
+type Revision struct {
+	// Resource is the path of the resource of the item.
+	Resource string
+	// ItemID is the id of the item.
+	ItemID interface{}
+	// Op is the operation which produced the revision.
+	Op RevisionOp
+	// ETag is the ETag of the item at this revision.
+	ETag string
+	// Payload is the payload of the item at this revision.
+	Payload map[string]interface{}
+	// Time is the time at which the revision was recorded.
+	Time time.Time
+	// Actor identifies who produced the revision (see Conf.HistoryActor).
+	Actor interface{}
+}
+
+// History is the list of revisions of an item in chronological order.
+type History []*Revision
+
+// At returns the revision current at time t, or nil if the item didn't exist
+// yet at this time.
+func (h History) At(t time.Time) *Revision {
+	var rev *Revision
+	for _, r := range h {
+		if r.Time.After(t) {
+			break
+		}
+		rev = r
+	}
+	return rev
+}
+
+// ETag returns the revision with the given etag, or nil if not found.
+func (h History) ETag(etag string) *Revision {
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i].ETag == etag {
+			return h[i]
+		}
+	}
+	return nil
+}
+
+// HistoryStorer stores the revisions of the items of resources having the
+// Conf.History option set. A HistoryStorer can be shared by several resources.
+//
+// Revisions are recorded after the storage operation succeeded. If recording
+// fails, the error is returned by the operation, but the storage is not rolled
+// back.
+type HistoryStorer interface {
+	// Record stores the revisions.
+	Record(ctx context.Context, revisions []*Revision) error
+	// History returns the revisions of the item with the given id of the
+	// resource at path, in chronological order. An empty history is returned
+	// if no revision is found.
+	History(ctx context.Context, path string, id interface{}) (History, error)
+}
+
+// History returns the revisions of the item with the given id, in
+// chronological order. The revisions not visible to the request, as defined by
+// the tenant scope and the Read rule of the policy, are omitted. If no revision
+// is visible, ErrNotFound is returned.
+//
+// Like for Get, the OnGet and OnGot hooks are called, the latest revision being
+// passed as the got item.
+func (r *Resource) History(ctx context.Context, id interface{}) (h History, err error) {
+	if r.conf.History == nil {
+		return nil, ErrNotImplemented
+	}
+	var item *Item
+	if err = r.hooks.onGet(ctx, id); err == nil {
+		if h, err = r.history(ctx, id); err == nil {
+			last := h[len(h)-1]
+			item = &Item{ID: last.ItemID, ETag: last.ETag, Updated: last.Time, Payload: last.Payload}
+		}
+	}
+	r.hooks.onGot(ctx, &item, &err)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// history returns the revisions of the item with the given id visible to the
+// request.
+func (r *Resource) history(ctx context.Context, id interface{}) (History, error) {
+	visible, err := r.restriction(NewContextWithDeletedFilter(ctx, IncludeDeleted), Read)
+	if err != nil {
+		return nil, err
+	}
+	h, err := r.conf.History.History(ctx, r.path, id)
+	if err != nil {
+		return nil, err
+	}
+	res := make(History, 0, len(h))
+	for _, rev := range h {
+		if visible == nil || visible.Match(rev.Payload) {
+			res = append(res, rev)
+		}
+	}
+	if len(res) == 0 {
+		return nil, ErrNotFound
+	}
+	return res, nil
+}
+
+// record records a revision for each item if history is enabled.
+func (r *Resource) record(ctx context.Context, op RevisionOp, items ...*Item) error {
+	if r.conf.History == nil {
+		return nil
+	}
+	var actor interface{}
+	if r.conf.HistoryActor != nil {
+		actor = r.conf.HistoryActor(ctx)
+	} else if p, ok := PrincipalFromContext(ctx); ok {
+		actor = p
+	}
+	now := time.Now()
+	revs := make([]*Revision, len(items))
+	for i, item := range items {
+		payload := make(map[string]interface{}, len(item.Payload))
+		for k, v := range item.Payload {
+			payload[k] = v
+		}
+		t := item.Updated
+		if t.IsZero() || op == RevisionDelete {
+			t = now
+		}
+		revs[i] = &Revision{
+			Resource: r.path,
+			ItemID:   item.ID,
+			Op:       op,
+			ETag:     item.ETag,
+			Payload:  payload,
+			Time:     t,
+			Actor:    actor,
+		}
+	}
+	return r.conf.History.Record(ctx, revs)
+}
+
+// recordedClear removes the items matching q from the storage and records
+// their deletion.
+func (r *Resource) recordedClear(ctx context.Context, q *query.Query) (int, error) {
+	list, err := r.storage.Find(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := r.storage.Clear(ctx, q)
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, r.record(ctx, RevisionDelete, list.Items...)
+}
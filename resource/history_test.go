@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+type testHistoryStorer struct {
+	revisions History
+}
+
+func (h *testHistoryStorer) Record(ctx context.Context, revisions []*Revision) error {
+	h.revisions = append(h.revisions, revisions...)
+	return nil
+}
+
+func (h *testHistoryStorer) History(ctx context.Context, path string, id interface{}) (History, error) {
+	res := History{}
+	for _, rev := range h.revisions {
+		if rev.Resource == path && rev.ItemID == id {
+			res = append(res, rev)
+		}
+	}
+	return res, nil
+}
+
+func TestHistoryRecord(t *testing.T) {
+	h := &testHistoryStorer{}
+	s := newTestMStorer()
+	stored := []*Item{{ID: "2", Payload: map[string]interface{}{"id": "2", "owner": "john"}}}
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		l := &ItemList{}
+		for _, i := range stored {
+			if q.Predicate.Match(i.Payload) {
+				l.Items = append(l.Items, i)
+			}
+		}
+		return l, nil
+	}
+	index := NewIndex()
+	r := index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}, "owner": {Filterable: true}, "name": {}}}, s, Conf{
+		AllowedModes: ReadWrite,
+		Policy:       Policy{Read: `{owner: $user.id}`, Create: "", Update: "", Delete: "", Clear: ""},
+		History:      h,
+	})
+	ctx := NewContextWithPrincipal(context.Background(), Principal{"id": "john"})
+
+	item, _ := NewItem(map[string]interface{}{"id": "1", "owner": "john"})
+	assert.NoError(t, r.Insert(ctx, []*Item{item}))
+	updated, _ := NewItem(map[string]interface{}{"id": "1", "owner": "john", "name": "foo"})
+	assert.NoError(t, r.Update(ctx, updated, item))
+	stored = append(stored, updated)
+	assert.NoError(t, r.Delete(ctx, updated))
+	stored = stored[:1]
+	n, err := r.Clear(ctx, &query.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	if assert.Len(t, h.revisions, 4) {
+		ops := []RevisionOp{}
+		for _, rev := range h.revisions {
+			ops = append(ops, rev.Op)
+			assert.Equal(t, "foo", rev.Resource)
+			assert.Equal(t, Principal{"id": "john"}, rev.Actor)
+		}
+		assert.Equal(t, []RevisionOp{RevisionInsert, RevisionUpdate, RevisionDelete, RevisionDelete}, ops)
+		assert.Equal(t, item.ETag, h.revisions[0].ETag)
+		assert.Equal(t, "2", h.revisions[3].ItemID)
+	}
+
+	// Revisions not visible to the principal are omitted.
+	h.revisions = append(h.revisions, &Revision{Resource: "foo", ItemID: "1", Payload: map[string]interface{}{"id": "1", "owner": "paul"}})
+	revs, err := r.History(ctx, "1")
+	if assert.NoError(t, err) && assert.Len(t, revs, 3) {
+		assert.Equal(t, RevisionInsert, revs[0].Op)
+		assert.Equal(t, "foo", revs[1].Payload["name"])
+	}
+	_, err = r.History(NewContextWithPrincipal(ctx, Principal{"id": "ringo"}), "1")
+	assert.Equal(t, ErrNotFound, err)
+
+	// Get hooks apply to the history.
+	r.Use(GetEventHandlerFunc(func(ctx context.Context, id interface{}) error {
+		return ErrForbidden
+	}))
+	_, err = r.History(ctx, "1")
+	assert.Equal(t, ErrForbidden, err)
+
+	r = index.Bind("bar", schema.Schema{}, s, Conf{})
+	_, err = r.History(ctx, "1")
+	assert.Equal(t, ErrNotImplemented, err)
+}
+
+func TestHistoryLookup(t *testing.T) {
+	t1 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	h := History{
+		{ETag: "a", Time: t1},
+		{ETag: "b", Time: t2},
+	}
+	assert.Nil(t, h.At(t1.Add(-time.Second)))
+	assert.Equal(t, "a", h.At(t1).ETag)
+	assert.Equal(t, "a", h.At(t2.Add(-time.Second)).ETag)
+	assert.Equal(t, "b", h.At(t2.Add(time.Hour)).ETag)
+	assert.Equal(t, "b", h.ETag("b").ETag)
+	assert.Nil(t, h.ETag("c"))
+}
@@ -108,7 +108,9 @@ type refChecker struct {
 	index Index
 }
 
-// ReferenceChecker implements the schema.ReferenceChecker interface.
+// ReferenceChecker implements the schema.ReferenceChecker interface. The
+// returned validator only checks that the referenced item exists, whatever the
+// policies and tenant of the request.
 func (rc refChecker) ReferenceChecker(path string) (schema.FieldValidator, schema.Validator) {
 	rsc, exists := rc.index.GetResource(path, nil)
 	if !exists {
@@ -129,7 +131,11 @@ func (rc refChecker) ReferenceChecker(path string) (schema.FieldValidator, schem
 			id = value
 		}
 
-		_, err = rsc.Get(context.TODO(), id)
+		// The validator doesn't provide the context of the request, so
+		// existence checks are not subject to access control policies nor
+		// tenant scoping. References to items of other tenants are rejected
+		// by Insert and Update instead (see TenantFunc).
+		_, err = rsc.Get(unrestricted(context.TODO()), id)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,212 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// dependent is a resource whose items reference the items of another resource
+// thru field, with the action to perform on them when the referenced items are
+// deleted. The path of the field in the payload is used to unset references.
+type dependent struct {
+	resource *Resource
+	field    string
+	path     []string
+	action   schema.DeleteAction
+}
+
+// compileIntegrity registers r as a dependent of the resources referenced by
+// its fields with an OnDelete action, including the fields nested in
+// sub-schemas, and the sub-resources of r with an OnParentDelete action as
+// dependents of r.
+//
+// As dependents are looked up with a query predicate, OnDelete actions are not
+// supported on references nested in arrays of objects or in dictionaries.
+// DeleteSetNull is not supported on required fields.
+func (r *Resource) compileIntegrity(rc schema.ReferenceChecker) error {
+	if c, ok := rc.(refChecker); ok {
+		for _, f := range refFields(&r.schema) {
+			if f.ref.OnDelete == schema.DeleteNoAction {
+				continue
+			}
+			if f.field == "" {
+				return fmt.Errorf("integrity: %s: OnDelete is not supported on references nested in arrays of objects or dictionaries", f.name())
+			}
+			if f.ref.OnDelete == schema.DeleteSetNull && f.def.Required {
+				return fmt.Errorf("integrity: %s: DeleteSetNull is not supported on required fields", f.name())
+			}
+			if target, found := c.index.GetResource(f.ref.Path, nil); found {
+				target.addDependent(dependent{r, f.field, f.path, f.ref.OnDelete})
+			}
+		}
+	}
+	for _, sr := range r.resources {
+		if sr.conf.OnParentDelete == schema.DeleteNoAction {
+			continue
+		}
+		if sr.conf.OnParentDelete == schema.DeleteSetNull {
+			if def := sr.schema.Fields[sr.parentField]; def.Required {
+				return fmt.Errorf("integrity: %s: DeleteSetNull is not supported on required fields", sr.parentField)
+			}
+		}
+		r.addDependent(dependent{sr, sr.parentField, []string{sr.parentField}, sr.conf.OnParentDelete})
+	}
+	return nil
+}
+
+// addDependent adds d to the dependents of r if not already present, as the
+// resource graph can be compiled several times.
+func (r *Resource) addDependent(d dependent) {
+	for _, e := range r.dependents {
+		if e.resource == d.resource && e.field == d.field {
+			return
+		}
+	}
+	r.dependents = append(r.dependents, d)
+}
+
+// cascade calls del after performing the OnDelete actions of the dependents of
+// the items with the ids returned by lookup. When r has dependents, the whole
+// operation is performed within a transaction (see Transaction).
+func (r *Resource) cascade(ctx context.Context, purge bool, lookup func(ctx context.Context) ([]interface{}, error), del func(ctx context.Context) error) error {
+	if len(r.dependents) == 0 {
+		return del(ctx)
+	}
+	return Transaction(ctx, func(ctx context.Context) error {
+		ids, err := lookup(ctx)
+		if err != nil {
+			return err
+		}
+		if err = r.deleteDependents(ctx, ids, purge); err != nil {
+			return err
+		}
+		return del(ctx)
+	})
+}
+
+// deleteDependents performs the OnDelete actions of the dependents of the items
+// with ids. If an item is referenced by a dependent with the DeleteRestrict
+// action, ErrReferenced is returned before any change is performed.
+//
+// Dependents are looked up and changed regardless of the policies and tenant
+// of the request. When purge is true, soft deleted dependents are looked up
+// and purged too. As soft deleted items can be restored, only the
+// DeleteRestrict actions are performed when soft deleting items, the other
+// actions being performed once the items are purged.
+func (r *Resource) deleteDependents(ctx context.Context, ids []interface{}, purge bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx = unrestricted(ctx)
+	if purge {
+		ctx = NewContextWithDeletedFilter(ctx, IncludeDeleted)
+	} else {
+		ctx = NewContextWithDeletedFilter(ctx, ExcludeDeleted)
+	}
+	values := make([]query.Value, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	lookup := func(d dependent, limit int) ([]*Item, error) {
+		q := &query.Query{Predicate: query.Predicate{&query.In{Field: d.field, Values: values}}}
+		if limit > 0 {
+			q.Window = &query.Window{Limit: limit}
+		}
+		l, err := d.resource.Find(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	}
+	for _, d := range r.dependents {
+		if d.action != schema.DeleteRestrict {
+			continue
+		}
+		items, err := lookup(d, 1)
+		if err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			return ErrReferenced
+		}
+	}
+	if r.conf.SoftDeleteField != "" && !purge {
+		return nil
+	}
+	// Dependents are looked up right before being changed as an item may
+	// reference the deleted items thru several fields.
+	for _, d := range r.dependents {
+		if d.action == schema.DeleteRestrict {
+			continue
+		}
+		items, err := lookup(d, 0)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if d.action == schema.DeleteCascade {
+				err = d.resource.delete(ctx, item, purge)
+			} else {
+				err = d.resource.unsetReference(ctx, item, d.path, ids)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unsetReference updates item so the field at path doesn't reference the items
+// with ids anymore. Single references are removed from the payload while the
+// ids are removed from arrays of references.
+func (r *Resource) unsetReference(ctx context.Context, item *Item, path []string, ids []interface{}) error {
+	updated, err := NewItem(unsetPath(item.Payload, path, ids))
+	if err != nil {
+		return err
+	}
+	return r.Update(ctx, updated, item)
+}
+
+// unsetPath returns a copy of payload without the references to ids held by
+// the field at path. The objects holding the field are copied too.
+func unsetPath(payload map[string]interface{}, path []string, ids []interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		res[k] = v
+	}
+	name := path[0]
+	switch {
+	case len(path) == 1:
+		delete(res, name)
+	case path[1] == "*":
+		if refs, ok := res[name].([]interface{}); ok {
+			kept := []interface{}{}
+			for _, ref := range refs {
+				if !contains(ids, ref) {
+					kept = append(kept, ref)
+				}
+			}
+			res[name] = kept
+		}
+	default:
+		if sub, ok := res[name].(map[string]interface{}); ok {
+			res[name] = unsetPath(sub, path[1:], ids)
+		}
+	}
+	return res
+}
+
+// contains returns true if v is in values.
+func contains(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
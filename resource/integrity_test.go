@@ -0,0 +1,218 @@
+package resource
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+// newIntegrityTestStorer returns a storer keeping the items in the items map.
+func newIntegrityTestStorer(items map[interface{}]*Item) *testMStorer {
+	s := newTestMStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		l := &ItemList{Total: -1, Items: []*Item{}}
+		for _, i := range items {
+			if q.Predicate.Match(i.Payload) {
+				l.Items = append(l.Items, i)
+			}
+		}
+		sort.Slice(l.Items, func(i, j int) bool { return l.Items[i].ID.(string) < l.Items[j].ID.(string) })
+		return l, nil
+	}
+	s.multiGet = func(ctx context.Context, ids []interface{}) ([]*Item, error) {
+		res := make([]*Item, len(ids))
+		for i, id := range ids {
+			res[i] = items[id]
+		}
+		return res, nil
+	}
+	s.insert = func(ctx context.Context, newItems []*Item) error {
+		for _, i := range newItems {
+			items[i.ID] = i
+		}
+		return nil
+	}
+	s.update = func(ctx context.Context, item *Item, original *Item) error {
+		items[item.ID] = item
+		return nil
+	}
+	s.delete = func(ctx context.Context, item *Item) error {
+		delete(items, item.ID)
+		return nil
+	}
+	s.clear = func(ctx context.Context, q *query.Query) (int, error) {
+		n := 0
+		for id, i := range items {
+			if q.Predicate.Match(i.Payload) {
+				delete(items, id)
+				n++
+			}
+		}
+		return n, nil
+	}
+	return s
+}
+
+func newIntegrityTestItems(payloads ...map[string]interface{}) map[interface{}]*Item {
+	items := map[interface{}]*Item{}
+	for _, p := range payloads {
+		i, _ := NewItem(p)
+		items[i.ID] = i
+	}
+	return items
+}
+
+func TestIntegrity(t *testing.T) {
+	users := newIntegrityTestItems(
+		map[string]interface{}{"id": "u1"},
+		map[string]interface{}{"id": "u2"},
+	)
+	tokens := newIntegrityTestItems(
+		map[string]interface{}{"id": "k1", "user": "u1"},
+		map[string]interface{}{"id": "k2", "user": "u2"},
+	)
+	tags := newIntegrityTestItems(
+		map[string]interface{}{"id": "t1"},
+		map[string]interface{}{"id": "t2"},
+		map[string]interface{}{"id": "t3"},
+	)
+	posts := newIntegrityTestItems(
+		map[string]interface{}{"id": "p1", "author": "u1", "tags": []interface{}{"t1", "t2"}, "main": "t1",
+			"meta": map[string]interface{}{"tag": "t2", "tags": []interface{}{"t2", "t3"}}},
+	)
+	index := NewIndex()
+	u := index.Bind("users", schema.Schema{Fields: schema.Fields{"id": {}}}, newIntegrityTestStorer(users), DefaultConf)
+	u.Bind("tokens", "user", schema.Schema{Fields: schema.Fields{"id": {}, "user": {}}}, newIntegrityTestStorer(tokens), Conf{
+		AllowedModes:   ReadWrite,
+		OnParentDelete: schema.DeleteCascade,
+	})
+	tg := index.Bind("tags", schema.Schema{Fields: schema.Fields{"id": {}}}, newIntegrityTestStorer(tags), DefaultConf)
+	index.Bind("posts", schema.Schema{Fields: schema.Fields{
+		"id":     {},
+		"author": {Validator: &schema.Reference{Path: "users", OnDelete: schema.DeleteRestrict}},
+		"tags": {Validator: &schema.Array{Values: schema.Field{
+			Validator: &schema.Reference{Path: "tags", OnDelete: schema.DeleteSetNull},
+		}}},
+		"main": {Validator: &schema.Reference{Path: "tags", OnDelete: schema.DeleteSetNull}},
+		"meta": {Schema: &schema.Schema{Fields: schema.Fields{
+			"tag": {Validator: &schema.Reference{Path: "tags", OnDelete: schema.DeleteSetNull}},
+			"tags": {Validator: &schema.Array{Values: schema.Field{
+				Validator: &schema.Reference{Path: "tags", OnDelete: schema.DeleteSetNull},
+			}}},
+		}}},
+	}}, newIntegrityTestStorer(posts), DefaultConf)
+	// Compiling twice doesn't duplicate the actions.
+	for i := 0; i < 2; i++ {
+		if err := index.(Compiler).Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Len(t, u.dependents, 2)
+	assert.Len(t, tg.dependents, 4)
+	ctx := context.Background()
+
+	// Referenced items can't be deleted.
+	assert.Equal(t, ErrReferenced, u.Delete(ctx, users["u1"]))
+	assert.Len(t, users, 2)
+	assert.Len(t, tokens, 2)
+	_, err := u.Clear(ctx, &query.Query{})
+	assert.Equal(t, ErrReferenced, err)
+	assert.Len(t, users, 2)
+
+	// Sub-resource items are deleted with their parent.
+	assert.NoError(t, u.Delete(ctx, users["u2"]))
+	assert.Len(t, users, 1)
+	assert.Contains(t, tokens, "k1")
+	assert.NotContains(t, tokens, "k2")
+
+	// References are removed.
+	assert.NoError(t, tg.Delete(ctx, tags["t1"]))
+	assert.Equal(t, map[string]interface{}{"id": "p1", "author": "u1", "tags": []interface{}{"t2"},
+		"meta": map[string]interface{}{"tag": "t2", "tags": []interface{}{"t2", "t3"}}}, posts["p1"].Payload)
+	n, err := tg.Clear(ctx, &query.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[string]interface{}{"id": "p1", "author": "u1", "tags": []interface{}{},
+		"meta": map[string]interface{}{"tags": []interface{}{}}}, posts["p1"].Payload)
+}
+
+func TestIntegritySoftDelete(t *testing.T) {
+	users := newIntegrityTestItems(
+		map[string]interface{}{"id": "u1"},
+		map[string]interface{}{"id": "u2"},
+	)
+	tokens := newIntegrityTestItems(
+		map[string]interface{}{"id": "k1", "user": "u1"},
+		map[string]interface{}{"id": "k2", "user": "u2"},
+	)
+	teams := newIntegrityTestItems(
+		map[string]interface{}{"id": "t1", "leader": "u1"},
+	)
+	index := NewIndex()
+	u := index.Bind("users", schema.Schema{Fields: schema.Fields{"id": {}, "deleted": {ReadOnly: true}}}, newIntegrityTestStorer(users), Conf{
+		AllowedModes:    append(ReadWrite, SoftDeleteModes...),
+		SoftDeleteField: "deleted",
+	})
+	u.Bind("tokens", "user", schema.Schema{Fields: schema.Fields{"id": {}, "user": {}}}, newIntegrityTestStorer(tokens), Conf{
+		AllowedModes:   ReadWrite,
+		OnParentDelete: schema.DeleteCascade,
+	})
+	index.Bind("teams", schema.Schema{Fields: schema.Fields{
+		"id":     {},
+		"leader": {Validator: &schema.Reference{Path: "users", OnDelete: schema.DeleteRestrict}},
+	}}, newIntegrityTestStorer(teams), DefaultConf)
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Restrict actions are checked on soft delete.
+	assert.Equal(t, ErrReferenced, u.Delete(ctx, users["u1"]))
+
+	// Dependents are left untouched until the item is purged, so it can be
+	// restored.
+	assert.NoError(t, u.Delete(ctx, users["u2"]))
+	assert.Contains(t, users["u2"].Payload, "deleted")
+	assert.Contains(t, tokens, "k2")
+	restored, err := u.Restore(ctx, users["u2"])
+	assert.NoError(t, err)
+	assert.NoError(t, u.Delete(ctx, restored))
+	assert.NoError(t, u.Purge(ctx, users["u2"]))
+	assert.NotContains(t, users, "u2")
+	assert.NotContains(t, tokens, "k2")
+	assert.Contains(t, tokens, "k1")
+}
+
+func TestIntegrityCompile(t *testing.T) {
+	ref := &schema.Reference{Path: "foo", OnDelete: schema.DeleteSetNull}
+	cases := []struct {
+		field schema.Field
+		err   string
+	}{
+		{schema.Field{Validator: ref}, ""},
+		{schema.Field{Validator: ref, Required: true}, "bar: integrity: ref: DeleteSetNull is not supported on required fields"},
+		{schema.Field{Validator: &schema.Object{Schema: &schema.Schema{Fields: schema.Fields{
+			"ref": {Validator: ref, Required: true},
+		}}}}, "bar: integrity: ref.ref: DeleteSetNull is not supported on required fields"},
+		{schema.Field{Validator: &schema.Array{Values: schema.Field{Validator: &schema.Object{Schema: &schema.Schema{Fields: schema.Fields{
+			"ref": {Validator: ref},
+		}}}}}}, "bar: integrity: ref.ref: OnDelete is not supported on references nested in arrays of objects or dictionaries"},
+		{schema.Field{Validator: &schema.Dict{Values: schema.Field{Validator: ref}}},
+			"bar: integrity: ref: OnDelete is not supported on references nested in arrays of objects or dictionaries"},
+	}
+	for _, tc := range cases {
+		index := NewIndex()
+		index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, nil, DefaultConf)
+		index.Bind("bar", schema.Schema{Fields: schema.Fields{"id": {}, "ref": tc.field}}, nil, DefaultConf)
+		err := index.(Compiler).Compile()
+		if tc.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tc.err)
+		}
+	}
+}
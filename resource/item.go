@@ -2,6 +2,7 @@ package resource
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -67,7 +68,8 @@ func (i *Item) GetField(name string) interface{} {
 }
 
 // getField gets the value of a given field by supporting sub-field path. A get
-// on field.subfield is equivalent to payload["field"]["subfield].
+// on field.subfield is equivalent to payload["field"]["subfield], and a get on
+// field.0 to payload["field"][0] for arrays.
 func getField(payload map[string]interface{}, name string) interface{} {
 	// Split the name to get the current level name on first element and the
 	// rest of the path as second element if dot notation is used (i.e.:
@@ -75,15 +77,32 @@ func getField(payload map[string]interface{}, name string) interface{} {
 	path := strings.SplitN(name, ".", 2)
 	if value, found := payload[path[0]]; found {
 		if len(path) == 2 {
-			if subPayload, ok := value.(map[string]interface{}); ok {
-				// Check next level
-				return getField(subPayload, path[1])
-			}
-			// The requested depth does not exist
-			return nil
+			return getSubField(value, path[1])
 		}
 		// Full path has been found
 		return value
 	}
 	return nil
 }
+
+// getSubField gets the value of the sub-field name of value, which is either
+// an object or an array indexed by the first element of the path.
+func getSubField(value interface{}, name string) interface{} {
+	switch t := value.(type) {
+	case map[string]interface{}:
+		// Check next level
+		return getField(t, name)
+	case []interface{}:
+		path := strings.SplitN(name, ".", 2)
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(t) {
+			return nil
+		}
+		if len(path) == 2 {
+			return getSubField(t[i], path[1])
+		}
+		return t[i]
+	}
+	// The requested depth does not exist
+	return nil
+}
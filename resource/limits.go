@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// QueryLimits defines safeguards against costly queries. Zero values mean no
+// limit.
+type QueryLimits struct {
+	// MaxPredicateDepth is the maximum nesting depth of the filter. Top-level
+	// expressions have a depth of 1, and each $and, $or or $elemMatch adds a
+	// level to the expressions it contains.
+	MaxPredicateDepth int
+	// MaxPredicateClauses is the maximum number of expressions in the filter,
+	// including the nested ones but not counting the $and and $or operators
+	// themselves.
+	MaxPredicateClauses int
+	// AllowedOperators restricts the operators usable on a field, the key
+	// being the field name and the value the list of allowed operators as
+	// named in the filter syntax (i.e.: $in, $regex). Equality is named $eq.
+	// Fields not listed accept all operators. The fields of $elemMatch
+	// sub-expressions are relative to the array field.
+	AllowedOperators map[string][]string
+	// MaxLimit is the maximum number of items per page. When set, it is also
+	// the default page size of resources without PaginationDefaultLimit, and
+	// caps the PaginationDefaultLimit.
+	MaxLimit int
+	// MaxSkip is the maximum offset of the first item of a page, whether it
+	// results from the skip or the page parameter.
+	MaxSkip int
+	// MaxProjectionDepth is the maximum nesting depth of the field selection,
+	// top-level fields having a depth of 1.
+	MaxProjectionDepth int
+	// MaxScan is the maximum number of items scanned in memory when the
+	// storer can't perform an operation natively (see Aggregator, Distincter
+	// and TextSearcher). Operations matching more items fail with
+	// ErrScanLimit.
+	MaxScan int
+}
+
+// CheckPredicate returns an error explaining which limit p exceeds, if any.
+func (l QueryLimits) CheckPredicate(p query.Predicate) error {
+	clauses := 0
+	return query.Walk(p, func(exp query.Expression, depth int) error {
+		if l.MaxPredicateDepth > 0 && depth > l.MaxPredicateDepth {
+			return fmt.Errorf("exceeds the maximum depth of %d", l.MaxPredicateDepth)
+		}
+		field, op := query.Operator(exp)
+		if op == "$and" || op == "$or" {
+			return nil
+		}
+		if clauses++; l.MaxPredicateClauses > 0 && clauses > l.MaxPredicateClauses {
+			return fmt.Errorf("exceeds the maximum of %d clauses", l.MaxPredicateClauses)
+		}
+		if allowed, found := l.AllowedOperators[field]; found && !containsString(allowed, op) {
+			return fmt.Errorf("%s: operator %s not allowed", field, op)
+		}
+		return nil
+	})
+}
+
+// CheckProjection returns an error if p is nested deeper than allowed.
+func (l QueryLimits) CheckProjection(p query.Projection) error {
+	if l.MaxProjectionDepth > 0 && projectionDepth(p) > l.MaxProjectionDepth {
+		return fmt.Errorf("exceeds the maximum depth of %d", l.MaxProjectionDepth)
+	}
+	return nil
+}
+
+func projectionDepth(p query.Projection) int {
+	max := 0
+	for _, f := range p {
+		if d := 1 + projectionDepth(f.Children); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// CheckLimit returns an error if limit exceeds MaxLimit.
+func (l QueryLimits) CheckLimit(limit int) error {
+	if l.MaxLimit > 0 && limit > l.MaxLimit {
+		return fmt.Errorf("exceeds the maximum of %d", l.MaxLimit)
+	}
+	return nil
+}
+
+// CheckSkip returns an error if skip exceeds MaxSkip.
+func (l QueryLimits) CheckSkip(skip int) error {
+	if l.MaxSkip > 0 && skip > l.MaxSkip {
+		return fmt.Errorf("exceeds the maximum of %d", l.MaxSkip)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryLimits(t *testing.T) {
+	var l QueryLimits
+	p := query.MustParsePredicate(`{$or: [{a: 1}, {$and: [{b: 1}, {c: {$regex: "x"}}]}]}`)
+	assert.NoError(t, l.CheckPredicate(p), "no limit")
+
+	l = QueryLimits{MaxPredicateDepth: 2}
+	assert.EqualError(t, l.CheckPredicate(p), "exceeds the maximum depth of 2")
+	l = QueryLimits{MaxPredicateDepth: 3}
+	assert.NoError(t, l.CheckPredicate(p))
+
+	l = QueryLimits{MaxPredicateClauses: 2}
+	assert.EqualError(t, l.CheckPredicate(p), "exceeds the maximum of 2 clauses")
+	l = QueryLimits{MaxPredicateClauses: 3}
+	assert.NoError(t, l.CheckPredicate(p))
+
+	l = QueryLimits{AllowedOperators: map[string][]string{"c": {"$eq", "$in"}}}
+	assert.EqualError(t, l.CheckPredicate(p), "c: operator $regex not allowed")
+	assert.NoError(t, l.CheckPredicate(query.MustParsePredicate(`{c: "x", a: {$regex: "x"}}`)))
+
+	l = QueryLimits{MaxProjectionDepth: 2}
+	proj, _ := query.ParseProjection(`a,b{c,d{e}}`)
+	assert.EqualError(t, l.CheckProjection(proj), "exceeds the maximum depth of 2")
+	proj, _ = query.ParseProjection(`a,b{c,d}`)
+	assert.NoError(t, l.CheckProjection(proj))
+
+	l = QueryLimits{MaxLimit: 10, MaxSkip: 100}
+	assert.NoError(t, l.CheckLimit(10))
+	assert.EqualError(t, l.CheckLimit(11), "exceeds the maximum of 10")
+	assert.NoError(t, l.CheckSkip(100))
+	assert.EqualError(t, l.CheckSkip(101), "exceeds the maximum of 100")
+}
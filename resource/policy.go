@@ -0,0 +1,269 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// Policy defines declarative access control rules for a resource. Each rule is
+// a query predicate, as accepted by the filter parameter, that an item must
+// match for the mode to be performed on it. A rule can refer to the attributes
+// of the Principal stored in the context using $user.<attribute> placeholders:
+//
+//     resource.Policy{
+//         resource.Read:   `{$or: [{public: true}, {owner: $user.id}]}`,
+//         resource.Create: `{owner: $user.id}`,
+//         resource.Update: `{owner: $user.id}`,
+//         resource.Delete: `{owner: $user.id}`,
+//     }
+//
+// An empty rule (or {}) allows the mode on all items, while a mode without
+// rule is denied with ErrForbidden. The rules are enforced as follow:
+//
+//   - Read restricts the items returned by Find, Get and MultiGet. Items not
+//     matching the rule are treated as not found. It also applies to the List,
+//     Aggregate and Distinct modes.
+//   - Create is checked on items passed to Insert, after the insert hooks.
+//   - Update is checked on both the original and the new version of the item
+//     passed to Update, after the update hooks. It also applies to the Replace
+//     mode. An original item not matching the Read rule is not found.
+//   - Delete is checked on the item passed to Delete. An item not matching the
+//     Read rule is not found.
+//   - Clear is added to the query predicate passed to Clear.
+//   - Restore is controlled by the Update rule, while Purge is controlled by
+//     the Delete and Clear rules.
+//
+// If a rule refers to an attribute and the context holds no principal, or the
+// principal has no such attribute, the operation is denied with ErrForbidden.
+type Policy map[Mode]string
+
+// Principal holds the attributes of the entity performing a request, as
+// referred to by Policy rules. Attributes can be nested using maps.
+type Principal map[string]interface{}
+
+type principalKey struct{}
+
+// NewContextWithPrincipal stores the principal p into the context.
+func NewContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext retrieves the principal stored into the context if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+type unrestrictedKey struct{}
+
+// unrestricted returns a context on which neither policies nor tenant scoping
+// are enforced, for internal lookups like reference checks.
+func unrestricted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unrestrictedKey{}, true)
+}
+
+// principalPlaceholder is the prefix of the principal attributes in rules.
+const principalPlaceholder = "$user."
+
+// compile checks the syntax of the rules.
+func (p Policy) compile() error {
+	for mode, rule := range p {
+		switch mode {
+		case Replace:
+			return fmt.Errorf("policy: Replace mode is controlled by the Update rule")
+		case List:
+			return fmt.Errorf("policy: List mode is controlled by the Read rule")
+		case Restore:
+			return fmt.Errorf("policy: Restore mode is controlled by the Update rule")
+		case Purge:
+			return fmt.Errorf("policy: Purge mode is controlled by the Delete and Clear rules")
+		case Aggregate:
+			return fmt.Errorf("policy: Aggregate mode is controlled by the Read rule")
+		case Distinct:
+			return fmt.Errorf("policy: Distinct mode is controlled by the Read rule")
+		}
+		// Check the syntax with placeholders resolving to sample values, as
+		// the type of the attributes is only known at request time.
+		var err error
+		for _, sample := range []interface{}{nil, []interface{}{}, ""} {
+			var r string
+			if r, err = expandRule(rule, func(string) (interface{}, bool) { return sample, true }); err != nil {
+				break
+			}
+			if _, err = query.ParsePredicate(r); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("policy: invalid %s rule: %v", modeName(mode), err)
+		}
+	}
+	return nil
+}
+
+// predicate returns the predicate an item must match for mode to be performed
+// on it, given the principal stored in ctx. A nil predicate is returned if the
+// policy doesn't apply.
+func (p Policy) predicate(ctx context.Context, mode Mode, v schema.Validator) (query.Predicate, error) {
+	if p == nil || ctx.Value(unrestrictedKey{}) != nil {
+		return nil, nil
+	}
+	rule, found := p[mode]
+	if !found {
+		return nil, ErrForbidden
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	r, err := expandRule(rule, func(attr string) (interface{}, bool) {
+		return principalAttr(principal, attr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	pred, err := query.ParsePredicate(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := pred.Prepare(v); err != nil {
+		return nil, fmt.Errorf("policy: %s rule: %v", modeName(mode), err)
+	}
+	return pred, nil
+}
+
+// check returns ErrForbidden if one of the items doesn't match the rule of
+// mode.
+func (p Policy) check(ctx context.Context, mode Mode, v schema.Validator, items ...*Item) error {
+	pred, err := p.predicate(ctx, mode, v)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if pred != nil && !pred.Match(item.Payload) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// scope returns a copy of q with pred added to its predicate, or q if pred is
+// nil.
+func scope(q *query.Query, pred query.Predicate) *query.Query {
+	if pred == nil {
+		return q
+	}
+	scoped := *q
+	scoped.Predicate = make(query.Predicate, 0, len(q.Predicate)+len(pred))
+	scoped.Predicate = append(append(scoped.Predicate, q.Predicate...), pred...)
+	return &scoped
+}
+
+// expandRule replaces the principal placeholders found outside of string
+// literals in rule by the JSON representation of the attribute returned by
+// lookup. If lookup returns false, ErrForbidden is returned.
+func expandRule(rule string, lookup func(attr string) (interface{}, bool)) (string, error) {
+	if strings.TrimSpace(rule) == "" {
+		return "{}", nil
+	}
+	var b bytes.Buffer
+	inString := false
+	for i := 0; i < len(rule); i++ {
+		c := rule[i]
+		if inString {
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(rule) {
+				i++
+				b.WriteByte(rule[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+		} else if strings.HasPrefix(rule[i:], principalPlaceholder) {
+			j := i + len(principalPlaceholder)
+			for j < len(rule) && isAttrChar(rule[j]) {
+				j++
+			}
+			attr := rule[i+len(principalPlaceholder) : j]
+			if attr == "" {
+				return "", fmt.Errorf("missing attribute name at char %d", i)
+			}
+			value, found := lookup(attr)
+			if !found {
+				return "", ErrForbidden
+			}
+			v, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			b.Write(v)
+			i = j - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), nil
+}
+
+func isAttrChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// principalAttr returns the attribute of p at path, using the dot notation to
+// reference nested attributes.
+func principalAttr(p Principal, path string) (interface{}, bool) {
+	if p == nil {
+		return nil, false
+	}
+	var v interface{} = map[string]interface{}(p)
+	for _, name := range strings.Split(path, ".") {
+		var m map[string]interface{}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			m = t
+		case Principal:
+			m = t
+		default:
+			return nil, false
+		}
+		var found bool
+		if v, found = m[name]; !found {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func modeName(m Mode) string {
+	switch m {
+	case Create:
+		return "Create"
+	case Read:
+		return "Read"
+	case Update:
+		return "Update"
+	case Replace:
+		return "Replace"
+	case Delete:
+		return "Delete"
+	case Clear:
+		return "Clear"
+	case List:
+		return "List"
+	case Restore:
+		return "Restore"
+	case Purge:
+		return "Purge"
+	case Aggregate:
+		return "Aggregate"
+	case Distinct:
+		return "Distinct"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
@@ -0,0 +1,188 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPolicyTestResource returns a resource holding the items a (owned by
+// john), b (owned by paul) and c (public, owned by paul). The storer records the
+// queries it receives in queries.
+func newPolicyTestResource(t *testing.T, p Policy, queries *[]*query.Query) *Resource {
+	items := []*Item{
+		{ID: "a", ETag: "a", Payload: map[string]interface{}{"id": "a", "owner": "john", "public": false}},
+		{ID: "b", ETag: "b", Payload: map[string]interface{}{"id": "b", "owner": "paul", "public": false}},
+		{ID: "c", ETag: "c", Payload: map[string]interface{}{"id": "c", "owner": "paul", "public": true}},
+	}
+	s := newTestMStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		*queries = append(*queries, q)
+		l := &ItemList{Total: 0, Items: []*Item{}}
+		for _, i := range items {
+			if q.Predicate.Match(i.Payload) {
+				l.Items = append(l.Items, i)
+				l.Total++
+			}
+		}
+		return l, nil
+	}
+	s.clear = func(ctx context.Context, q *query.Query) (int, error) {
+		*queries = append(*queries, q)
+		return 0, nil
+	}
+	s.multiGet = func(ctx context.Context, ids []interface{}) ([]*Item, error) {
+		res := make([]*Item, len(ids))
+		for j, id := range ids {
+			for _, i := range items {
+				if i.ID == id {
+					res[j] = i
+				}
+			}
+		}
+		return res, nil
+	}
+	index := NewIndex()
+	r := index.Bind("posts", schema.Schema{Fields: schema.Fields{
+		"id":     {},
+		"owner":  {Filterable: true, Validator: &schema.String{}},
+		"public": {Filterable: true, Validator: &schema.Bool{}},
+	}}, s, Conf{AllowedModes: ReadWrite, Policy: p})
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+var testPolicy = Policy{
+	Read:   `{$or: [{public: true}, {owner: $user.id}]}`,
+	Create: `{owner: $user.id}`,
+	Update: `{owner: $user.id}`,
+	Delete: `{owner: $user.id}`,
+}
+
+func TestPolicyFind(t *testing.T) {
+	var queries []*query.Query
+	r := newPolicyTestResource(t, testPolicy, &queries)
+	ctx := NewContextWithPrincipal(context.Background(), Principal{"id": "john"})
+
+	q := &query.Query{Predicate: query.MustParsePredicate(`{id: {$exists: true}}`)}
+	l, err := r.Find(ctx, q)
+	if assert.NoError(t, err) && assert.Len(t, l.Items, 2) {
+		assert.Equal(t, "a", l.Items[0].ID)
+		assert.Equal(t, "c", l.Items[1].ID)
+	}
+	// The query of the caller is left untouched.
+	assert.Len(t, q.Predicate, 1)
+	assert.Equal(t, `{$or: [{owner: "john"}, {public: true}], id: {$exists: true}}`, queries[0].Predicate.String())
+
+	_, err = r.Find(context.Background(), &query.Query{})
+	assert.Equal(t, ErrForbidden, err)
+	_, err = r.Find(NewContextWithPrincipal(context.Background(), Principal{"name": "john"}), &query.Query{})
+	assert.Equal(t, ErrForbidden, err)
+}
+
+func TestPolicyGet(t *testing.T) {
+	var queries []*query.Query
+	r := newPolicyTestResource(t, testPolicy, &queries)
+	ctx := NewContextWithPrincipal(context.Background(), Principal{"id": "john"})
+
+	item, err := r.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", item.ID)
+	_, err = r.Get(ctx, "b")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = r.Get(context.Background(), "a")
+	assert.Equal(t, ErrForbidden, err)
+
+	items, err := r.MultiGet(ctx, []interface{}{"a", "b", "c"})
+	assert.NoError(t, err)
+	if assert.Len(t, items, 3) {
+		assert.Equal(t, "a", items[0].ID)
+		assert.Nil(t, items[1])
+		assert.Equal(t, "c", items[2].ID)
+	}
+}
+
+func TestPolicyWrite(t *testing.T) {
+	var queries []*query.Query
+	r := newPolicyTestResource(t, testPolicy, &queries)
+	john := NewContextWithPrincipal(context.Background(), Principal{"id": "john"})
+
+	assert.NoError(t, r.Insert(john, []*Item{{ID: "d", Payload: map[string]interface{}{"id": "d", "owner": "john"}}}))
+	assert.Equal(t, ErrForbidden, r.Insert(john, []*Item{{ID: "d", Payload: map[string]interface{}{"id": "d", "owner": "paul"}}}))
+
+	a, _ := r.Get(john, "a")
+	assert.NoError(t, r.Update(john, &Item{ID: "a", Payload: map[string]interface{}{"id": "a", "owner": "john", "public": true}}, a))
+	// Giving the item away is not allowed.
+	assert.Equal(t, ErrForbidden, r.Update(john, &Item{ID: "a", Payload: map[string]interface{}{"id": "a", "owner": "paul"}}, a))
+
+	b, _ := r.Get(unrestricted(context.Background()), "b")
+	c, _ := r.Get(NewContextWithPrincipal(context.Background(), Principal{"id": "paul"}), "c")
+	// Not visible by john: 404.
+	assert.Equal(t, ErrNotFound, r.Update(john, &Item{ID: "b", Payload: b.Payload}, b))
+	assert.Equal(t, ErrNotFound, r.Delete(john, b))
+	// Visible but not owned by john: 403.
+	assert.Equal(t, ErrForbidden, r.Update(john, &Item{ID: "c", Payload: c.Payload}, c))
+	assert.Equal(t, ErrForbidden, r.Delete(john, c))
+	assert.NoError(t, r.Delete(john, a))
+
+	// No Clear rule.
+	_, err := r.Clear(john, &query.Query{})
+	assert.Equal(t, ErrForbidden, err)
+}
+
+func TestPolicyClear(t *testing.T) {
+	var queries []*query.Query
+	r := newPolicyTestResource(t, Policy{Clear: `{owner: $user.id}`, Read: ""}, &queries)
+	ctx := NewContextWithPrincipal(context.Background(), Principal{"id": "john"})
+	_, err := r.Clear(ctx, &query.Query{Predicate: query.MustParsePredicate(`{public: false}`)})
+	assert.NoError(t, err)
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, `{public: false, owner: "john"}`, queries[0].Predicate.String())
+	}
+	// An empty rule allows everything, even without principal.
+	l, err := r.Find(context.Background(), &query.Query{})
+	assert.NoError(t, err)
+	assert.Len(t, l.Items, 3)
+}
+
+func TestPolicyCompile(t *testing.T) {
+	cases := []struct {
+		policy Policy
+		err    string
+	}{
+		{Policy{Read: `{owner: {$in: $user.groups}}`}, ""},
+		{Policy{Read: `{owner: "$user.id"}`}, ""},
+		{Policy{Read: `{owner: $user.}`}, "policy: invalid Read rule: missing attribute name at char 8"},
+		{Policy{Update: `{owner; $user.id}`}, "policy: invalid Update rule: char 6: expected ':' got ';'"},
+		{Policy{Replace: ``}, "policy: Replace mode is controlled by the Update rule"},
+		{Policy{Aggregate: ``}, "policy: Aggregate mode is controlled by the Read rule"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.policy[Read]+tc.policy[Update], func(t *testing.T) {
+			err := tc.policy.compile()
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestExpandRule(t *testing.T) {
+	p := Principal{"id": "x\"y", "org": map[string]interface{}{"id": 1}, "groups": []string{"a", "b"}}
+	r, err := expandRule(`{a: $user.id, b: "$user.id", c: $user.org.id, d: {$in: $user.groups}}`, func(attr string) (interface{}, bool) {
+		return principalAttr(p, attr)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{a: "x\"y", b: "$user.id", c: 1, d: {$in: ["a","b"]}}`, r)
+	_, err = expandRule(`{a: $user.name}`, func(attr string) (interface{}, bool) {
+		return principalAttr(p, attr)
+	})
+	assert.Equal(t, ErrForbidden, err)
+}
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+// refField is a reference field of a schema, possibly nested in objects,
+// arrays or dictionaries.
+type refField struct {
+	// path locates the field in payloads, a * component standing for all the
+	// values of an array or a dictionary.
+	path []string
+	// field is the name of the field using the dot notation if the references
+	// can be looked up with a query predicate, or an empty string otherwise
+	// (i.e.: when nested in arrays of objects or in dictionaries).
+	field string
+	ref   *schema.Reference
+	def   schema.Field
+}
+
+// refFields returns the reference fields of s, walking its sub-schemas.
+func refFields(s *schema.Schema) []refField {
+	return appendRefFields(nil, s, nil, "")
+}
+
+// appendRefFields appends the reference fields of the sub-schema s located at
+// path to refs.
+func appendRefFields(refs []refField, s *schema.Schema, path []string, field string) []refField {
+	queryable := field != "" || len(path) == 0
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := ""
+		if queryable {
+			f = strings.TrimPrefix(field+"."+name, ".")
+		}
+		refs = appendRefField(refs, s.Fields[name], appendPath(path, name), f)
+	}
+	return refs
+}
+
+// appendRefField appends the reference fields held by the field def located
+// at path to refs.
+func appendRefField(refs []refField, def schema.Field, path []string, field string) []refField {
+	if def.Schema != nil {
+		return appendRefFields(refs, def.Schema, path, field)
+	}
+	switch v := def.Validator.(type) {
+	case *schema.Reference:
+		refs = append(refs, refField{path: path, field: field, ref: v, def: def})
+	case *schema.Object:
+		if v.Schema != nil {
+			refs = appendRefFields(refs, v.Schema, path, field)
+		}
+	case *schema.Array:
+		// Queries match arrays of values, but not the fields of the objects
+		// they hold.
+		if _, ok := v.Values.Validator.(*schema.Reference); !ok {
+			field = ""
+		}
+		refs = appendRefField(refs, v.Values, appendPath(path, "*"), field)
+	case *schema.Dict:
+		refs = appendRefField(refs, v.Values, appendPath(path, "*"), "")
+	}
+	return refs
+}
+
+// appendPath returns a copy of path with name appended.
+func appendPath(path []string, name string) []string {
+	return append(path[:len(path):len(path)], name)
+}
+
+// name returns the name of the field for error reporting.
+func (f refField) name() string {
+	names := make([]string, 0, len(f.path))
+	for _, n := range f.path {
+		if n != "*" {
+			names = append(names, n)
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// values returns the non nil references held by payload.
+func (f refField) values(payload map[string]interface{}) []interface{} {
+	return appendValues(nil, payload, f.path)
+}
+
+func appendValues(values []interface{}, v interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		if v != nil {
+			values = append(values, v)
+		}
+		return values
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if path[0] != "*" {
+			return appendValues(values, t[path[0]], path[1:])
+		}
+		for _, e := range t {
+			values = appendValues(values, e, path[1:])
+		}
+	case []interface{}:
+		if path[0] == "*" {
+			for _, e := range t {
+				values = appendValues(values, e, path[1:])
+			}
+		}
+	}
+	return values
+}
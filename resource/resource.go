@@ -23,6 +23,16 @@ type Resource struct {
 	resources   subResources
 	aliases     map[string]url.Values
 	hooks       eventHandler
+	// tenantRefs holds the reference fields pointing to resources scoped by
+	// tenant, with their referenced resource.
+	tenantRefs []tenantRef
+	// dependents holds the resources referencing the items of the resource
+	// with an action to perform when those items are deleted.
+	dependents []dependent
+	// unique holds the unique constraints of the resource, and uniqueNative
+	// is true when they are enforced by the storer.
+	unique       []UniqueConstraint
+	uniqueNative bool
 }
 
 type subResources []*Resource
@@ -67,6 +77,24 @@ func (v validatorFallback) GetField(name string) *schema.Field {
 	return v.fallback.GetField(name)
 }
 
+// Schema returns the wrapped schema extended with the fallback fields. It allows
+// encoders to describe the documents returned for the resource.
+func (v validatorFallback) Schema() schema.Schema {
+	s, ok := v.Validator.(schema.Schema)
+	if !ok {
+		return v.fallback
+	}
+	fields := make(schema.Fields, len(s.Fields)+len(v.fallback.Fields))
+	for name, f := range v.fallback.Fields {
+		fields[name] = f
+	}
+	for name, f := range s.Fields {
+		fields[name] = f
+	}
+	s.Fields = fields
+	return s
+}
+
 // newResource creates a new resource with provided spec, handler and config.
 func newResource(name string, s schema.Schema, h Storer, c Conf) *Resource {
 	return &Resource{
@@ -77,7 +105,7 @@ func newResource(name string, s schema.Schema, h Storer, c Conf) *Resource {
 			Validator: s,
 			fallback:  schema.Schema{Fields: schema.Fields{}},
 		},
-		storage:   storageWrapper{h},
+		storage:   storageWrapper{Storer: h, maxScan: c.Limits.MaxScan},
 		conf:      c,
 		resources: subResources{},
 		aliases:   map[string]url.Values{},
@@ -109,6 +137,21 @@ func (r *Resource) Compile(rc schema.ReferenceChecker) error {
 			return fmt.Errorf(": schema compilation error: %s", err)
 		}
 	}
+	if err := r.conf.Policy.compile(); err != nil {
+		return fmt.Errorf(": %s", err)
+	}
+	if err := r.compileTenancy(rc); err != nil {
+		return fmt.Errorf(": %s", err)
+	}
+	if err := r.compileSoftDelete(); err != nil {
+		return fmt.Errorf(": %s", err)
+	}
+	if err := r.compileIntegrity(rc); err != nil {
+		return fmt.Errorf(": %s", err)
+	}
+	if err := r.compileUnique(); err != nil {
+		return fmt.Errorf(": %s", err)
+	}
 	for _, r := range r.resources {
 		if err := r.Compile(rc); err != nil {
 			if err.Error()[0] == ':' {
@@ -245,7 +288,13 @@ func (r *Resource) Get(ctx context.Context, id interface{}) (item *Item, err err
 		}(time.Now())
 	}
 	if err = r.hooks.onGet(ctx, id); err == nil {
-		item, err = r.storage.Get(ctx, id)
+		var visible query.Predicate
+		if visible, err = r.restriction(ctx, Read); err == nil {
+			item, err = r.storage.Get(ctx, id)
+			if err == nil && visible != nil && !visible.Match(item.Payload) {
+				item, err = nil, ErrNotFound
+			}
+		}
 	}
 	r.hooks.onGot(ctx, &item, &err)
 	return
@@ -272,8 +321,18 @@ func (r *Resource) MultiGet(ctx context.Context, ids []interface{}) (items []*It
 		}
 	}
 	// Perform the storage request if none of the pre-hook returned an err.
+	var visible query.Predicate
+	if err == nil {
+		visible, err = r.restriction(ctx, Read)
+	}
 	if err == nil {
 		items, err = r.storage.MultiGet(ctx, ids)
+		for i, item := range items {
+			// Hide the items of other tenants or not matching the policy.
+			if item != nil && visible != nil && !visible.Match(item.Payload) {
+				items[i] = nil
+			}
+		}
 	}
 	var errOverwrite error
 	for i := range ids {
@@ -331,18 +390,65 @@ func (r *Resource) find(ctx context.Context, q *query.Query, forceTotal bool) (l
 			})
 		}(time.Now())
 	}
-	if err = r.hooks.onFind(ctx, q); err == nil {
-		list, err = r.storage.Find(ctx, q)
+	e, explain := ExplanationFromContext(ctx)
+	stage := func(name string, start time.Time) {
+		if explain {
+			e.AddStage(name, start)
+		}
+	}
+	start := time.Now()
+	if explain {
+		err = r.hooks.explainFind(ctx, q, e)
+	} else {
+		err = r.hooks.onFind(ctx, q)
+	}
+	stage("find hooks", start)
+	if err == nil {
+		var visible query.Predicate
+		sq := q
+		start = time.Now()
+		if visible, err = r.restriction(ctx, Read); err == nil {
+			sq = normalize(scope(q, visible))
+			stage("restriction", start)
+			if explain {
+				e.Query = sq
+			}
+			start = time.Now()
+			if sq.Predicate.IsFalse() {
+				// The predicate can't match any item, don't query the storage.
+				list = &ItemList{Total: 0, Items: []*Item{}}
+				if sq.Window != nil {
+					list.Offset, list.Limit = sq.Window.Offset, sq.Window.Limit
+				}
+			} else {
+				list, err = r.storage.Find(ctx, sq)
+			}
+			stage("storage", start)
+		}
 		if err == nil && list.Total == -1 && forceTotal {
 			// Send a query with no window so the storage won't be tempted to
 			// count within the window.
-			list.Total, err = r.storage.Count(ctx, &query.Query{Predicate: q.Predicate})
+			start = time.Now()
+			list.Total, err = r.storage.Count(ctx, &query.Query{Predicate: sq.Predicate})
+			stage("count", start)
+		}
+		if err == nil && explain && !sq.Predicate.IsFalse() {
+			e.Plan, err = r.storage.Explain(ctx, sq)
 		}
 	}
+	start = time.Now()
 	r.hooks.onFound(ctx, q, &list, &err)
+	stage("found hooks", start)
 	return
 }
 
+// normalize returns a copy of q with its predicate normalized.
+func normalize(q *query.Query) *query.Query {
+	nq := *q
+	nq.Predicate = q.Predicate.Normalize()
+	return &nq
+}
+
 // Insert implements Storer interface.
 func (r *Resource) Insert(ctx context.Context, items []*Item) (err error) {
 	if LoggerLevel <= LogLevelDebug && Logger != nil {
@@ -354,8 +460,16 @@ func (r *Resource) Insert(ctx context.Context, items []*Item) (err error) {
 		}(time.Now())
 	}
 	if err = r.hooks.onInsert(ctx, items); err == nil {
-		if err = recalcEtag(items); err == nil {
-			err = r.storage.Insert(ctx, items)
+		if err = r.checkWrite(ctx, Create, items...); err == nil {
+			if err = r.checkUnique(ctx, nil, items...); err == nil {
+				if err = recalcEtag(items); err == nil {
+					if err = r.storage.Insert(ctx, items); err == nil {
+						err = r.record(ctx, RevisionInsert, items...)
+					} else {
+						err = r.uniqueError(err)
+					}
+				}
+			}
 		}
 	}
 	r.hooks.onInserted(ctx, items, &err)
@@ -391,36 +505,76 @@ func (r *Resource) Update(ctx context.Context, item *Item, original *Item) (err
 		}(time.Now())
 	}
 	if err = r.hooks.onUpdate(ctx, item, original); err == nil {
-		if err = recalcEtag([]*Item{item}); err == nil {
-			err = r.storage.Update(ctx, item, original)
+		if err = r.checkOriginal(ctx, Update, original); err == nil {
+			if err = r.checkWrite(ctx, Update, item); err == nil {
+				if err = r.checkUnique(ctx, original, item); err == nil {
+					if err = recalcEtag([]*Item{item}); err == nil {
+						if err = r.storage.Update(ctx, item, original); err == nil {
+							err = r.record(ctx, RevisionUpdate, item)
+						} else {
+							err = r.uniqueError(err)
+						}
+					}
+				}
+			}
 		}
 	}
 	r.hooks.onUpdated(ctx, item, original, &err)
 	return
 }
 
-// Delete implements Storer interface.
+// Delete implements Storer interface. When soft delete is enabled, the item is
+// marked as deleted instead of being removed from the storage.
 func (r *Resource) Delete(ctx context.Context, item *Item) (err error) {
+	return r.delete(ctx, item, false)
+}
+
+func (r *Resource) delete(ctx context.Context, item *Item, purge bool) (err error) {
 	if LoggerLevel <= LogLevelDebug && Logger != nil {
+		op := "Delete"
+		if purge {
+			op = "Purge"
+		}
 		defer func(t time.Time) {
-			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.Delete(%v)", r.path, item.ID), map[string]interface{}{
+			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.%s(%v)", r.path, op, item.ID), map[string]interface{}{
 				"duration": time.Since(t),
 				"error":    err,
 			})
 		}(time.Now())
 	}
 	if err = r.hooks.onDelete(ctx, item); err == nil {
-		err = r.storage.Delete(ctx, item)
+		if err = r.checkOriginal(ctx, Delete, item); err == nil {
+			err = r.cascade(ctx, purge, func(ctx context.Context) ([]interface{}, error) {
+				return []interface{}{item.ID}, nil
+			}, func(ctx context.Context) error {
+				if r.conf.SoftDeleteField != "" && !purge {
+					return r.softDelete(ctx, item)
+				}
+				if err := r.storage.Delete(ctx, item); err != nil {
+					return err
+				}
+				return r.record(ctx, RevisionDelete, item)
+			})
+		}
 	}
 	r.hooks.onDeleted(ctx, item, &err)
 	return
 }
 
-// Clear implements Storer interface.
+// Clear implements Storer interface. When soft delete is enabled, the items are
+// marked as deleted instead of being removed from the storage.
 func (r *Resource) Clear(ctx context.Context, q *query.Query) (deleted int, err error) {
+	return r.clear(ctx, q, false)
+}
+
+func (r *Resource) clear(ctx context.Context, q *query.Query, purge bool) (deleted int, err error) {
 	if LoggerLevel <= LogLevelDebug && Logger != nil {
+		op := "Clear"
+		if purge {
+			op = "PurgeAll"
+		}
 		defer func(t time.Time) {
-			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.Clear(%v)", r.path, q), map[string]interface{}{
+			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.%s(%v)", r.path, op, q), map[string]interface{}{
 				"duration": time.Since(t),
 				"deleted":  deleted,
 				"error":    err,
@@ -428,8 +582,76 @@ func (r *Resource) Clear(ctx context.Context, q *query.Query) (deleted int, err
 		}(time.Now())
 	}
 	if err = r.hooks.onClear(ctx, q); err == nil {
-		deleted, err = r.storage.Clear(ctx, q)
+		var scoped query.Predicate
+		if scoped, err = r.restriction(ctx, Clear); err == nil {
+			q := scope(q, scoped)
+			err = r.cascade(ctx, purge, func(ctx context.Context) ([]interface{}, error) {
+				list, err := r.storage.Find(ctx, q)
+				if err != nil {
+					return nil, err
+				}
+				ids := make([]interface{}, len(list.Items))
+				for i, item := range list.Items {
+					ids[i] = item.ID
+				}
+				return ids, nil
+			}, func(ctx context.Context) (err error) {
+				if r.conf.SoftDeleteField != "" && !purge {
+					deleted, err = r.softClear(ctx, q)
+				} else if r.conf.History != nil {
+					deleted, err = r.recordedClear(ctx, q)
+				} else {
+					deleted, err = r.storage.Clear(ctx, q)
+				}
+				return err
+			})
+		}
 	}
 	r.hooks.onCleared(ctx, q, &deleted, &err)
 	return
 }
+
+// restriction returns the predicate the items must match for mode to be
+// performed on them, combining the tenant scope, the soft deleted items filter
+// and the policy rule of mode. A nil predicate is returned if there is no
+// restriction.
+func (r *Resource) restriction(ctx context.Context, mode Mode) (query.Predicate, error) {
+	pred, err := r.conf.Policy.predicate(ctx, mode, r.validator)
+	if err != nil {
+		return nil, err
+	}
+	tenant, scoped, err := r.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scoped {
+		pred = append(query.Predicate{&query.Equal{Field: r.conf.TenantField, Value: tenant}}, pred...)
+	}
+	return append(r.deletedFilter(ctx), pred...), nil
+}
+
+// checkOriginal checks the policy allows mode on the stored item original. An
+// item of another tenant or not matching the Read rule is reported as not
+// found, so its existence is not disclosed.
+func (r *Resource) checkOriginal(ctx context.Context, mode Mode, original *Item) error {
+	visible, err := r.restriction(ctx, Read)
+	if err != nil {
+		return err
+	}
+	if visible != nil && !visible.Match(original.Payload) {
+		return ErrNotFound
+	}
+	return r.conf.Policy.check(ctx, mode, r.validator, original)
+}
+
+// checkWrite assigns the tenant to the items written with mode and checks them
+// against the policy and the tenant of their references.
+func (r *Resource) checkWrite(ctx context.Context, mode Mode, items ...*Item) error {
+	if err := r.assignTenant(ctx, items...); err != nil {
+		return err
+	}
+	if err := r.conf.Policy.check(ctx, mode, r.validator, items...); err != nil {
+		return err
+	}
+	return r.checkTenantRefs(ctx, items...)
+}
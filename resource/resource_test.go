@@ -1,12 +1,14 @@
 package resource
 
 import (
+	"context"
 	"io/ioutil"
 	"log"
 	"net/url"
 	"testing"
 
 	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -125,6 +127,17 @@ func TestResourceValidatorFallback(t *testing.T) {
 	assert.Nil(t, vf.GetField("baz"))
 }
 
+func TestResourceValidatorFallbackSchema(t *testing.T) {
+	vf := validatorFallback{
+		Validator: schema.Schema{Description: "desc", Fields: schema.Fields{"foo": {}}},
+		fallback:  schema.Schema{Fields: schema.Fields{"bar": {}}},
+	}
+	s := vf.Schema()
+	assert.Equal(t, "desc", s.Description)
+	assert.Len(t, s.Fields, 2)
+	assert.Len(t, vf.Validator.(schema.Schema).Fields, 1)
+}
+
 func TestResourceConnection(t *testing.T) {
 	c := schema.Connection{}
 	v, err := c.Validate("foo")
@@ -141,3 +154,47 @@ func TestResourceUse(t *testing.T) {
 	err := r.Use("non handler")
 	assert.EqualError(t, err, "does not implement any event handler interface")
 }
+
+func TestResourceFindNormalize(t *testing.T) {
+	s := newIntegrityTestStorer(newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "age": 10},
+		map[string]interface{}{"id": "2", "age": 20},
+	))
+	find := s.find
+	var queries []*query.Query
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		queries = append(queries, q)
+		return find(ctx, q)
+	}
+	sch := schema.Schema{Fields: schema.Fields{
+		"id":  {},
+		"age": {Filterable: true, Validator: &schema.Integer{}},
+	}}
+	r := NewIndex().Bind("users", sch, s, DefaultConf)
+	ctx := context.Background()
+	newQuery := func(predicate string) *query.Query {
+		q, err := query.New("", predicate, "", query.Page(1, 10, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = q.Validate(sch); err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+
+	l, err := r.Find(ctx, newQuery(`{$and: [{age: {$gt: 5}}, {age: {$gt: 15}}]}`))
+	assert.NoError(t, err)
+	if assert.Len(t, l.Items, 1) {
+		assert.Equal(t, "2", l.Items[0].ID)
+	}
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, `{age: {$gt: 15}}`, queries[0].Predicate.String())
+	}
+
+	// Predicates that can't match don't reach the storage.
+	l, err = r.FindWithTotal(ctx, newQuery(`{age: {$gt: 15}, age: {$lt: 10}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &ItemList{Total: 0, Offset: 0, Limit: 10, Items: []*Item{}}, l)
+	assert.Len(t, queries, 1)
+}
@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// TextSearcher is an optional interface a Storer can implement to execute the
+// query.Text full-text search expressions natively.
+//
+// When the storer doesn't support full-text search, the expressions involving
+// a query.Text are removed from the predicate passed to Find, and the items
+// returned are scanned page by page and matched in memory, up to the MaxScan
+// query limit of the resource.
+type TextSearcher interface {
+	// SupportsTextSearch returns true if Find supports query.Text expressions
+	// and sorting on query.ScoreField.
+	SupportsTextSearch() bool
+}
+
+// supportsTextSearch returns true if s executes full-text searches natively.
+func supportsTextSearch(s Storer) bool {
+	ts, ok := s.(TextSearcher)
+	return ok && ts.SupportsTextSearch()
+}
+
+// hasText returns true if one of exps involves a query.Text expression.
+func hasText(exps []query.Expression) bool {
+	for _, exp := range exps {
+		switch t := exp.(type) {
+		case *query.Text:
+			return true
+		case *query.And:
+			if hasText(*t) {
+				return true
+			}
+		case *query.Or:
+			if hasText(*t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchText performs q, involving full-text search expressions, on a storer
+// not supporting them. The expressions without full-text search are sent to
+// the storer, and the resulting items are matched against the whole predicate.
+// Sorting on query.ScoreField is only supported as the first sort field. The
+// number of items scanned is capped by the MaxScan query limit.
+func (s storageWrapper) searchText(ctx context.Context, q *query.Query) (*ItemList, error) {
+	sq := &query.Query{}
+	for _, exp := range q.Predicate {
+		if !hasText([]query.Expression{exp}) {
+			sq.Predicate = append(sq.Predicate, exp)
+		}
+	}
+	scoreSort := query.SortField{}
+	for i, sf := range q.Sort {
+		if sf.Name != query.ScoreField {
+			sq.Sort = append(sq.Sort, sf)
+		} else if i == 0 {
+			scoreSort = sf
+		} else {
+			return nil, ErrNotImplemented
+		}
+	}
+	// Without sorting on the score, only the items up to the end of the window
+	// are kept in memory.
+	keep := -1
+	if w := q.Window; w != nil && w.Limit >= 0 && scoreSort.Name == "" {
+		keep = w.Offset + w.Limit
+	}
+	matched := []*Item{}
+	total := 0
+	err := s.scan(ctx, sq, func(item *Item) {
+		if !q.Predicate.Match(item.Payload) {
+			return
+		}
+		if total++; keep < 0 || len(matched) < keep {
+			matched = append(matched, item)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if scoreSort.Name != "" {
+		scores := make(map[*Item]float64, len(matched))
+		for _, item := range matched {
+			scores[item] = q.Predicate.Score(item.Payload)
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			if scoreSort.Reversed {
+				return scores[matched[i]] > scores[matched[j]]
+			}
+			return scores[matched[i]] < scores[matched[j]]
+		})
+	}
+	list := &ItemList{Total: total, Items: matched}
+	if w := q.Window; w != nil {
+		list.Offset = w.Offset
+		list.Limit = w.Limit
+		if w.Offset >= len(matched) {
+			list.Items = []*Item{}
+		} else {
+			list.Items = matched[w.Offset:]
+			if w.Limit >= 0 && w.Limit < len(list.Items) {
+				list.Items = list.Items[:w.Limit]
+			}
+		}
+	}
+	return list, nil
+}
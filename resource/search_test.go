@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchText(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "title": "fox", "published": true},
+		map[string]interface{}{"id": "2", "title": "fox fox", "published": true},
+		map[string]interface{}{"id": "3", "title": "dog", "published": true},
+		map[string]interface{}{"id": "4", "title": "fox fox fox", "published": false},
+	)
+	s := newIntegrityTestStorer(items)
+	find := s.find
+	var queries []*query.Query
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		queries = append(queries, q)
+		return find(ctx, q)
+	}
+	sch := schema.Schema{Fields: schema.Fields{
+		"id":        {Sortable: true},
+		"title":     {Searchable: true},
+		"published": {Filterable: true},
+	}}
+	posts := NewIndex().Bind("posts", sch, s, DefaultConf)
+	ctx := context.Background()
+	newQuery := func(predicate, sort string, window *query.Window) *query.Query {
+		q, err := query.New("", predicate, sort, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = q.Validate(sch); err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+
+	// Full-text expressions are matched in memory.
+	l, err := posts.Find(ctx, newQuery(`{$text: "fox", published: true}`, "-_score", &query.Window{Limit: 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, l.Total)
+	if assert.Len(t, l.Items, 1) {
+		assert.Equal(t, "2", l.Items[0].ID)
+	}
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, `{published: true}`, queries[0].Predicate.String())
+		assert.Equal(t, "id", queries[0].Sort.String())
+	}
+
+	// Without sorting on the score, the window is applied on the matches.
+	l, err = posts.Find(ctx, newQuery(`{$text: "fox"}`, "", &query.Window{Offset: 1, Limit: 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, l.Total)
+	if assert.Len(t, l.Items, 1) {
+		assert.Equal(t, "2", l.Items[0].ID)
+	}
+
+	// Sorting on score after another field is not supported.
+	_, err = posts.Find(ctx, newQuery(`{$text: "fox"}`, "id,-_score", nil))
+	assert.Equal(t, ErrNotImplemented, err)
+
+	// The scan is capped by the MaxScan limit.
+	capped := NewIndex().Bind("posts", sch, s, Conf{AllowedModes: ReadWrite, Limits: QueryLimits{MaxScan: 2}})
+	_, err = capped.Find(ctx, newQuery(`{$text: "fox"}`, "", nil))
+	assert.Equal(t, ErrScanLimit, err)
+}
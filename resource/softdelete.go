@@ -0,0 +1,153 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// DeletedFilter defines which items are returned by lookups on resources with
+// soft delete enabled (see Conf.SoftDeleteField).
+type DeletedFilter int
+
+const (
+	// ExcludeDeleted hides soft deleted items. This is the default.
+	ExcludeDeleted DeletedFilter = iota
+	// IncludeDeleted returns both deleted and non deleted items.
+	IncludeDeleted
+	// OnlyDeleted returns only soft deleted items.
+	OnlyDeleted
+)
+
+type deletedFilterKey struct{}
+
+// NewContextWithDeletedFilter stores the filter f into the context so lookups
+// performed with this context return soft deleted items as defined by f.
+func NewContextWithDeletedFilter(ctx context.Context, f DeletedFilter) context.Context {
+	return context.WithValue(ctx, deletedFilterKey{}, f)
+}
+
+// DeletedFilterFromContext retrieves the filter stored into the context if any,
+// or ExcludeDeleted otherwise.
+func DeletedFilterFromContext(ctx context.Context) DeletedFilter {
+	f, _ := ctx.Value(deletedFilterKey{}).(DeletedFilter)
+	return f
+}
+
+// compileSoftDelete checks the soft delete configuration of r. The soft delete
+// field must be read-only so it can only be set by Delete and Restore.
+func (r *Resource) compileSoftDelete() error {
+	if r.conf.SoftDeleteField == "" {
+		return nil
+	}
+	f := r.validator.GetField(r.conf.SoftDeleteField)
+	if f == nil {
+		return fmt.Errorf("soft delete: unknown field `%s'", r.conf.SoftDeleteField)
+	}
+	if !f.ReadOnly {
+		return fmt.Errorf("soft delete: field `%s' must be read-only", r.conf.SoftDeleteField)
+	}
+	return nil
+}
+
+// deletedFilter returns the predicate filtering soft deleted items as requested
+// by ctx, or nil if soft delete is not enabled.
+func (r *Resource) deletedFilter(ctx context.Context) query.Predicate {
+	if r.conf.SoftDeleteField == "" {
+		return nil
+	}
+	switch DeletedFilterFromContext(ctx) {
+	case IncludeDeleted:
+		return nil
+	case OnlyDeleted:
+		return query.Predicate{&query.Exist{Field: r.conf.SoftDeleteField}}
+	default:
+		return query.Predicate{&query.NotExist{Field: r.conf.SoftDeleteField}}
+	}
+}
+
+// isDeleted returns true if item is soft deleted.
+func (r *Resource) isDeleted(item *Item) bool {
+	if r.conf.SoftDeleteField == "" {
+		return false
+	}
+	_, found := item.Payload[r.conf.SoftDeleteField]
+	return found
+}
+
+// softDelete marks item as deleted in the storage.
+func (r *Resource) softDelete(ctx context.Context, item *Item) error {
+	payload := make(map[string]interface{}, len(item.Payload)+1)
+	for k, v := range item.Payload {
+		payload[k] = v
+	}
+	payload[r.conf.SoftDeleteField] = time.Now()
+	deleted, err := NewItem(payload)
+	if err != nil {
+		return err
+	}
+	if err = r.storage.Update(ctx, deleted, item); err != nil {
+		return err
+	}
+	return r.record(ctx, RevisionDelete, deleted)
+}
+
+// softClear marks the items matching q as deleted in the storage and returns
+// the number of deleted items.
+func (r *Resource) softClear(ctx context.Context, q *query.Query) (int, error) {
+	list, err := r.storage.Find(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	for i, item := range list.Items {
+		if err = r.softDelete(ctx, item); err != nil {
+			return i, err
+		}
+	}
+	return len(list.Items), nil
+}
+
+// Restore restores the soft deleted item and returns its new version. The
+// update hooks are called and the Update rule of the policy is checked, if
+// any. If the item is not deleted, ErrNotFound is returned.
+func (r *Resource) Restore(ctx context.Context, item *Item) (*Item, error) {
+	if r.conf.SoftDeleteField == "" {
+		return nil, ErrNotImplemented
+	}
+	payload := make(map[string]interface{}, len(item.Payload))
+	for k, v := range item.Payload {
+		if k != r.conf.SoftDeleteField {
+			payload[k] = v
+		}
+	}
+	restored, err := NewItem(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.Update(NewContextWithDeletedFilter(ctx, OnlyDeleted), restored, item); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// Purge permanently deletes the item from the storage, whether it is soft
+// deleted or not. The delete hooks are called and the Delete rule of the
+// policy is checked, if any.
+func (r *Resource) Purge(ctx context.Context, item *Item) error {
+	if r.conf.SoftDeleteField == "" {
+		return ErrNotImplemented
+	}
+	return r.delete(NewContextWithDeletedFilter(ctx, IncludeDeleted), item, true)
+}
+
+// PurgeAll permanently deletes the soft deleted items matching the query from
+// the storage and returns the number of purged items. The clear hooks are
+// called and the Clear rule of the policy is checked, if any.
+func (r *Resource) PurgeAll(ctx context.Context, q *query.Query) (int, error) {
+	if r.conf.SoftDeleteField == "" {
+		return 0, ErrNotImplemented
+	}
+	return r.clear(NewContextWithDeletedFilter(ctx, OnlyDeleted), q, true)
+}
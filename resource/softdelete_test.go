@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletedFilter(t *testing.T) {
+	r := newResource("foo", schema.Schema{}, nil, Conf{SoftDeleteField: "deleted"})
+	ctx := context.Background()
+	assert.Equal(t, ExcludeDeleted, DeletedFilterFromContext(ctx))
+	assert.Equal(t, query.Predicate{&query.NotExist{Field: "deleted"}}, r.deletedFilter(ctx))
+	assert.Nil(t, r.deletedFilter(NewContextWithDeletedFilter(ctx, IncludeDeleted)))
+	assert.Equal(t, query.Predicate{&query.Exist{Field: "deleted"}}, r.deletedFilter(NewContextWithDeletedFilter(ctx, OnlyDeleted)))
+
+	r = newResource("foo", schema.Schema{}, nil, Conf{})
+	assert.Nil(t, r.deletedFilter(ctx))
+	_, err := r.Restore(ctx, &Item{})
+	assert.Equal(t, ErrNotImplemented, err)
+	assert.Equal(t, ErrNotImplemented, r.Purge(ctx, &Item{}))
+}
+
+func TestSoftDeleteCompile(t *testing.T) {
+	index := NewIndex()
+	index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, nil, Conf{SoftDeleteField: "deleted"})
+	assert.EqualError(t, index.(Compiler).Compile(), "foo: soft delete: unknown field `deleted'")
+
+	index = NewIndex()
+	index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}, "deleted": {}}}, nil, Conf{SoftDeleteField: "deleted"})
+	assert.EqualError(t, index.(Compiler).Compile(), "foo: soft delete: field `deleted' must be read-only")
+}
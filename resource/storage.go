@@ -111,11 +111,41 @@ type storageHandler interface {
 	Storer
 	MultiGetter
 	Counter
+	Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error)
+	Distinct(ctx context.Context, q *query.Query, field string) ([]DistinctValue, error)
+	Explain(ctx context.Context, q *query.Query) (interface{}, error)
 	Get(ctx context.Context, id interface{}) (item *Item, err error)
 }
 
 type storageWrapper struct {
 	Storer
+	// maxScan is the maximum number of items scanned in memory (see
+	// QueryLimits.MaxScan).
+	maxScan int
+}
+
+// session returns the context to pass to the storer for an operation performed
+// with ctx. If ctx carries a transaction (see Transaction), the operation is
+// performed within the transaction if the storer implements Transactor,
+// otherwise the returned compensate function must be used to register the
+// operation reverting the changes performed by a write.
+func (s storageWrapper) session(ctx context.Context) (sctx context.Context, compensate func(undo func(ctx context.Context) error), err error) {
+	tx, found := ctx.Value(transactionKey{}).(*transaction)
+	if !found {
+		return ctx, nil, nil
+	}
+	if t, ok := s.Storer.(Transactor); ok {
+		if sctx, err = tx.session(ctx, t); err != ErrNotImplemented {
+			return sctx, nil, err
+		}
+	}
+	tx.mu.Lock()
+	done := tx.done
+	tx.mu.Unlock()
+	if done {
+		return nil, nil, ErrTransactionDone
+	}
+	return ctx, tx.compensate, nil
 }
 
 // Get get one item by its id. If item is not found, ErrNotFound error is
@@ -141,6 +171,9 @@ func (s storageWrapper) MultiGet(ctx context.Context, ids []interface{}) (items
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
+	if ctx, _, err = s.session(ctx); err != nil {
+		return nil, err
+	}
 	var tmp []*Item
 	if mg, ok := s.Storer.(MultiGetter); ok {
 		// If native support, use it
@@ -188,6 +221,12 @@ func (s storageWrapper) Find(ctx context.Context, q *query.Query) (list *ItemLis
 	if s.Storer == nil {
 		return nil, ErrNoStorage
 	}
+	if ctx, _, err = s.session(ctx); err != nil {
+		return nil, err
+	}
+	if hasText(q.Predicate) && !supportsTextSearch(s.Storer) {
+		return s.searchText(ctx, q)
+	}
 	if mg, ok := s.Storer.(MultiGetter); ok {
 		// If storage supports MultiGetter interface, detect some common find
 		// pattern that could be converted to multi get.
@@ -227,7 +266,21 @@ func (s storageWrapper) Insert(ctx context.Context, items []*Item) (err error) {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return s.Storer.Insert(ctx, items)
+	ctx, compensate, err := s.session(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.Storer.Insert(ctx, items); err == nil && compensate != nil {
+		compensate(func(ctx context.Context) error {
+			for _, item := range items {
+				if err := s.Storer.Delete(ctx, item); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return err
 }
 
 func (s storageWrapper) Update(ctx context.Context, item *Item, original *Item) (err error) {
@@ -237,7 +290,16 @@ func (s storageWrapper) Update(ctx context.Context, item *Item, original *Item)
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return s.Storer.Update(ctx, item, original)
+	ctx, compensate, err := s.session(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.Storer.Update(ctx, item, original); err == nil && compensate != nil {
+		compensate(func(ctx context.Context) error {
+			return s.Storer.Update(ctx, original, item)
+		})
+	}
+	return err
 }
 
 func (s storageWrapper) Delete(ctx context.Context, item *Item) (err error) {
@@ -247,7 +309,16 @@ func (s storageWrapper) Delete(ctx context.Context, item *Item) (err error) {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return s.Storer.Delete(ctx, item)
+	ctx, compensate, err := s.session(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.Storer.Delete(ctx, item); err == nil && compensate != nil {
+		compensate(func(ctx context.Context) error {
+			return s.Storer.Insert(ctx, []*Item{item})
+		})
+	}
+	return err
 }
 
 func (s storageWrapper) Clear(ctx context.Context, q *query.Query) (deleted int, err error) {
@@ -257,7 +328,24 @@ func (s storageWrapper) Clear(ctx context.Context, q *query.Query) (deleted int,
 	if ctx.Err() != nil {
 		return 0, ctx.Err()
 	}
-	return s.Storer.Clear(ctx, q)
+	ctx, compensate, err := s.session(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if compensate == nil {
+		return s.Storer.Clear(ctx, q)
+	}
+	// Keep the cleared items so they can be inserted back.
+	list, err := s.Storer.Find(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if deleted, err = s.Storer.Clear(ctx, q); err == nil && len(list.Items) > 0 {
+		compensate(func(ctx context.Context) error {
+			return s.Storer.Insert(ctx, list.Items)
+		})
+	}
+	return deleted, err
 }
 
 func (s storageWrapper) Count(ctx context.Context, q *query.Query) (total int, err error) {
@@ -267,6 +355,9 @@ func (s storageWrapper) Count(ctx context.Context, q *query.Query) (total int, e
 	if ctx.Err() != nil {
 		return -1, ctx.Err()
 	}
+	if ctx, _, err = s.session(ctx); err != nil {
+		return -1, err
+	}
 	if c, ok := s.Storer.(Counter); ok {
 		return c.Count(ctx, q)
 	}
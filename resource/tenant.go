@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// TenantFunc returns the tenant of the request described by ctx, or false if
+// the request is not bound to any tenant.
+//
+// When set on a resource along with a TenantField, the operations on the
+// resource are scoped as follow:
+//
+//   - Find, Get, MultiGet and Clear only see the items of the tenant. Items of
+//     other tenants are treated as not found.
+//   - Insert sets the tenant field of the items lacking it and Update sets it
+//     on the new version of the item. Items holding another tenant are
+//     rejected with ErrForbidden.
+//   - Update and Delete return ErrNotFound for items of other tenants.
+//   - Insert and Update reject references to items of other tenants, if the
+//     referenced resource is scoped by tenant, with a ValidationError.
+//
+// As projection and GraphQL references are loaded through the same methods,
+// they are scoped too.
+type TenantFunc func(ctx context.Context) (tenant interface{}, ok bool)
+
+// tenantRef is a reference field pointing to a resource scoped by tenant.
+type tenantRef struct {
+	refField
+	target *Resource
+}
+
+// compileTenancy checks the tenancy configuration of r and lists the reference
+// fields of r pointing to resources scoped by tenant, including those nested in
+// sub-schemas.
+func (r *Resource) compileTenancy(rc schema.ReferenceChecker) error {
+	if r.conf.TenantField != "" {
+		if r.conf.TenantFunc == nil {
+			return fmt.Errorf("tenancy: TenantFunc must be set with TenantField")
+		}
+		if r.validator.GetField(r.conf.TenantField) == nil {
+			return fmt.Errorf("tenancy: unknown tenant field `%s'", r.conf.TenantField)
+		}
+	}
+	c, ok := rc.(refChecker)
+	if !ok {
+		return nil
+	}
+	r.tenantRefs = nil
+	for _, f := range refFields(&r.schema) {
+		if target, found := c.index.GetResource(f.ref.Path, nil); found && target.conf.TenantField != "" {
+			r.tenantRefs = append(r.tenantRefs, tenantRef{f, target})
+		}
+	}
+	return nil
+}
+
+// tenant returns the tenant of the request described by ctx. If r is not scoped
+// by tenant, scoped is false. If the request has no tenant, ErrForbidden is
+// returned.
+func (r *Resource) tenant(ctx context.Context) (tenant interface{}, scoped bool, err error) {
+	if r.conf.TenantField == "" || ctx.Value(unrestrictedKey{}) != nil {
+		return nil, false, nil
+	}
+	tenant, ok := r.conf.TenantFunc(ctx)
+	if !ok {
+		return nil, true, ErrForbidden
+	}
+	return tenant, true, nil
+}
+
+// assignTenant sets the tenant of the request on the items lacking it, and
+// returns ErrForbidden if an item belongs to another tenant.
+func (r *Resource) assignTenant(ctx context.Context, items ...*Item) error {
+	tenant, scoped, err := r.tenant(ctx)
+	if err != nil || !scoped {
+		return err
+	}
+	owned := &query.Equal{Field: r.conf.TenantField, Value: tenant}
+	for _, item := range items {
+		if v, found := item.Payload[r.conf.TenantField]; !found || v == nil {
+			item.Payload[r.conf.TenantField] = tenant
+		} else if !owned.Match(item.Payload) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// checkTenantRefs returns a ValidationError if the items reference items of
+// another tenant.
+func (r *Resource) checkTenantRefs(ctx context.Context, items ...*Item) error {
+	issues := map[string][]interface{}{}
+	for _, tr := range r.tenantRefs {
+		target := tr.target
+		tenant, scoped, err := target.tenant(ctx)
+		if err != nil {
+			return err
+		}
+		if !scoped {
+			continue
+		}
+		var ids []interface{}
+		for _, item := range items {
+			ids = append(ids, tr.values(item.Payload)...)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		// The existence of the references is already checked by the
+		// validator, only their tenant is checked here.
+		refs, err := target.storage.MultiGet(ctx, ids)
+		if err != nil {
+			return err
+		}
+		owned := &query.Equal{Field: target.conf.TenantField, Value: tenant}
+		for i := range ids {
+			if i >= len(refs) || refs[i] == nil || !owned.Match(refs[i].Payload) {
+				issues[tr.name()] = append(issues[tr.name()], ErrNotFound.Error())
+				break
+			}
+		}
+	}
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+	return nil
+}
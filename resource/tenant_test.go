@@ -0,0 +1,195 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+func tenantFromContext(ctx context.Context) (interface{}, bool) {
+	t, ok := ctx.Value(tenantKey{}).(string)
+	return t, ok
+}
+
+// newTenantTestStorer returns a storer holding items and recording the queries
+// it receives in queries.
+func newTenantTestStorer(items []*Item, queries *[]*query.Query) *testMStorer {
+	s := newTestMStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		*queries = append(*queries, q)
+		l := &ItemList{Total: -1, Items: []*Item{}}
+		for _, i := range items {
+			if q.Predicate.Match(i.Payload) {
+				l.Items = append(l.Items, i)
+			}
+		}
+		return l, nil
+	}
+	s.clear = func(ctx context.Context, q *query.Query) (int, error) {
+		*queries = append(*queries, q)
+		return 0, nil
+	}
+	s.multiGet = func(ctx context.Context, ids []interface{}) ([]*Item, error) {
+		res := []*Item{}
+		for _, id := range ids {
+			for _, i := range items {
+				if i.ID == id {
+					res = append(res, i)
+				}
+			}
+		}
+		return res, nil
+	}
+	return s
+}
+
+// newTenantTestIndex returns an index with projects (a owned by acme, b owned
+// by initech) and tasks referencing projects, both scoped by the org field.
+func newTenantTestIndex(t *testing.T, queries *[]*query.Query) Index {
+	projects := []*Item{
+		{ID: "a", ETag: "a", Payload: map[string]interface{}{"id": "a", "org": "acme"}},
+		{ID: "b", ETag: "b", Payload: map[string]interface{}{"id": "b", "org": "initech"}},
+	}
+	conf := Conf{AllowedModes: ReadWrite, TenantField: "org", TenantFunc: tenantFromContext}
+	index := NewIndex()
+	index.Bind("projects", schema.Schema{Fields: schema.Fields{
+		"id":  {},
+		"org": {ReadOnly: true},
+	}}, newTenantTestStorer(projects, queries), conf)
+	index.Bind("tasks", schema.Schema{Fields: schema.Fields{
+		"id":       {},
+		"org":      {ReadOnly: true},
+		"project":  {Validator: &schema.Reference{Path: "projects"}},
+		"projects": {Validator: &schema.Array{Values: schema.Field{Validator: &schema.Reference{Path: "projects"}}}},
+		"meta": {Schema: &schema.Schema{Fields: schema.Fields{
+			"project": {Validator: &schema.Reference{Path: "projects"}},
+		}}},
+		"links": {Validator: &schema.Array{Values: schema.Field{Validator: &schema.Object{Schema: &schema.Schema{Fields: schema.Fields{
+			"project": {Validator: &schema.Reference{Path: "projects"}},
+		}}}}}},
+		"labels": {Validator: &schema.Dict{Values: schema.Field{Validator: &schema.Reference{Path: "projects"}}}},
+	}}, newTenantTestStorer(nil, queries), conf)
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+func TestTenantRead(t *testing.T) {
+	var queries []*query.Query
+	index := newTenantTestIndex(t, &queries)
+	projects, _ := index.GetResource("projects", nil)
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	l, err := projects.Find(acme, &query.Query{Predicate: query.MustParsePredicate(`{id: {$exists: true}}`)})
+	if assert.NoError(t, err) && assert.Len(t, l.Items, 1) {
+		assert.Equal(t, "a", l.Items[0].ID)
+	}
+	assert.Equal(t, `{id: {$exists: true}, org: "acme"}`, queries[0].Predicate.String())
+	_, err = projects.Find(context.Background(), &query.Query{})
+	assert.Equal(t, ErrForbidden, err)
+
+	item, err := projects.Get(acme, "a")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a", item.ID)
+	}
+	_, err = projects.Get(acme, "b")
+	assert.Equal(t, ErrNotFound, err)
+
+	items, err := projects.MultiGet(acme, []interface{}{"a", "b"})
+	if assert.NoError(t, err) && assert.Len(t, items, 2) {
+		assert.Equal(t, "a", items[0].ID)
+		assert.Nil(t, items[1])
+	}
+
+	_, err = projects.Clear(acme, &query.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{org: "acme"}`, queries[len(queries)-1].Predicate.String())
+}
+
+func TestTenantWrite(t *testing.T) {
+	var queries []*query.Query
+	index := newTenantTestIndex(t, &queries)
+	projects, _ := index.GetResource("projects", nil)
+	tasks, _ := index.GetResource("tasks", nil)
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	// The tenant is assigned on insert.
+	task := &Item{ID: "1", Payload: map[string]interface{}{"id": "1", "project": "a"}}
+	assert.NoError(t, tasks.Insert(acme, []*Item{task}))
+	assert.Equal(t, "acme", task.Payload["org"])
+	assert.Equal(t, ErrForbidden, tasks.Insert(acme, []*Item{
+		{ID: "2", Payload: map[string]interface{}{"id": "2", "org": "initech"}},
+	}))
+
+	// References to items of other tenants are rejected.
+	err := tasks.Insert(acme, []*Item{
+		{ID: "2", Payload: map[string]interface{}{"id": "2", "project": "b", "projects": []interface{}{"a", "b"}}},
+	})
+	assert.Equal(t, &ValidationError{Issues: map[string][]interface{}{
+		"project":  {"Not Found"},
+		"projects": {"Not Found"},
+	}}, err)
+	err = tasks.Update(acme, &Item{ID: "1", Payload: map[string]interface{}{"id": "1", "org": "acme", "project": "b"}}, task)
+	assert.IsType(t, &ValidationError{}, err)
+
+	// Nested references are checked too.
+	err = tasks.Insert(acme, []*Item{
+		{ID: "2", Payload: map[string]interface{}{
+			"id":     "2",
+			"meta":   map[string]interface{}{"project": "b"},
+			"links":  []interface{}{map[string]interface{}{"project": "a"}, map[string]interface{}{"project": "b"}},
+			"labels": map[string]interface{}{"x": "a", "y": "b"},
+		}},
+	})
+	assert.Equal(t, &ValidationError{Issues: map[string][]interface{}{
+		"meta.project":  {"Not Found"},
+		"links.project": {"Not Found"},
+		"labels":        {"Not Found"},
+	}}, err)
+	assert.NoError(t, tasks.Insert(acme, []*Item{
+		{ID: "3", Payload: map[string]interface{}{
+			"id":     "3",
+			"meta":   map[string]interface{}{"project": "a"},
+			"links":  []interface{}{map[string]interface{}{"project": "a"}},
+			"labels": map[string]interface{}{"x": "a"},
+		}},
+	}))
+
+	// Items of other tenants are not found.
+	b, _ := projects.Get(unrestricted(context.Background()), "b")
+	assert.Equal(t, ErrNotFound, projects.Update(acme, &Item{ID: "b", Payload: map[string]interface{}{"id": "b"}}, b))
+	assert.Equal(t, ErrNotFound, projects.Delete(acme, b))
+
+	// The tenant can't be changed.
+	a, _ := projects.Get(acme, "a")
+	assert.Equal(t, ErrForbidden, projects.Update(acme, &Item{ID: "a", Payload: map[string]interface{}{"id": "a", "org": "initech"}}, a))
+	assert.NoError(t, projects.Update(acme, &Item{ID: "a", Payload: map[string]interface{}{"id": "a"}}, a))
+	assert.NoError(t, projects.Delete(acme, a))
+}
+
+func TestTenantCompile(t *testing.T) {
+	cases := []struct {
+		conf Conf
+		err  string
+	}{
+		{Conf{TenantField: "org"}, "foo: tenancy: TenantFunc must be set with TenantField"},
+		{Conf{TenantField: "tenant", TenantFunc: tenantFromContext}, "foo: tenancy: unknown tenant field `tenant'"},
+		{Conf{TenantField: "org", TenantFunc: tenantFromContext}, ""},
+	}
+	for _, tc := range cases {
+		index := NewIndex()
+		index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}, "org": {}}}, nil, tc.conf)
+		err := index.(Compiler).Compile()
+		if tc.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tc.err)
+		}
+	}
+}
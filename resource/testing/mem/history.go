@@ -0,0 +1,49 @@
+package mem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/rest-layer/resource"
+)
+
+// HistoryHandler is an example history storer keeping revisions in memory.
+type HistoryHandler struct {
+	sync.RWMutex
+
+	revisions map[historyKey]resource.History
+}
+
+type historyKey struct {
+	path string
+	id   interface{}
+}
+
+// NewHistoryHandler creates an empty memory history handler.
+func NewHistoryHandler() *HistoryHandler {
+	return &HistoryHandler{
+		revisions: map[historyKey]resource.History{},
+	}
+}
+
+// Record stores the revisions.
+func (h *HistoryHandler) Record(ctx context.Context, revisions []*resource.Revision) error {
+	h.Lock()
+	defer h.Unlock()
+	for _, rev := range revisions {
+		k := historyKey{rev.Resource, rev.ItemID}
+		h.revisions[k] = append(h.revisions[k], rev)
+	}
+	return nil
+}
+
+// History returns the revisions of the item with the given id of the resource
+// at path.
+func (h *HistoryHandler) History(ctx context.Context, path string, id interface{}) (resource.History, error) {
+	h.RLock()
+	defer h.RUnlock()
+	revs := h.revisions[historyKey{path, id}]
+	res := make(resource.History, len(revs))
+	copy(res, revs)
+	return res, nil
+}
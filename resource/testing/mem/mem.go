@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
+	"reflect"
 	"sort"
 	"sync"
 	"time"
@@ -21,8 +23,9 @@ type MemoryHandler struct {
 	// all operations.
 	Latency time.Duration
 
-	items map[interface{}][]byte
-	ids   []interface{}
+	items  map[interface{}][]byte
+	ids    []interface{}
+	unique []resource.UniqueConstraint
 }
 
 func init() {
@@ -99,6 +102,9 @@ func (m *MemoryHandler) Insert(ctx context.Context, items []*resource.Item) (err
 				return resource.ErrConflict
 			}
 		}
+		if err := m.checkUnique(items); err != nil {
+			return err
+		}
 		for _, item := range items {
 			if err := m.store(item); err != nil {
 				return err
@@ -126,11 +132,64 @@ func (m *MemoryHandler) Update(ctx context.Context, item *resource.Item, origina
 		if original.ETag != o.ETag {
 			return resource.ErrConflict
 		}
+		if err := m.checkUnique([]*resource.Item{item}); err != nil {
+			return err
+		}
 		return m.store(item)
 	})
 	return err
 }
 
+// EnforceUnique implements the resource.UniqueEnforcer interface. As the
+// constraints are held by the handler, a handler can't be shared by resources
+// with different constraints.
+func (m *MemoryHandler) EnforceUnique(constraints []resource.UniqueConstraint) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.unique != nil && !reflect.DeepEqual(m.unique, constraints) {
+		return errors.New("mem: handler already enforces other constraints")
+	}
+	m.unique = constraints
+	return nil
+}
+
+// SupportsTextSearch implements the resource.TextSearcher interface. Full-text
+// search expressions are matched by tokenizing the searched fields.
+func (m *MemoryHandler) SupportsTextSearch() bool {
+	return true
+}
+
+// checkUnique returns a *resource.UniqueError if the items violate a unique
+// constraint, either with a stored item or with another of the items.
+func (m *MemoryHandler) checkUnique(items []*resource.Item) error {
+	for _, c := range m.unique {
+		for i, item := range items {
+			pred, ok := c.Predicate(item.Payload)
+			if !ok {
+				continue
+			}
+			for _, other := range items[:i] {
+				if pred.Match(other.Payload) {
+					return &resource.UniqueError{Constraint: c}
+				}
+			}
+			for _, id := range m.ids {
+				if id == item.ID {
+					continue
+				}
+				stored, _, err := m.fetch(id)
+				if err != nil {
+					return err
+				}
+				if pred.Match(stored.Payload) {
+					return &resource.UniqueError{Constraint: c}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Delete deletes an item from memory.
 func (m *MemoryHandler) Delete(ctx context.Context, item *resource.Item) (err error) {
 	m.Lock()
@@ -199,7 +258,7 @@ func (m *MemoryHandler) find(ctx context.Context, q *query.Query) (*resource.Ite
 
 	// Apply sort
 	if len(q.Sort) > 0 {
-		s := sortableItems{q.Sort, list.Items}
+		s := sortableItems{q.Sort, list.Items, q.Predicate}
 		sort.Sort(s)
 	}
 	// Apply pagination
@@ -0,0 +1,160 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// Transactor is an optional interface a Storer can implement to take part in
+// transactions started with Transaction.
+//
+// The transaction is carried by the context: Begin returns a context holding
+// the transaction, and the storer must perform the operations it receives with
+// a context derived from it within the transaction. Values are looked up in the
+// context of each operation first, then in the context returned by Begin.
+//
+// Transactor implementations must be comparable (i.e.: pointers) so the same
+// transaction is used by all the resources sharing the storer. Begin may return
+// ErrNotImplemented if the storer can't perform transactions after all (i.e.:
+// when decorating a storer not implementing Transactor), in which case its
+// changes are compensated on rollback like for other storers.
+type Transactor interface {
+	// Begin starts a transaction and returns a context carrying it.
+	Begin(ctx context.Context) (context.Context, error)
+	// Commit commits the transaction carried by ctx.
+	Commit(ctx context.Context) error
+	// Rollback aborts the transaction carried by ctx.
+	Rollback(ctx context.Context) error
+}
+
+type transactionKey struct{}
+
+// transaction tracks the storers used within a call to Transaction.
+type transaction struct {
+	mu sync.Mutex
+	// ctx is the context the transaction has been started with.
+	ctx context.Context
+	// sessions lists the transactions begun on Transactor storers, in order.
+	sessions []session
+	// undo lists the operations compensating the changes performed on the
+	// storers not implementing Transactor, in order.
+	undo []func(ctx context.Context) error
+	done bool
+}
+
+type session struct {
+	t   Transactor
+	ctx context.Context
+}
+
+// sessionContext is the context passed to a Transactor storer for an
+// operation performed within a transaction. It behaves like the context of the
+// operation, with a fallback on the context returned by Transactor.Begin for
+// values.
+type sessionContext struct {
+	context.Context
+	session context.Context
+}
+
+// Value implements context.Context.
+func (c sessionContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.session.Value(key)
+}
+
+// Transaction runs fn as a write session spanning all the resources used with
+// the context it gets. If fn returns an error or panics, the changes performed
+// thru this context are rolled back, otherwise they are committed.
+//
+// Storers implementing Transactor get a transaction begun the first time they
+// are used within the session. For other storers, the changes are applied
+// immediately and reverted on a best-effort basis on rollback: inserted items
+// are deleted, updated items are restored to their original version and
+// deleted items are inserted back. Note that when several Transactor storers
+// are involved, the commits are performed one after the other and are thus not
+// atomic as a whole: if a commit fails, the transactions not committed yet are
+// rolled back.
+//
+// Calls to Transaction with a context already carrying a transaction join the
+// outer transaction.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, found := ctx.Value(transactionKey{}).(*transaction); found {
+		return fn(ctx)
+	}
+	tx := &transaction{ctx: ctx}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollback(0)
+			panic(p)
+		}
+	}()
+	if err = fn(context.WithValue(ctx, transactionKey{}, tx)); err != nil {
+		tx.rollback(0)
+		return err
+	}
+	return tx.commit()
+}
+
+// session returns the context to pass to t for an operation performed with
+// ctx, beginning a transaction on t if not already done.
+func (tx *transaction) session(ctx context.Context, t Transactor) (context.Context, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return nil, ErrTransactionDone
+	}
+	for _, s := range tx.sessions {
+		if s.t == t {
+			return sessionContext{ctx, s.ctx}, nil
+		}
+	}
+	sctx, err := t.Begin(tx.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx.sessions = append(tx.sessions, session{t, sctx})
+	return sessionContext{ctx, sctx}, nil
+}
+
+// compensate registers an operation reverting a change on rollback.
+func (tx *transaction) compensate(undo func(ctx context.Context) error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.undo = append(tx.undo, undo)
+}
+
+// commit commits the sessions in order, rolling back the remaining ones if one
+// fails.
+func (tx *transaction) commit() error {
+	tx.mu.Lock()
+	tx.done = true
+	tx.mu.Unlock()
+	for i, s := range tx.sessions {
+		if err := s.t.Commit(s.ctx); err != nil {
+			tx.rollback(i + 1)
+			return err
+		}
+	}
+	return nil
+}
+
+// rollback rolls back the sessions from the from index and reverts the changes
+// performed on non Transactor storers, in reverse order.
+func (tx *transaction) rollback(from int) {
+	tx.mu.Lock()
+	tx.done = true
+	tx.mu.Unlock()
+	for i := len(tx.sessions) - 1; i >= from; i-- {
+		s := tx.sessions[i]
+		if err := s.t.Rollback(s.ctx); err != nil {
+			logErrorf(tx.ctx, "transaction: rollback failed: %v", err)
+		}
+	}
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		if err := tx.undo[i](tx.ctx); err != nil {
+			logErrorf(tx.ctx, "transaction: cannot revert change: %v", err)
+		}
+	}
+}
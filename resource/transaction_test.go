@@ -0,0 +1,192 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTxKey struct{}
+
+type testTransactor struct {
+	*testMStorer
+	name   string
+	calls  *[]string
+	commit error
+}
+
+func (s *testTransactor) Begin(ctx context.Context) (context.Context, error) {
+	*s.calls = append(*s.calls, "begin "+s.name)
+	return context.WithValue(ctx, testTxKey{}, s.name), nil
+}
+
+func (s *testTransactor) Commit(ctx context.Context) error {
+	*s.calls = append(*s.calls, "commit "+ctx.Value(testTxKey{}).(string))
+	return s.commit
+}
+
+func (s *testTransactor) Rollback(ctx context.Context) error {
+	*s.calls = append(*s.calls, "rollback "+ctx.Value(testTxKey{}).(string))
+	return nil
+}
+
+func newTestTransactor(name string, calls *[]string) *testTransactor {
+	s := &testTransactor{testMStorer: newTestMStorer(), name: name, calls: calls}
+	s.insert = func(ctx context.Context, items []*Item) error {
+		*calls = append(*calls, "insert "+ctx.Value(testTxKey{}).(string))
+		return nil
+	}
+	return s
+}
+
+func TestTransaction(t *testing.T) {
+	var calls []string
+	a := newTestTransactor("a", &calls)
+	b := newTestTransactor("b", &calls)
+	index := NewIndex()
+	foo := index.Bind("foo", schema.Schema{}, a, DefaultConf)
+	bar := index.Bind("bar", schema.Schema{}, a, DefaultConf)
+	baz := index.Bind("baz", schema.Schema{}, b, DefaultConf)
+	ctx := context.Background()
+	insert := func(ctx context.Context, r *Resource) error {
+		return r.Insert(ctx, []*Item{{ID: 1, Payload: map[string]interface{}{"id": 1}}})
+	}
+
+	// Resources sharing a storer share the transaction.
+	err := Transaction(ctx, func(ctx context.Context) error {
+		assert.NoError(t, insert(ctx, foo))
+		assert.NoError(t, insert(ctx, bar))
+		// Nested transactions join the outer one.
+		return Transaction(ctx, func(ctx context.Context) error {
+			return insert(ctx, baz)
+		})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"begin a", "insert a", "insert a", "begin b", "insert b", "commit a", "commit b"}, calls)
+
+	// Errors roll back all the transactions.
+	calls = nil
+	errFailed := errors.New("failed")
+	err = Transaction(ctx, func(ctx context.Context) error {
+		assert.NoError(t, insert(ctx, foo))
+		assert.NoError(t, insert(ctx, baz))
+		return errFailed
+	})
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, []string{"begin a", "insert a", "begin b", "insert b", "rollback b", "rollback a"}, calls)
+
+	// Panics roll back too.
+	calls = nil
+	assert.Panics(t, func() {
+		Transaction(ctx, func(ctx context.Context) error {
+			insert(ctx, foo)
+			panic("failed")
+		})
+	})
+	assert.Equal(t, []string{"begin a", "insert a", "rollback a"}, calls)
+
+	// A failed commit rolls back the transactions not committed yet.
+	calls = nil
+	a.commit = errFailed
+	err = Transaction(ctx, func(ctx context.Context) error {
+		assert.NoError(t, insert(ctx, foo))
+		return insert(ctx, baz)
+	})
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, []string{"begin a", "insert a", "begin b", "insert b", "commit a", "rollback b"}, calls)
+
+	// The context can't be used once the transaction is done.
+	var txCtx context.Context
+	Transaction(ctx, func(ctx context.Context) error {
+		txCtx = ctx
+		return nil
+	})
+	assert.Equal(t, ErrTransactionDone, insert(txCtx, foo))
+}
+
+func TestTransactionCompensate(t *testing.T) {
+	var calls []string
+	s := newTestMStorer()
+	s.insert = func(ctx context.Context, items []*Item) error {
+		calls = append(calls, "insert")
+		return nil
+	}
+	s.update = func(ctx context.Context, item *Item, original *Item) error {
+		calls = append(calls, "update "+item.ETag+" "+original.ETag)
+		return nil
+	}
+	s.delete = func(ctx context.Context, item *Item) error {
+		calls = append(calls, "delete "+item.ETag)
+		return nil
+	}
+	foo := NewIndex().Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, s, DefaultConf)
+	ctx := context.Background()
+	item, _ := NewItem(map[string]interface{}{"id": 1})
+	updated, _ := NewItem(map[string]interface{}{"id": 1, "foo": "bar"})
+
+	// Changes are kept on commit.
+	assert.NoError(t, Transaction(ctx, func(ctx context.Context) error {
+		return foo.Insert(ctx, []*Item{item})
+	}))
+	assert.Equal(t, []string{"insert"}, calls)
+
+	// Changes are reverted in reverse order on rollback.
+	calls = nil
+	errFailed := errors.New("failed")
+	err := Transaction(ctx, func(ctx context.Context) error {
+		assert.NoError(t, foo.storage.Update(ctx, updated, item))
+		assert.NoError(t, foo.storage.Delete(ctx, updated))
+		return errFailed
+	})
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, []string{
+		"update " + updated.ETag + " " + item.ETag,
+		"delete " + updated.ETag,
+		"insert",
+		"update " + item.ETag + " " + updated.ETag,
+	}, calls)
+}
+
+// notTransactor is a Transactor not supporting transactions after all.
+type notTransactor struct {
+	*testMStorer
+}
+
+func (s notTransactor) Begin(ctx context.Context) (context.Context, error) {
+	return nil, ErrNotImplemented
+}
+
+func (s notTransactor) Commit(ctx context.Context) error {
+	return ErrNotImplemented
+}
+
+func (s notTransactor) Rollback(ctx context.Context) error {
+	return ErrNotImplemented
+}
+
+func TestTransactionNotImplemented(t *testing.T) {
+	var calls []string
+	s := newTestMStorer()
+	s.insert = func(ctx context.Context, items []*Item) error {
+		calls = append(calls, "insert")
+		return nil
+	}
+	s.delete = func(ctx context.Context, item *Item) error {
+		calls = append(calls, "delete")
+		return nil
+	}
+	foo := NewIndex().Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, notTransactor{s}, DefaultConf)
+	item, _ := NewItem(map[string]interface{}{"id": 1})
+
+	// Changes are compensated on rollback.
+	errFailed := errors.New("failed")
+	err := Transaction(context.Background(), func(ctx context.Context) error {
+		assert.NoError(t, foo.Insert(ctx, []*Item{item}))
+		return errFailed
+	})
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, []string{"insert", "delete"}, calls)
+}
@@ -0,0 +1,203 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// UniqueConstraint is a list of fields whose combined values must be unique
+// among the items of a resource. Items lacking one of the fields or holding a
+// null value for it are not subject to the constraint.
+type UniqueConstraint []string
+
+// Predicate returns the predicate matching the items holding the same values
+// as payload for the fields of c. If the constraint doesn't apply to payload,
+// false is returned.
+func (c UniqueConstraint) Predicate(payload map[string]interface{}) (query.Predicate, bool) {
+	pred := make(query.Predicate, 0, len(c))
+	for _, field := range c {
+		v := lookupField(payload, field)
+		if v == nil {
+			return nil, false
+		}
+		pred = append(pred, &query.Equal{Field: field, Value: v})
+	}
+	return pred, true
+}
+
+// has returns true if field is part of c.
+func (c UniqueConstraint) has(field string) bool {
+	for _, f := range c {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupField returns the value of the field at path (i.e.: a.b) in payload, or
+// nil if not found.
+func lookupField(payload map[string]interface{}, path string) interface{} {
+	var v interface{} = payload
+	for _, name := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[name]
+	}
+	return v
+}
+
+// UniqueEnforcer is an optional interface a Storer can implement to enforce the
+// unique constraints of the resource natively (i.e.: using unique indexes).
+//
+// When the storer doesn't implement this interface or EnforceUnique returns
+// ErrNotImplemented, the resource looks up the items holding the same values
+// before inserting or updating items. As this check is not atomic, concurrent
+// writes may still introduce duplicates. The soft deleted items are ignored by
+// this check, while storers enforcing the constraints natively may count them
+// as any other item.
+//
+// Constraints apply per tenant on resources scoped by tenant (see
+// Conf.TenantField): the tenant field is then appended to each constraint
+// passed to EnforceUnique, so items of different tenants may hold the same
+// values.
+type UniqueEnforcer interface {
+	// EnforceUnique is called with the unique constraints of the resource when
+	// the resource graph is compiled. Insert and Update must then return a
+	// *UniqueError with the violated constraint if an item violates one of
+	// them, leaving the storage untouched.
+	EnforceUnique(constraints []UniqueConstraint) error
+}
+
+// compileUnique lists the unique constraints of r, declared on the schema
+// fields and in the configuration, and passes them to the storer if it
+// enforces them natively.
+func (r *Resource) compileUnique() error {
+	r.unique = nil
+	names := make([]string, 0, len(r.schema.Fields))
+	for name, def := range r.schema.Fields {
+		if def.Unique {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.unique = append(r.unique, UniqueConstraint{name})
+	}
+	for _, c := range r.conf.Unique {
+		if len(c) == 0 {
+			return fmt.Errorf("unique: empty constraint")
+		}
+		for _, field := range c {
+			if r.validator.GetField(field) == nil {
+				return fmt.Errorf("unique: unknown field `%s'", field)
+			}
+		}
+		r.unique = append(r.unique, c)
+	}
+	r.uniqueNative = false
+	if w, ok := r.storage.(storageWrapper); ok && len(r.unique) > 0 {
+		if e, ok := w.Storer.(UniqueEnforcer); ok {
+			switch err := e.EnforceUnique(r.scopedUnique()); err {
+			case nil:
+				r.uniqueNative = true
+			case ErrNotImplemented:
+			default:
+				return fmt.Errorf("unique: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// scopedUnique returns the unique constraints of r as enforced by the storer,
+// including the tenant field if r is scoped by tenant.
+func (r *Resource) scopedUnique() []UniqueConstraint {
+	if r.conf.TenantField == "" {
+		return r.unique
+	}
+	scoped := make([]UniqueConstraint, len(r.unique))
+	for i, c := range r.unique {
+		scoped[i] = c
+		if !c.has(r.conf.TenantField) {
+			scoped[i] = append(c[:len(c):len(c)], r.conf.TenantField)
+		}
+	}
+	return scoped
+}
+
+// uniqueError maps the constraint of a *UniqueError returned by the storer back
+// to the constraint declared on r, so the tenant field is not reported.
+func (r *Resource) uniqueError(err error) error {
+	e, ok := err.(*UniqueError)
+	if !ok || r.conf.TenantField == "" {
+		return err
+	}
+	for i, c := range r.scopedUnique() {
+		if reflect.DeepEqual(c, e.Constraint) {
+			return &UniqueError{Constraint: r.unique[i]}
+		}
+	}
+	return err
+}
+
+// checkUnique returns a *UniqueError if one of the items violates a unique
+// constraint of r, either with a stored item or with another of the items. When
+// original is provided, constraints on unchanged values are not checked.
+//
+// Soft deleted items don't take part in the constraints, so their values can be
+// reused. Restoring an item thus checks all its values again. On resources
+// scoped by tenant, only the items of the same tenant are considered.
+func (r *Resource) checkUnique(ctx context.Context, original *Item, items ...*Item) error {
+	if r.uniqueNative {
+		return nil
+	}
+	if original != nil && r.isDeleted(original) {
+		original = nil
+	}
+	for _, c := range r.unique {
+		for i, item := range items {
+			pred, ok := c.Predicate(item.Payload)
+			if !ok || r.isDeleted(item) {
+				continue
+			}
+			if r.conf.TenantField != "" {
+				if tenant := item.Payload[r.conf.TenantField]; tenant != nil {
+					pred = append(pred, &query.Equal{Field: r.conf.TenantField, Value: tenant})
+				} else {
+					pred = append(pred, &query.NotExist{Field: r.conf.TenantField})
+				}
+			}
+			if original != nil && pred.Match(original.Payload) {
+				continue
+			}
+			for _, other := range items[:i] {
+				if !r.isDeleted(other) && pred.Match(other.Payload) {
+					return &UniqueError{Constraint: c}
+				}
+			}
+			q := &query.Query{
+				Predicate: append(pred, &query.NotEqual{Field: "id", Value: item.ID}),
+				Window:    &query.Window{Limit: 1},
+			}
+			if r.conf.SoftDeleteField != "" {
+				q.Predicate = append(q.Predicate, &query.NotExist{Field: r.conf.SoftDeleteField})
+			}
+			l, err := r.storage.Find(ctx, q)
+			if err != nil {
+				return err
+			}
+			if len(l.Items) > 0 {
+				return &UniqueError{Constraint: c}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUniqueEnforcer struct {
+	*testMStorer
+	constraints []UniqueConstraint
+}
+
+func (s *testUniqueEnforcer) EnforceUnique(constraints []UniqueConstraint) error {
+	s.constraints = constraints
+	return nil
+}
+
+var testUniqueSchema = schema.Schema{Fields: schema.Fields{
+	"id":    {},
+	"email": {Unique: true},
+	"org":   {},
+	"name":  {},
+}}
+
+func TestUnique(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "email": "john@example.com", "org": "acme", "name": "john"},
+	)
+	index := NewIndex()
+	r := index.Bind("users", testUniqueSchema, newIntegrityTestStorer(items), Conf{
+		AllowedModes: ReadWrite,
+		Unique:       []UniqueConstraint{{"org", "name"}},
+	})
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	email := &UniqueError{Constraint: UniqueConstraint{"email"}}
+	orgName := &UniqueError{Constraint: UniqueConstraint{"org", "name"}}
+	newItem := func(payload map[string]interface{}) *Item {
+		i, _ := NewItem(payload)
+		return i
+	}
+
+	assert.Equal(t, email, r.Insert(ctx, []*Item{
+		newItem(map[string]interface{}{"id": "2", "email": "john@example.com"}),
+	}))
+	assert.Equal(t, orgName, r.Insert(ctx, []*Item{
+		newItem(map[string]interface{}{"id": "2", "org": "acme", "name": "john"}),
+	}))
+	// Duplicates within the inserted items are detected.
+	assert.Equal(t, email, r.Insert(ctx, []*Item{
+		newItem(map[string]interface{}{"id": "2", "email": "paul@example.com"}),
+		newItem(map[string]interface{}{"id": "3", "email": "paul@example.com"}),
+	}))
+	// Constraints don't apply to items lacking one of the fields.
+	assert.NoError(t, r.Insert(ctx, []*Item{
+		newItem(map[string]interface{}{"id": "2", "name": "john"}),
+		newItem(map[string]interface{}{"id": "3", "name": "john"}),
+	}))
+	assert.Len(t, items, 3)
+
+	// Unchanged values are not checked on update.
+	assert.NoError(t, r.Update(ctx, newItem(map[string]interface{}{"id": "1", "email": "john@example.com", "org": "acme", "name": "john"}), items["1"]))
+	assert.Equal(t, email, r.Update(ctx, newItem(map[string]interface{}{"id": "2", "email": "john@example.com"}), items["2"]))
+	assert.NoError(t, r.Update(ctx, newItem(map[string]interface{}{"id": "2", "email": "paul@example.com"}), items["2"]))
+}
+
+func TestUniqueSoftDelete(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "email": "john@example.com", "deleted": true},
+	)
+	index := NewIndex()
+	r := index.Bind("users", schema.Schema{Fields: schema.Fields{
+		"id":      {},
+		"email":   {Unique: true},
+		"deleted": {ReadOnly: true},
+	}}, newIntegrityTestStorer(items), Conf{AllowedModes: ReadWrite, SoftDeleteField: "deleted"})
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The values of soft deleted items can be reused.
+	item, _ := NewItem(map[string]interface{}{"id": "2", "email": "john@example.com"})
+	assert.NoError(t, r.Insert(ctx, []*Item{item}))
+
+	// Restoring an item checks its values again.
+	_, err := r.Restore(ctx, items["1"])
+	assert.Equal(t, &UniqueError{Constraint: UniqueConstraint{"email"}}, err)
+	assert.NoError(t, r.Delete(ctx, items["2"]))
+	_, err = r.Restore(ctx, items["1"])
+	assert.NoError(t, err)
+}
+
+func TestUniqueNative(t *testing.T) {
+	s := &testUniqueEnforcer{testMStorer: newTestMStorer()}
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		t.Error("unexpected lookup")
+		return &ItemList{}, nil
+	}
+	index := NewIndex()
+	r := index.Bind("users", testUniqueSchema, s, Conf{
+		AllowedModes: ReadWrite,
+		Unique:       []UniqueConstraint{{"org", "name"}},
+	})
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []UniqueConstraint{{"email"}, {"org", "name"}}, s.constraints)
+	item, _ := NewItem(map[string]interface{}{"id": "1", "email": "john@example.com"})
+	assert.NoError(t, r.Insert(context.Background(), []*Item{item}))
+}
+
+func TestUniqueTenant(t *testing.T) {
+	items := newIntegrityTestItems(
+		map[string]interface{}{"id": "1", "email": "john@example.com", "org": "acme"},
+	)
+	index := NewIndex()
+	r := index.Bind("users", testUniqueSchema, newIntegrityTestStorer(items), Conf{
+		AllowedModes: ReadWrite,
+		TenantField:  "org",
+		TenantFunc:   tenantFromContext,
+	})
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	globex := context.WithValue(context.Background(), tenantKey{}, "globex")
+
+	// Values are only unique within a tenant.
+	item, _ := NewItem(map[string]interface{}{"id": "2", "email": "john@example.com"})
+	assert.NoError(t, r.Insert(globex, []*Item{item}))
+	item, _ = NewItem(map[string]interface{}{"id": "3", "email": "john@example.com"})
+	assert.Equal(t, &UniqueError{Constraint: UniqueConstraint{"email"}}, r.Insert(acme, []*Item{item}))
+}
+
+func TestUniqueTenantNative(t *testing.T) {
+	s := &testUniqueEnforcer{testMStorer: newTestMStorer()}
+	s.insert = func(ctx context.Context, items []*Item) error {
+		return &UniqueError{Constraint: UniqueConstraint{"email", "org"}}
+	}
+	index := NewIndex()
+	r := index.Bind("users", testUniqueSchema, s, Conf{
+		AllowedModes: ReadWrite,
+		Unique:       []UniqueConstraint{{"org", "name"}},
+		TenantField:  "org",
+		TenantFunc:   tenantFromContext,
+	})
+	if err := index.(Compiler).Compile(); err != nil {
+		t.Fatal(err)
+	}
+	// The tenant field is added to the constraints passed to the storer, and
+	// removed from the constraints it reports.
+	assert.Equal(t, []UniqueConstraint{{"email", "org"}, {"org", "name"}}, s.constraints)
+	item, _ := NewItem(map[string]interface{}{"id": "1", "email": "john@example.com"})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	assert.Equal(t, &UniqueError{Constraint: UniqueConstraint{"email"}}, r.Insert(ctx, []*Item{item}))
+}
+
+func TestUniqueCompile(t *testing.T) {
+	cases := []struct {
+		unique []UniqueConstraint
+		err    string
+	}{
+		{[]UniqueConstraint{{"org", "foo"}}, "users: unique: unknown field `foo'"},
+		{[]UniqueConstraint{{}}, "users: unique: empty constraint"},
+		{[]UniqueConstraint{{"org", "name"}}, ""},
+	}
+	for _, tc := range cases {
+		index := NewIndex()
+		index.Bind("users", testUniqueSchema, nil, Conf{Unique: tc.unique})
+		err := index.(Compiler).Compile()
+		if tc.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package client is a minimal HTTP client speaking the protocol implemented by
+// the rest package: query-string parameters, ETag based conditional requests
+// and error format. It is used as the runtime of the typed clients produced by
+// the clientgen package, but can also be used on its own.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client sends requests to a REST Layer API.
+type Client struct {
+	// BaseURL is the URL on which the API handler is mounted, without trailing
+	// slash (i.e.: http://example.com/api).
+	BaseURL string
+	// HTTPClient is the client used to perform requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Header is added to every request sent by the client (i.e.: to set an
+	// Authorization header).
+	Header http.Header
+}
+
+// New creates a new client for the API mounted at baseURL.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Header:  http.Header{},
+	}
+}
+
+// Query defines the parameters of a list request. The zero value requests the
+// first page of items, using the resource defaults.
+type Query struct {
+	// Filter is a predicate as accepted by the filter parameter.
+	Filter string
+	// Sort is a comma separated list of fields to sort on, as accepted by the
+	// sort parameter.
+	Sort string
+	// Fields is a projection, as accepted by the fields parameter.
+	Fields string
+	// Limit is the maximum number of items to return. If 0, the resource
+	// default limit is used.
+	Limit int
+	// Skip is the number of items to skip.
+	Skip int
+	// Page is the page number, starting at 1.
+	Page int
+	// Total requests the total number of matching items to be computed.
+	Total bool
+}
+
+// Values returns the query-string parameters representing q.
+func (q Query) Values() url.Values {
+	v := url.Values{}
+	if q.Filter != "" {
+		v.Set("filter", q.Filter)
+	}
+	if q.Sort != "" {
+		v.Set("sort", q.Sort)
+	}
+	if q.Fields != "" {
+		v.Set("fields", q.Fields)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Skip > 0 {
+		v.Set("skip", strconv.Itoa(q.Skip))
+	}
+	if q.Page > 0 {
+		v.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.Total {
+		v.Set("total", "1")
+	}
+	return v
+}
+
+// ListInfo holds the metadata of a list response.
+type ListInfo struct {
+	// Total is the total number of items matching the query, or -1 if it was
+	// not computed.
+	Total int
+	// Offset is the index of the first returned item.
+	Offset int
+	// ETag is the ETag of the list.
+	ETag string
+}
+
+// Error is returned when the API responds with an error status. It mirrors
+// the rest.Error type.
+type Error struct {
+	// Code is the HTTP status of the response.
+	Code int `json:"code"`
+	// Message is the error message.
+	Message string `json:"message"`
+	// Issues holds per fields errors if any.
+	Issues map[string][]interface{} `json:"issues,omitempty"`
+}
+
+// Error implements the built-in error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Get fetches the item at path and decodes it into out. The returned etag is
+// unquoted.
+func (c *Client) Get(ctx context.Context, path string, params url.Values, out interface{}) (etag string, err error) {
+	res, err := c.Do(ctx, http.MethodGet, path, params, nil, nil, out)
+	if err != nil {
+		return "", err
+	}
+	return ParseETag(res.Header.Get("Etag")), nil
+}
+
+// Find lists the items of the collection at path matching q, and decodes them
+// into out, which must be a pointer to a slice. The ETag of each item is
+// available in its _etag field.
+func (c *Client) Find(ctx context.Context, path string, q Query, out interface{}) (ListInfo, error) {
+	info := ListInfo{Total: -1}
+	res, err := c.Do(ctx, http.MethodGet, path, q.Values(), nil, nil, out)
+	if err != nil {
+		return info, err
+	}
+	if t := res.Header.Get("X-Total"); t != "" {
+		if info.Total, err = strconv.Atoi(t); err != nil {
+			return info, fmt.Errorf("invalid X-Total header: %v", err)
+		}
+	}
+	if o := res.Header.Get("X-Offset"); o != "" {
+		if info.Offset, err = strconv.Atoi(o); err != nil {
+			return info, fmt.Errorf("invalid X-Offset header: %v", err)
+		}
+	}
+	info.ETag = ParseETag(res.Header.Get("Etag"))
+	return info, nil
+}
+
+// Insert creates a new item in the collection at path by posting in, and
+// decodes the created item into out.
+func (c *Client) Insert(ctx context.Context, path string, in, out interface{}) (etag string, err error) {
+	res, err := c.Do(ctx, http.MethodPost, path, nil, nil, in, out)
+	if err != nil {
+		return "", err
+	}
+	return ParseETag(res.Header.Get("Etag")), nil
+}
+
+// Replace replaces (or creates) the item at path with in using PUT, and
+// decodes the stored item into out. If etag is not empty, the request is
+// conditioned to the stored item having this ETag.
+func (c *Client) Replace(ctx context.Context, path, etag string, in, out interface{}) (string, error) {
+	res, err := c.Do(ctx, http.MethodPut, path, nil, ifMatch(etag), in, out)
+	if err != nil {
+		return "", err
+	}
+	return ParseETag(res.Header.Get("Etag")), nil
+}
+
+// Update applies the changes in to the item at path using PATCH, and decodes
+// the stored item into out. If etag is not empty, the request is conditioned
+// to the stored item having this ETag.
+func (c *Client) Update(ctx context.Context, path, etag string, in, out interface{}) (string, error) {
+	res, err := c.Do(ctx, http.MethodPatch, path, nil, ifMatch(etag), in, out)
+	if err != nil {
+		return "", err
+	}
+	return ParseETag(res.Header.Get("Etag")), nil
+}
+
+// Delete deletes the item at path. If etag is not empty, the request is
+// conditioned to the stored item having this ETag.
+func (c *Client) Delete(ctx context.Context, path, etag string) error {
+	_, err := c.Do(ctx, http.MethodDelete, path, nil, ifMatch(etag), nil, nil)
+	return err
+}
+
+// Clear deletes all the items of the collection at path matching q, and returns
+// the number of deleted items, or -1 if unknown.
+func (c *Client) Clear(ctx context.Context, path string, q Query) (int, error) {
+	params := url.Values{}
+	if q.Filter != "" {
+		params.Set("filter", q.Filter)
+	}
+	res, err := c.Do(ctx, http.MethodDelete, path, params, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	total, err := strconv.Atoi(res.Header.Get("X-Total"))
+	if err != nil {
+		return -1, nil
+	}
+	return total, nil
+}
+
+// Do sends a request with the given method on path, relative to the client's
+// BaseURL. If in is not nil, it is sent JSON encoded as the request body. If
+// out is not nil and the response has a body, it is JSON decoded into out. A
+// response with a status code of 400 or above is returned as an *Error.
+func (c *Client) Do(ctx context.Context, method, path string, params url.Values, header http.Header, in, out interface{}) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if in != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		e := &Error{}
+		if err := json.NewDecoder(res.Body).Decode(e); err != nil || e.Message == "" {
+			e.Message = http.StatusText(res.StatusCode)
+		}
+		e.Code = res.StatusCode
+		return res, e
+	}
+	if out != nil && res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil && err != io.EOF {
+			return res, fmt.Errorf("invalid response body: %v", err)
+		}
+	}
+	return res, nil
+}
+
+// ItemPath returns the path of the item identified by id in the collection at
+// path.
+func ItemPath(path string, id interface{}) string {
+	return path + "/" + url.PathEscape(fmt.Sprint(id))
+}
+
+// ParseETag returns the ETag value of an Etag header, without the weak
+// indicator and the quotes.
+func ParseETag(h string) string {
+	h = strings.TrimPrefix(h, "W/")
+	if l := len(h); l >= 2 && h[0] == '"' && h[l-1] == '"' {
+		h = h[1 : l-1]
+	}
+	return h
+}
+
+// Payload converts v to a generic JSON object, removing the _etag field and the
+// omit fields from it. It is used to strip fields that the API would reject
+// from write requests.
+func Payload(v interface{}, omit ...string) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "_etag")
+	for _, f := range omit {
+		delete(m, f)
+	}
+	return m, nil
+}
+
+func ifMatch(etag string) http.Header {
+	if etag == "" {
+		return nil
+	}
+	return http.Header{"If-Match": []string{`W/"` + etag + `"`}}
+}
@@ -0,0 +1,97 @@
+package client_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/rest"
+	"github.com/rs/rest-layer/rest/client"
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	index := resource.NewIndex()
+	index.Bind("users", schema.Schema{
+		Fields: schema.Fields{
+			"id":   schema.IDField,
+			"name": {Required: true, Filterable: true, Validator: &schema.String{}},
+		},
+	}, mem.NewHandler(), resource.DefaultConf)
+	h, err := rest.NewHandler(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(h)
+}
+
+func TestClient(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+	c := client.New(s.URL + "/")
+	ctx := context.Background()
+
+	user := map[string]interface{}{}
+	etag, err := c.Insert(ctx, "/users", map[string]interface{}{"name": "john"}, &user)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, etag)
+	id := user["id"]
+
+	got := map[string]interface{}{}
+	getEtag, err := c.Get(ctx, client.ItemPath("/users", id), nil, &got)
+	assert.NoError(t, err)
+	assert.Equal(t, etag, getEtag)
+	assert.Equal(t, "john", got["name"])
+
+	var list []map[string]interface{}
+	info, err := c.Find(ctx, "/users", client.Query{Filter: `{name: "john"}`, Total: true}, &list)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, info.Total)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, etag, list[0]["_etag"])
+	}
+
+	newEtag, err := c.Update(ctx, client.ItemPath("/users", id), etag, map[string]interface{}{"name": "paul"}, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, etag, newEtag)
+
+	// The previous etag is now stale.
+	_, err = c.Update(ctx, client.ItemPath("/users", id), etag, map[string]interface{}{"name": "jack"}, nil)
+	assert.Equal(t, &client.Error{Code: 412, Message: "Precondition Failed"}, err)
+
+	_, err = c.Insert(ctx, "/users", map[string]interface{}{}, nil)
+	if assert.IsType(t, &client.Error{}, err) {
+		e := err.(*client.Error)
+		assert.Equal(t, 422, e.Code)
+		assert.Equal(t, map[string][]interface{}{"name": {"required"}}, e.Issues)
+	}
+
+	assert.NoError(t, c.Delete(ctx, client.ItemPath("/users", id), newEtag))
+	_, err = c.Get(ctx, client.ItemPath("/users", id), nil, nil)
+	assert.Equal(t, &client.Error{Code: 404, Message: "Not Found"}, err)
+}
+
+func TestQueryValues(t *testing.T) {
+	q := client.Query{Filter: "{}", Sort: "-name", Fields: "id", Limit: 10, Skip: 1, Page: 2, Total: true}
+	assert.Equal(t, "fields=id&filter=%7B%7D&limit=10&page=2&skip=1&sort=-name&total=1", q.Values().Encode())
+	assert.Equal(t, "", client.Query{}.Values().Encode())
+}
+
+func TestParseETag(t *testing.T) {
+	assert.Equal(t, "abc", client.ParseETag(`W/"abc"`))
+	assert.Equal(t, "abc", client.ParseETag(`"abc"`))
+	assert.Equal(t, "abc", client.ParseETag(`abc`))
+}
+
+func TestPayload(t *testing.T) {
+	p, err := client.Payload(struct {
+		ETag string `json:"_etag"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}{"etag", "id", "name"}, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "name"}, p)
+}
@@ -0,0 +1,381 @@
+// Package clientgen generates Go source code for a typed HTTP client of a REST
+// Layer API. For each resource of a resource.Index, a struct describing its
+// items and a client exposing the operations allowed by the resource
+// configuration are generated. The generated code relies on the
+// github.com/rs/rest-layer/rest/client package at runtime.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema"
+)
+
+// Config controls the code generation.
+type Config struct {
+	// Package is the name of the generated package. If empty, "client" is
+	// used.
+	Package string
+	// TypeName returns the Go type name used for the items of the resource
+	// stored at path (i.e.: users.posts). If nil, DefaultTypeName is used.
+	TypeName func(path string) string
+}
+
+// DefaultTypeName builds a type name from a resource path by singularizing and
+// camel-casing each of its components (i.e.: users.posts gives UserPost).
+func DefaultTypeName(path string) string {
+	var name string
+	for _, comp := range strings.Split(path, ".") {
+		name += GoName(singular(comp))
+	}
+	return name
+}
+
+// Generate writes the Go source of a typed client for all the resources of
+// index to w.
+func Generate(w io.Writer, index resource.Index, conf Config) error {
+	if conf.Package == "" {
+		conf.Package = "client"
+	}
+	if conf.TypeName == nil {
+		conf.TypeName = DefaultTypeName
+	}
+	g := &generator{
+		conf:  conf,
+		index: index,
+		types: map[string]string{},
+		buf:   &bytes.Buffer{},
+	}
+	if err := g.generate(); err != nil {
+		return err
+	}
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("invalid generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	conf  Config
+	index resource.Index
+	// types maps generated type names to the path of the schema they
+	// describe, in order to detect collisions.
+	types map[string]string
+	// usesTime is set when a time.Time field is generated.
+	usesTime bool
+	// usesContext is set when a client method is generated.
+	usesContext bool
+	buf         *bytes.Buffer
+	body        bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+func (g *generator) generate() error {
+	g.printf("// Client is a typed client for the API.\n")
+	g.printf("type Client struct {\n\t*client.Client\n}\n\n")
+	g.printf("// New creates a new client for the API mounted at baseURL.\n")
+	g.printf("func New(baseURL string) *Client {\n\treturn &Client{client.New(baseURL)}\n}\n\n")
+	for _, r := range g.index.GetResources() {
+		g.printf("// %s gives access to the %s resource.\n", GoName(r.Name()), r.Path())
+		g.printf("func (c *Client) %s() *%sClient {\n", GoName(r.Name()), g.conf.TypeName(r.Path()))
+		g.printf("\treturn &%sClient{c: c.Client, path: %q}\n}\n\n", g.conf.TypeName(r.Path()), "/"+r.Name())
+	}
+	for _, r := range g.index.GetResources() {
+		if err := g.resource(r); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(g.buf, "// Code generated by rest-layer-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(g.buf, "package %s\n\n", g.conf.Package)
+	fmt.Fprintf(g.buf, "import (\n")
+	if g.usesContext {
+		fmt.Fprintf(g.buf, "\t\"context\"\n")
+	}
+	if g.usesTime {
+		fmt.Fprintf(g.buf, "\t\"time\"\n")
+	}
+	fmt.Fprintf(g.buf, "\n\t\"github.com/rs/rest-layer/rest/client\"\n)\n\n")
+	g.buf.Write(g.body.Bytes())
+	return nil
+}
+
+// resource generates the item type and the client of r and its sub-resources.
+func (g *generator) resource(r *resource.Resource) error {
+	typeName := g.conf.TypeName(r.Path())
+	s := r.Schema()
+	if err := g.structType(typeName, r.Path(), &s, true); err != nil {
+		return err
+	}
+	idType := "string"
+	if f, found := s.Fields["id"]; found {
+		t, err := g.fieldType(typeName+"ID", r.Path()+".id", f)
+		if err != nil {
+			return err
+		}
+		idType = t
+	}
+	// Read-only fields and the field binding a sub-resource to its parent
+	// (set from the URL) are never sent on writes.
+	omitted := []string{}
+	for _, name := range sortedFields(s.Fields) {
+		if s.Fields[name].ReadOnly || name == r.ParentField() {
+			omitted = append(omitted, fmt.Sprintf("%q", name))
+		}
+	}
+	conf := r.Conf()
+	for _, m := range []resource.Mode{resource.Read, resource.List, resource.Create, resource.Update, resource.Delete} {
+		g.usesContext = g.usesContext || conf.IsModeAllowed(m)
+	}
+	client := typeName + "Client"
+	g.printf("// %s gives access to the %s resource.\n", client, r.Path())
+	g.printf("type %s struct {\n\tc    *client.Client\n\tpath string\n}\n\n", client)
+	if conf.IsModeAllowed(resource.Read) {
+		g.printf("// Get fetches the %s item identified by id.\n", r.Path())
+		g.printf("func (c *%s) Get(ctx context.Context, id %s) (*%s, error) {\n", client, idType, typeName)
+		g.printf("\titem := &%s{}\n", typeName)
+		g.printf("\tetag, err := c.c.Get(ctx, client.ItemPath(c.path, id), nil, item)\n")
+		g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		g.printf("\titem.ETag = etag\n\treturn item, nil\n}\n\n")
+	}
+	if conf.IsModeAllowed(resource.List) {
+		g.printf("// Find lists the %s items matching q.\n", r.Path())
+		g.printf("func (c *%s) Find(ctx context.Context, q client.Query) ([]*%s, client.ListInfo, error) {\n", client, typeName)
+		g.printf("\titems := []*%s{}\n", typeName)
+		g.printf("\tinfo, err := c.c.Find(ctx, c.path, q, &items)\n")
+		g.printf("\treturn items, info, err\n}\n\n")
+	}
+	if conf.IsModeAllowed(resource.Create) {
+		g.printf("// Insert creates a new %s item. Read-only fields of item are ignored.\n", r.Path())
+		g.printf("func (c *%s) Insert(ctx context.Context, item *%s) (*%s, error) {\n", client, typeName, typeName)
+		g.printf("\tpayload, err := client.Payload(item, %sOmitted...)\n", lowerFirst(typeName))
+		g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		g.printf("\tcreated := &%s{}\n", typeName)
+		g.printf("\tif created.ETag, err = c.c.Insert(ctx, c.path, payload, created); err != nil {\n\t\treturn nil, err\n\t}\n")
+		g.printf("\treturn created, nil\n}\n\n")
+	}
+	if conf.IsModeAllowed(resource.Update) {
+		g.printf("// Update applies the writable fields of item to the %s item identified by\n", r.Path())
+		g.printf("// id. If item.ETag is set, the update only succeeds if the stored item has the\n")
+		g.printf("// same ETag.\n")
+		g.printf("func (c *%s) Update(ctx context.Context, id %s, item *%s) (*%s, error) {\n", client, idType, typeName, typeName)
+		g.printf("\tpayload, err := client.Payload(item, %sOmitted...)\n", lowerFirst(typeName))
+		g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		g.printf("\tupdated := &%s{}\n", typeName)
+		g.printf("\tif updated.ETag, err = c.c.Update(ctx, client.ItemPath(c.path, id), item.ETag, payload, updated); err != nil {\n\t\treturn nil, err\n\t}\n")
+		g.printf("\treturn updated, nil\n}\n\n")
+	}
+	if conf.IsModeAllowed(resource.Delete) {
+		g.printf("// Delete deletes the %s item identified by id. If etag is not empty, the\n", r.Path())
+		g.printf("// deletion only succeeds if the stored item has the same ETag.\n")
+		g.printf("func (c *%s) Delete(ctx context.Context, id %s, etag string) error {\n", client, idType)
+		g.printf("\treturn c.c.Delete(ctx, client.ItemPath(c.path, id), etag)\n}\n\n")
+	}
+	g.printf("// %sOmitted lists the fields of %s never sent on writes.\n", lowerFirst(typeName), typeName)
+	g.printf("var %sOmitted = []string{%s}\n\n", lowerFirst(typeName), strings.Join(omitted, ", "))
+	for _, sr := range r.GetResources() {
+		subClient := g.conf.TypeName(sr.Path()) + "Client"
+		g.printf("// %s gives access to the %s items of the %s item identified by %s.\n", GoName(sr.Name()), sr.Path(), r.Path(), lowerFirst(typeName)+"ID")
+		g.printf("func (c *%s) %s(%sID %s) *%s {\n", client, GoName(sr.Name()), lowerFirst(typeName), idType, subClient)
+		g.printf("\treturn &%s{c: c.c, path: client.ItemPath(c.path, %sID) + %q}\n}\n\n", subClient, lowerFirst(typeName), "/"+sr.Name())
+		if err := g.resource(sr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structType generates a struct type named name for the schema s found at
+// path. Item structs get an extra ETag field.
+func (g *generator) structType(name, path string, s *schema.Schema, isItem bool) error {
+	if other, found := g.types[name]; found {
+		return fmt.Errorf("%s: type name %s already used for %s", path, name, other)
+	}
+	g.types[name] = path
+	var body bytes.Buffer
+	if isItem {
+		fmt.Fprintf(&body, "\t// ETag is the version of the item as returned by the API.\n")
+		fmt.Fprintf(&body, "\tETag string `json:\"_etag,omitempty\"`\n")
+	}
+	goNames := map[string]string{}
+	for _, fname := range sortedFields(s.Fields) {
+		f := s.Fields[fname]
+		if _, ok := f.Validator.(*schema.Connection); ok {
+			// Connections are only returned when explicitly projected.
+			continue
+		}
+		goName := GoName(fname)
+		if other, found := goNames[goName]; found {
+			return fmt.Errorf("%s: fields %s and %s both map to %s", path, other, fname, goName)
+		}
+		goNames[goName] = fname
+		t, err := g.fieldType(name+goName, path+"."+fname, f)
+		if err != nil {
+			return err
+		}
+		tag := fname
+		// Hidden fields are never returned, so they must be omitted when not
+		// set to avoid overwriting them with a zero value.
+		if !f.Required || f.Hidden {
+			tag += ",omitempty"
+			t = optional(t)
+		}
+		if f.Description != "" {
+			fmt.Fprintf(&body, "\t// %s\n", strings.Replace(f.Description, "\n", "\n\t// ", -1))
+		}
+		fmt.Fprintf(&body, "\t%s %s `json:%q`\n", goName, t, tag)
+	}
+	desc := s.Description
+	if desc == "" {
+		desc = "describes the " + path + " schema."
+	}
+	g.printf("// %s %s\n", name, desc)
+	g.printf("type %s struct {\n%s}\n\n", name, body.String())
+	return nil
+}
+
+// fieldType returns the Go type for the values of f. Nested types are named
+// after name.
+func (g *generator) fieldType(name, path string, f schema.Field) (string, error) {
+	if f.Schema != nil {
+		if err := g.structType(name, path, f.Schema, false); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	return g.validatorType(name, path, f.Validator)
+}
+
+// validatorType returns the Go type for the values accepted by v.
+func (g *generator) validatorType(name, path string, v schema.FieldValidator) (string, error) {
+	switch t := v.(type) {
+	case *schema.String, *schema.Password, *schema.URL, *schema.IP:
+		return "string", nil
+	case *schema.Integer:
+		return "int", nil
+	case *schema.Float:
+		return "float64", nil
+	case *schema.Bool:
+		return "bool", nil
+	case *schema.Time:
+		g.usesTime = true
+		return "time.Time", nil
+	case *schema.Array:
+		vt, err := g.fieldType(name+"Item", path+"[]", t.Values)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + vt, nil
+	case *schema.Dict:
+		vt, err := g.fieldType(name+"Value", path+"{}", t.Values)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + vt, nil
+	case *schema.Object:
+		if t.Schema == nil {
+			return "map[string]interface{}", nil
+		}
+		if err := g.structType(name, path, t.Schema, false); err != nil {
+			return "", err
+		}
+		return name, nil
+	case *schema.AllOf:
+		// All validators must accept the same value, the first one is
+		// representative.
+		if len(*t) > 0 {
+			return g.validatorType(name, path, (*t)[0])
+		}
+	case *schema.Reference:
+		// References hold the id of the referenced item.
+		if rsc, found := g.index.GetResource(t.Path, nil); found {
+			if f, found := rsc.Schema().Fields["id"]; found {
+				return g.validatorType(name, path, f.Validator)
+			}
+		}
+	}
+	return "interface{}", nil
+}
+
+// optional returns the type to use for t when the value may be absent.
+func optional(t string) string {
+	switch t {
+	case "string", "int", "float64", "bool", "time.Time":
+		return "*" + t
+	}
+	if strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") || t == "interface{}" {
+		return t
+	}
+	// Nested structs.
+	return "*" + t
+}
+
+func sortedFields(fields schema.Fields) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// commonInitialisms are upper-cased entirely by GoName, as recommended by
+// golint.
+var commonInitialisms = map[string]bool{
+	"API": true, "ETAG": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// GoName converts a field or resource name to an exported Go identifier
+// (i.e.: created_at gives CreatedAt, user_id gives UserID).
+func GoName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var name string
+	for _, w := range words {
+		if u := strings.ToUpper(w); commonInitialisms[u] {
+			name += u
+			continue
+		}
+		name += strings.ToUpper(w[:1]) + w[1:]
+	}
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// singular returns a naive singular form of an English plural noun.
+func singular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"):
+		return s
+	case strings.HasSuffix(s, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package clientgen_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/rest/client/clientgen"
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	index := resource.NewIndex()
+	users := index.Bind("users", schema.Schema{
+		Description: "is a user of the API.",
+		Fields: schema.Fields{
+			"id":       schema.IDField,
+			"created":  schema.CreatedField,
+			"name":     {Required: true, Validator: &schema.String{}},
+			"age":      {Validator: &schema.Integer{}},
+			"password": schema.PasswordField,
+			"address": {
+				Validator: &schema.Object{Schema: &schema.Schema{
+					Fields: schema.Fields{"city": {Validator: &schema.String{}}},
+				}},
+			},
+			"scores": {
+				Validator: &schema.Dict{Values: schema.Field{Validator: &schema.Float{}}},
+			},
+		},
+	}, nil, resource.DefaultConf)
+	users.Bind("posts", "user", schema.Schema{
+		Fields: schema.Fields{
+			"id":   schema.IDField,
+			"user": {Required: true, Validator: &schema.Reference{Path: "users"}},
+			"tags": {Validator: &schema.Array{Values: schema.Field{Validator: &schema.String{}}}},
+		},
+	}, nil, resource.Conf{AllowedModes: resource.ReadOnly})
+
+	b := new(bytes.Buffer)
+	err := clientgen.Generate(b, index, clientgen.Config{Package: "api"})
+	assert.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "client.go", b, 0)
+	assert.NoError(t, err)
+	// Ignore gofmt alignment.
+	src := regexp.MustCompile("[ \t]+").ReplaceAllString(b.String(), " ")
+
+	for _, expected := range []string{
+		"package api\n",
+		"// User is a user of the API.\ntype User struct {",
+		" ETag string `json:\"_etag,omitempty\"`\n",
+		" Address *UserAddress `json:\"address,omitempty\"`\n",
+		" Created time.Time `json:\"created\"`\n",
+		" Name string `json:\"name\"`\n",
+		" Password *string `json:\"password,omitempty\"`\n",
+		" Scores map[string]float64 `json:\"scores,omitempty\"`\n",
+		"type UserAddress struct {\n City *string `json:\"city,omitempty\"`\n}",
+		"func (c *Client) Users() *UserClient {",
+		"func (c *UserClient) Get(ctx context.Context, id string) (*User, error) {",
+		"func (c *UserClient) Find(ctx context.Context, q client.Query) ([]*User, client.ListInfo, error) {",
+		"func (c *UserClient) Insert(ctx context.Context, item *User) (*User, error) {",
+		"func (c *UserClient) Update(ctx context.Context, id string, item *User) (*User, error) {",
+		"func (c *UserClient) Delete(ctx context.Context, id string, etag string) error {",
+		"var userOmitted = []string{\"created\", \"id\"}",
+		"func (c *UserClient) Posts(userID string) *UserPostClient {",
+		" User string `json:\"user\"`\n",
+		" Tags []string `json:\"tags,omitempty\"`\n",
+		"func (c *UserPostClient) Find(",
+		"var userPostOmitted = []string{\"id\", \"user\"}",
+	} {
+		assert.Contains(t, src, expected)
+	}
+	// Modes not allowed on posts are not generated.
+	assert.NotContains(t, src, "func (c *UserPostClient) Insert(")
+	assert.NotContains(t, src, "func (c *UserPostClient) Delete(")
+}
+
+func TestGenerateNameCollision(t *testing.T) {
+	index := resource.NewIndex()
+	index.Bind("user", schema.Schema{}, nil, resource.DefaultConf)
+	index.Bind("users", schema.Schema{}, nil, resource.DefaultConf)
+	err := clientgen.Generate(new(bytes.Buffer), index, clientgen.Config{})
+	assert.EqualError(t, err, "users: type name User already used for user")
+}
+
+func TestGoName(t *testing.T) {
+	for input, expected := range map[string]string{
+		"name":       "Name",
+		"created_at": "CreatedAt",
+		"user_id":    "UserID",
+		"homeURL":    "HomeURL",
+		"ip":         "IP",
+		"2fa":        "X2fa",
+	} {
+		assert.Equal(t, expected, clientgen.GoName(input), input)
+	}
+}
+
+func TestDefaultTypeName(t *testing.T) {
+	for input, expected := range map[string]string{
+		"users":             "User",
+		"users.posts":       "UserPost",
+		"categories":        "Category",
+		"addresses":         "Address",
+		"users.post_status": "UserPostStatus",
+	} {
+		assert.Equal(t, expected, clientgen.DefaultTypeName(input), input)
+	}
+}
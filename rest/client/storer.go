@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// Storer is a resource.Storer storing items in a resource of a remote REST
+// Layer API. It lets a service bind resources whose data is owned by another
+// service.
+//
+// Queries are sent as filter, sort, skip and limit parameters, so the remote
+// resource must allow filtering and sorting on the queried fields. Note that a
+// query without limit is subject to the PaginationDefaultLimit of the remote
+// resource.
+//
+// Items are inserted with a PUT on their URL, failing with a conflict if an
+// item already exists with the same ID. As the existence is checked with a
+// prior GET, this check isn't atomic. Items are updated with a PATCH holding
+// the changed fields, or a JSON Patch when some fields were removed, and are
+// conditioned to the ETag of the original item through an If-Match header.
+// As the remote API computes its own ETags and may transform the payload, the
+// stored item is copied back into the item passed to Insert or Update.
+type Storer struct {
+	// Client is the client used to reach the remote API.
+	Client *Client
+	// Path is the path of the remote resource, relative to the client's
+	// BaseURL (i.e.: /users).
+	Path string
+	// ReadOnly lists the fields flagged as read-only by the remote resource.
+	// They are never sent by write operations and are generated by the remote
+	// API instead. The id field is always sent through the item URL.
+	ReadOnly []string
+}
+
+// NewStorer creates a Storer for the resource at path of the API reached by c.
+func NewStorer(c *Client, path string, readOnly ...string) *Storer {
+	return &Storer{Client: c, Path: path, ReadOnly: readOnly}
+}
+
+// Find implements resource.Storer interface.
+func (s *Storer) Find(ctx context.Context, q *query.Query) (*resource.ItemList, error) {
+	params := queryParams(q)
+	if q.Window != nil {
+		if q.Window.Limit >= 0 {
+			params.Set("limit", strconv.Itoa(q.Window.Limit))
+		}
+		if q.Window.Offset > 0 {
+			params.Set("skip", strconv.Itoa(q.Window.Offset))
+		}
+	}
+	if len(q.Sort) > 0 {
+		params.Set("sort", q.Sort.String())
+	}
+	var payloads []map[string]interface{}
+	res, err := s.Client.Do(ctx, http.MethodGet, s.Path, params, nil, nil, &payloads)
+	if err != nil {
+		return nil, storerError(ctx, err)
+	}
+	list := &resource.ItemList{Total: -1, Items: make([]*resource.Item, 0, len(payloads))}
+	if q.Window != nil {
+		list.Offset = q.Window.Offset
+		list.Limit = q.Window.Limit
+	}
+	if total, err := strconv.Atoi(res.Header.Get("X-Total")); err == nil {
+		list.Total = total
+	}
+	for _, p := range payloads {
+		list.Items = append(list.Items, newItem(p, "", time.Time{}))
+	}
+	return list, nil
+}
+
+// Count implements resource.Counter interface.
+func (s *Storer) Count(ctx context.Context, q *query.Query) (int, error) {
+	params := queryParams(q)
+	params.Set("limit", "0")
+	params.Set("total", "1")
+	res, err := s.Client.Do(ctx, http.MethodGet, s.Path, params, nil, nil, nil)
+	if err != nil {
+		return -1, storerError(ctx, err)
+	}
+	total, err := strconv.Atoi(res.Header.Get("X-Total"))
+	if err != nil {
+		return -1, resource.ErrNotImplemented
+	}
+	return total, nil
+}
+
+// MultiGet implements resource.MultiGetter interface.
+func (s *Storer) MultiGet(ctx context.Context, ids []interface{}) ([]*resource.Item, error) {
+	values := make([]query.Value, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	q := &query.Query{
+		Predicate: query.Predicate{&query.In{Field: "id", Values: values}},
+		Window:    &query.Window{Limit: len(ids)},
+	}
+	list, err := s.Find(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*resource.Item, 0, len(ids))
+	for _, id := range ids {
+		for _, item := range list.Items {
+			if reflect.DeepEqual(item.ID, id) {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+	return items, nil
+}
+
+// Insert implements resource.Storer interface. Only one item can be inserted at
+// a time as the REST protocol doesn't support atomic batch inserts.
+func (s *Storer) Insert(ctx context.Context, items []*resource.Item) error {
+	if len(items) != 1 {
+		return resource.ErrNotImplemented
+	}
+	item := items[0]
+	path := ItemPath(s.Path, item.ID)
+	_, err := s.Client.Do(ctx, http.MethodGet, path, nil, nil, nil, nil)
+	if err == nil {
+		return resource.ErrConflict
+	}
+	if err = storerError(ctx, err); err != resource.ErrNotFound {
+		return err
+	}
+	payload := make(map[string]interface{}, len(item.Payload))
+	for k, v := range item.Payload {
+		payload[k] = v
+	}
+	s.omitReadOnly(payload)
+	return s.write(ctx, http.MethodPut, path, item, nil, payload)
+}
+
+// Update implements resource.Storer interface.
+func (s *Storer) Update(ctx context.Context, item *resource.Item, original *resource.Item) error {
+	changes := map[string]interface{}{}
+	removed := false
+	for k, v := range item.Payload {
+		if ov, found := original.Payload[k]; !found || !reflect.DeepEqual(v, ov) {
+			changes[k] = v
+		}
+	}
+	for k := range original.Payload {
+		if _, found := item.Payload[k]; !found {
+			removed = true
+		}
+	}
+	s.omitReadOnly(changes)
+	header := ifMatch(original.ETag)
+	if !removed {
+		return s.write(ctx, http.MethodPatch, ItemPath(s.Path, item.ID), item, header, changes)
+	}
+	// Fields can only be removed using JSON Patch.
+	ops := make([]map[string]interface{}, 0, len(changes))
+	for k := range original.Payload {
+		if _, found := item.Payload[k]; !found {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": "/" + escapePointer(k)})
+		}
+	}
+	for k, v := range changes {
+		ops = append(ops, map[string]interface{}{"op": "add", "path": "/" + escapePointer(k), "value": v})
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json-patch+json")
+	return s.write(ctx, http.MethodPatch, ItemPath(s.Path, item.ID), item, header, ops)
+}
+
+// Delete implements resource.Storer interface.
+func (s *Storer) Delete(ctx context.Context, item *resource.Item) error {
+	err := s.Client.Delete(ctx, ItemPath(s.Path, item.ID), item.ETag)
+	return storerError(ctx, err)
+}
+
+// Clear implements resource.Storer interface.
+func (s *Storer) Clear(ctx context.Context, q *query.Query) (int, error) {
+	params := queryParams(q)
+	if q.Window != nil && q.Window.Limit >= 0 {
+		params.Set("limit", strconv.Itoa(q.Window.Limit))
+	}
+	res, err := s.Client.Do(ctx, http.MethodDelete, s.Path, params, nil, nil, nil)
+	if err != nil {
+		return 0, storerError(ctx, err)
+	}
+	total, err := strconv.Atoi(res.Header.Get("X-Total"))
+	if err != nil {
+		return -1, nil
+	}
+	return total, nil
+}
+
+// write sends body to path and copies the stored item returned by the remote
+// API back into item.
+func (s *Storer) write(ctx context.Context, method, path string, item *resource.Item, header http.Header, body interface{}) error {
+	var payload map[string]interface{}
+	res, err := s.Client.Do(ctx, method, path, nil, header, body, &payload)
+	if err != nil {
+		return storerError(ctx, err)
+	}
+	updated, _ := http.ParseTime(res.Header.Get("Last-Modified"))
+	*item = *newItem(payload, ParseETag(res.Header.Get("Etag")), updated)
+	return nil
+}
+
+func (s *Storer) omitReadOnly(payload map[string]interface{}) {
+	delete(payload, "id")
+	for _, f := range s.ReadOnly {
+		delete(payload, f)
+	}
+}
+
+// queryParams returns the parameters representing the predicate of q.
+func queryParams(q *query.Query) url.Values {
+	params := url.Values{}
+	if len(q.Predicate) > 0 {
+		params.Set("filter", q.Predicate.String())
+	}
+	return params
+}
+
+// newItem creates an item from a payload returned by the remote API, taking
+// the ETag from the _etag field when etag is empty.
+func newItem(payload map[string]interface{}, etag string, updated time.Time) *resource.Item {
+	if e, ok := payload["_etag"].(string); ok {
+		if etag == "" {
+			etag = e
+		}
+		delete(payload, "_etag")
+	}
+	return &resource.Item{
+		ID:      payload["id"],
+		ETag:    etag,
+		Updated: updated,
+		Payload: payload,
+	}
+}
+
+// storerError maps the errors returned by the remote API to resource errors.
+func storerError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if e, ok := err.(*Error); ok {
+		switch e.Code {
+		case http.StatusNotFound:
+			return resource.ErrNotFound
+		case http.StatusConflict, http.StatusPreconditionFailed:
+			return resource.ErrConflict
+		case http.StatusForbidden:
+			return resource.ErrForbidden
+		case http.StatusNotImplemented:
+			return resource.ErrNotImplemented
+		}
+	}
+	return err
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
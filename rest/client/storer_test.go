@@ -0,0 +1,186 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/rest"
+	"github.com/rs/rest-layer/rest/client"
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+var storerTestSchema = schema.Schema{
+	Fields: schema.Fields{
+		"id": schema.IDField,
+		"serial": {ReadOnly: true, OnInit: func(ctx context.Context, value interface{}) interface{} {
+			return "s1"
+		}},
+		"name": {Required: true, Filterable: true, Sortable: true, Validator: &schema.String{}},
+		"age":  {Filterable: true, Validator: &schema.Integer{}},
+	},
+}
+
+func newStorerTestServer(t *testing.T) *httptest.Server {
+	index := resource.NewIndex()
+	index.Bind("users", storerTestSchema, mem.NewHandler(), resource.DefaultConf)
+	h, err := rest.NewHandler(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(h)
+}
+
+func TestStorer(t *testing.T) {
+	srv := newStorerTestServer(t)
+	defer srv.Close()
+	s := client.NewStorer(client.New(srv.URL), "/users", "serial")
+	ctx := context.Background()
+	idA, idB, idC := "a0000000000000000000", "b0000000000000000000", "c0000000000000000000"
+
+	for _, p := range []map[string]interface{}{
+		{"id": idA, "name": "john", "age": 30},
+		{"id": idB, "name": "paul", "age": 20},
+		{"id": idC, "name": "jack", "age": 10},
+	} {
+		item, err := resource.NewItem(p)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Insert(ctx, []*resource.Item{item}))
+		// The item is updated with the stored version.
+		assert.NotEmpty(t, item.ETag)
+		assert.Equal(t, "s1", item.Payload["serial"])
+		assert.False(t, item.Updated.IsZero())
+	}
+
+	// Insert of an existing item.
+	item, _ := resource.NewItem(map[string]interface{}{"id": idA, "name": "john"})
+	assert.Equal(t, resource.ErrConflict, s.Insert(ctx, []*resource.Item{item}))
+	assert.Equal(t, resource.ErrNotImplemented, s.Insert(ctx, []*resource.Item{item, item}))
+
+	l, err := s.Find(ctx, &query.Query{
+		Predicate: query.MustParsePredicate(`{age: {$gte: 20}}`),
+		Sort:      query.MustParseSort("-name"),
+		Window:    &query.Window{Offset: 1, Limit: 1},
+	})
+	if assert.NoError(t, err) && assert.Len(t, l.Items, 1) {
+		assert.Equal(t, 2, l.Total)
+		assert.Equal(t, 1, l.Offset)
+		assert.Equal(t, idA, l.Items[0].ID)
+		assert.Equal(t, "john", l.Items[0].Payload["name"])
+		assert.NotEmpty(t, l.Items[0].ETag)
+		assert.NotContains(t, l.Items[0].Payload, "_etag")
+	}
+
+	n, err := s.Count(ctx, &query.Query{Predicate: query.MustParsePredicate(`{name: {$in: ["john", "jack"]}}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	items, err := s.MultiGet(ctx, []interface{}{idC, "00000000000000000000", idA})
+	if assert.NoError(t, err) && assert.Len(t, items, 2) {
+		assert.Equal(t, idC, items[0].ID)
+		assert.Equal(t, idA, items[1].ID)
+	}
+
+	// Update of a field.
+	original := items[1]
+	updated := &resource.Item{ID: idA, Payload: map[string]interface{}{
+		"id": idA, "serial": original.Payload["serial"], "name": "johnny", "age": 30,
+	}}
+	assert.NoError(t, s.Update(ctx, updated, original))
+	assert.Equal(t, "johnny", updated.Payload["name"])
+	assert.NotEqual(t, original.ETag, updated.ETag)
+
+	// Update with a stale ETag.
+	assert.Equal(t, resource.ErrConflict, s.Update(ctx, &resource.Item{ID: idA, Payload: map[string]interface{}{
+		"id": idA, "name": "jo",
+	}}, original))
+
+	// Update removing a field.
+	removed := &resource.Item{ID: idA, Payload: map[string]interface{}{
+		"id": idA, "serial": updated.Payload["serial"], "name": "johnny",
+	}}
+	assert.NoError(t, s.Update(ctx, removed, updated))
+	assert.NotContains(t, removed.Payload, "age")
+
+	// Delete with a stale ETag, then with the right one.
+	assert.Equal(t, resource.ErrConflict, s.Delete(ctx, original))
+	assert.NoError(t, s.Delete(ctx, removed))
+	assert.Equal(t, resource.ErrNotFound, s.Delete(ctx, removed))
+
+	n, err = s.Clear(ctx, &query.Query{Predicate: query.MustParsePredicate(`{name: "paul"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	l, err = s.Find(ctx, &query.Query{})
+	if assert.NoError(t, err) && assert.Len(t, l.Items, 1) {
+		assert.Equal(t, idC, l.Items[0].ID)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.Find(ctx, &query.Query{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestStorerUpdateWithoutETag(t *testing.T) {
+	srv := newStorerTestServer(t)
+	defer srv.Close()
+	s := client.NewStorer(client.New(srv.URL), "/users", "serial")
+	ctx := context.Background()
+	id := "a0000000000000000000"
+
+	original, _ := resource.NewItem(map[string]interface{}{"id": id, "name": "john", "age": 30})
+	assert.NoError(t, s.Insert(ctx, []*resource.Item{original}))
+	// Update removing a field from an item without ETag, sent without If-Match.
+	original.ETag = ""
+	updated := &resource.Item{ID: id, Payload: map[string]interface{}{
+		"id": id, "serial": original.Payload["serial"], "name": "john",
+	}}
+	assert.NoError(t, s.Update(ctx, updated, original))
+	assert.NotContains(t, updated.Payload, "age")
+}
+
+// TestStorerProxy binds a resource using the Storer to a local handler and
+// makes sure requests are proxied to the remote API.
+func TestStorerProxy(t *testing.T) {
+	remote := newStorerTestServer(t)
+	defer remote.Close()
+	index := resource.NewIndex()
+	index.Bind("users", storerTestSchema, client.NewStorer(client.New(remote.URL), "/users", "serial"), resource.DefaultConf)
+	h, err := rest.NewHandler(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := httptest.NewServer(h)
+	defer local.Close()
+
+	res, err := http.Post(local.URL+"/users", "application/json", bytes.NewBufferString(`{"name": "john", "age": 30}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var created map[string]interface{}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&created))
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "john", created["name"])
+
+	c := client.New(remote.URL)
+	var stored map[string]interface{}
+	etag, err := c.Get(context.Background(), client.ItemPath("/users", created["id"]), nil, &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, `W/"`+etag+`"`, res.Header.Get("Etag"))
+	assert.Equal(t, created, stored)
+
+	c = client.New(local.URL)
+	_, err = c.Update(context.Background(), client.ItemPath("/users", created["id"]), etag, map[string]interface{}{"age": 31}, &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(31), stored["age"])
+	_, err = c.Update(context.Background(), client.ItemPath("/users", created["id"]), etag, map[string]interface{}{"age": 32}, nil)
+	assert.Equal(t, &client.Error{Code: 412, Message: "Precondition Failed"}, err)
+}
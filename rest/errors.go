@@ -3,8 +3,10 @@ package rest
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema"
 )
 
 var (
@@ -51,6 +53,12 @@ func NewError(err error) *Error {
 	if Err, ok := err.(*Error); ok {
 		return Err
 	}
+	if e, ok := err.(*resource.ValidationError); ok {
+		return &Error{422, e.Error(), e.Issues}
+	}
+	if e, ok := err.(*resource.UniqueError); ok {
+		return &Error{http.StatusConflict, ErrConflict.Message, uniqueIssues(e.Constraint)}
+	}
 	switch err {
 	case context.Canceled:
 		return ErrClientClosedRequest
@@ -64,6 +72,10 @@ func NewError(err error) *Error {
 		return ErrConflict
 	case resource.ErrNotImplemented:
 		return ErrNotImplemented
+	case resource.ErrReferenced:
+		return &Error{http.StatusConflict, err.Error(), nil}
+	case resource.ErrScanLimit:
+		return &Error{http.StatusUnprocessableEntity, err.Error(), nil}
 	case resource.ErrNoStorage:
 		return &Error{501, err.Error(), nil}
 	case nil:
@@ -73,6 +85,32 @@ func NewError(err error) *Error {
 	}
 }
 
+// uniqueIssues returns the issues reported on the fields of a violated unique
+// constraint.
+func uniqueIssues(c resource.UniqueConstraint) map[string][]interface{} {
+	issues := map[string][]interface{}{}
+	for i, field := range c {
+		issue := "must be unique"
+		if len(c) > 1 {
+			others := make([]string, 0, len(c)-1)
+			others = append(append(others, c[:i]...), c[i+1:]...)
+			issue += " in combination with " + strings.Join(others, ", ")
+		}
+		issues[field] = []interface{}{issue}
+	}
+	return issues
+}
+
+// newDocumentError returns the error for the issues reported by the validation
+// of a document. Changes on fields the client is not allowed to write result in
+// a 403 error.
+func newDocumentError(errs map[string][]interface{}) *Error {
+	if schema.IsForbidden(errs) {
+		return &Error{http.StatusForbidden, "Document contains forbidden change(s)", errs}
+	}
+	return &Error{422, "Document contains error(s)", errs}
+}
+
 // Error returns the error as string
 func (e *Error) Error() string {
 	return e.Message
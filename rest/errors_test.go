@@ -16,9 +16,17 @@ func TestNewError(t *testing.T) {
 	assert.Equal(t, ErrNotFound, NewError(resource.ErrNotFound))
 	assert.Equal(t, ErrConflict, NewError(resource.ErrConflict))
 	assert.Equal(t, ErrNotImplemented, NewError(resource.ErrNotImplemented))
+	assert.Equal(t, &Error{409, "Item Is Referenced", nil}, NewError(resource.ErrReferenced))
+	assert.Equal(t, &Error{422, "Too Many Items To Scan", nil}, NewError(resource.ErrScanLimit))
+	assert.Equal(t, &Error{409, "Conflict", map[string][]interface{}{
+		"a": {"must be unique in combination with b"},
+		"b": {"must be unique in combination with a"},
+	}}, NewError(&resource.UniqueError{Constraint: resource.UniqueConstraint{"a", "b"}}))
 	assert.Nil(t, NewError(nil))
 	assert.Equal(t, &Error{520, "test", nil}, NewError(errors.New("test")))
 	assert.Equal(t, ErrNotFound, NewError(ErrNotFound))
+	issues := map[string][]interface{}{"foo": {"Not Found"}}
+	assert.Equal(t, &Error{422, "Document contains error(s)", issues}, NewError(&resource.ValidationError{Issues: issues}))
 }
 
 func TestError(t *testing.T) {
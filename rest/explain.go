@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/rs/rest-layer/resource"
+)
+
+// newExplanation returns a new explanation if the route requests one with the
+// explain query-string parameter and the handler allows it (see
+// Handler.Explain).
+func newExplanation(ctx context.Context, route *RouteMatch) (*resource.Explanation, bool) {
+	if allowed, _ := ctx.Value(explainKey).(bool); !allowed || route.Params.Get("explain") != "1" {
+		return nil, false
+	}
+	return &resource.Explanation{}, true
+}
+
+// explanationBody returns the response body describing ex, the explanation of
+// the Find having returned list.
+func explanationBody(ex *resource.Explanation, list *resource.ItemList) map[string]interface{} {
+	q := map[string]interface{}{}
+	if ex.Query != nil {
+		q["filter"] = ex.Query.Predicate.String()
+		if len(ex.Query.Sort) > 0 {
+			q["sort"] = ex.Query.Sort.String()
+		}
+		if len(ex.Query.Projection) > 0 {
+			q["fields"] = ex.Query.Projection.String()
+		}
+		if w := ex.Query.Window; w != nil {
+			q["offset"] = w.Offset
+			q["limit"] = w.Limit
+		}
+	}
+	hooks := ex.Hooks
+	if hooks == nil {
+		hooks = []string{}
+	}
+	stages := make([]map[string]interface{}, 0, len(ex.Stages))
+	for _, s := range ex.Stages {
+		stages = append(stages, map[string]interface{}{
+			"name":     s.Name,
+			"duration": s.Duration.String(),
+		})
+	}
+	body := map[string]interface{}{
+		"query":  q,
+		"hooks":  hooks,
+		"stages": stages,
+		"found":  len(list.Items),
+	}
+	if list.Total >= 0 {
+		body["total"] = list.Total
+	}
+	if ex.Plan != nil {
+		body["plan"] = ex.Plan
+	}
+	return body
+}
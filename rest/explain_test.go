@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerExplain(t *testing.T) {
+	s := mem.NewHandler()
+	s.Insert(context.Background(), []*resource.Item{
+		{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "name": "a"}},
+		{ID: "2", ETag: "b", Payload: map[string]interface{}{"id": "2", "name": "b"}},
+	})
+	index := resource.NewIndex()
+	foo := index.Bind("foo", schema.Schema{Fields: schema.Fields{
+		"id":   {Sortable: true},
+		"name": {Filterable: true},
+	}}, s, resource.DefaultConf)
+	foo.Use(resource.FindEventHandlerFunc(func(ctx context.Context, q *query.Query) error {
+		q.Predicate = append(q.Predicate, &query.NotEqual{Field: "name", Value: "c"})
+		return nil
+	}))
+	h, err := NewHandler(index)
+	if !assert.NoError(t, err) {
+		return
+	}
+	serve := func() *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", `/foo?explain=1&sort=-id&filter={name:{$in:["a"]}}&limit=5`, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// The parameter is ignored unless enabled.
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id": "1", "name": "a", "_etag": "a"}]`, w.Body.String())
+
+	h.Explain = true
+	w = serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Query  map[string]interface{}
+		Hooks  []string
+		Stages []struct{ Name, Duration string }
+		Found  int
+	}
+	if !assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body)) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"filter": `{name: "a", name: {$ne: "c"}}`,
+		"sort":   "-id",
+		"offset": 0.0,
+		"limit":  5.0,
+	}, body.Query)
+	assert.Equal(t, []string{"resource.FindEventHandlerFunc"}, body.Hooks)
+	names := []string{}
+	for _, s := range body.Stages {
+		names = append(names, s.Name)
+		assert.NotEmpty(t, s.Duration)
+	}
+	assert.Equal(t, []string{"parse", "find hooks", "restriction", "storage", "found hooks"}, names)
+	assert.Equal(t, 1, body.Found)
+}
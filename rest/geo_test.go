@@ -0,0 +1,66 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestGeoSearch(t *testing.T) {
+	init := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.Background(), []*resource.Item{
+			{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "name": "Paris", "loc": map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.3522, 48.8566}}}},
+			{ID: "2", ETag: "b", Payload: map[string]interface{}{"id": "2", "name": "London", "loc": map[string]interface{}{"type": "Point", "coordinates": []interface{}{-0.1276, 51.5072}}}},
+			{ID: "3", ETag: "c", Payload: map[string]interface{}{"id": "3", "name": "Tokyo", "loc": map[string]interface{}{"type": "Point", "coordinates": []interface{}{139.6917, 35.6895}}}},
+		})
+		index := resource.NewIndex()
+		index.Bind("places", schema.Schema{Fields: schema.Fields{
+			"id":   {},
+			"name": {Filterable: true},
+			"loc":  {Filterable: true, Validator: &schema.GeoPoint{}},
+		}}, s, resource.DefaultConf)
+		return &requestTestVars{Index: index}
+	}
+	tests := map[string]requestTest{
+		"Near": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/places?filter={loc:{$near:{$geometry:[-1.55,47.22],$maxDistance:500000}}}&sort=_distance&fields=name`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[
+				{"name": "Paris", "_etag": "a"},
+				{"name": "London", "_etag": "b"}
+			]`,
+		},
+		"Within": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/places?filter={loc:{$within:{$circle:[[2.35,48.85],10000]}}}&fields=name`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"name": "Paris", "_etag": "a"}]`,
+		},
+		"NotGeoPoint": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/places?filter={name:{$near:{$geometry:[0,0]}}}`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"filter": ["name: not a geo point field"]}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/rs/rest-layer/resource"
 )
@@ -18,6 +19,26 @@ type Handler struct {
 	// FallbackHandlerFunc is called when REST layer doesn't find a route for
 	// the request. If not set, a 404 or 405 standard REST error is returned.
 	FallbackHandlerFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request)
+	// IdempotencyStore stores the responses of POST and PATCH requests sent
+	// with an Idempotency-Key header so retries of these requests get the
+	// same response instead of being processed again. If not set, the
+	// header is ignored.
+	IdempotencyStore IdempotencyStore
+	// IdempotencyTTL is the time during which the responses are kept in the
+	// IdempotencyStore. If not set, DefaultIdempotencyTTL is used.
+	IdempotencyTTL time.Duration
+	// IdempotencyMaxBodySize is the maximum size in bytes of the body of the
+	// requests sent with an Idempotency-Key header, as the body is read in
+	// memory to be compared with the retries. Larger requests are rejected
+	// with a 413 status. If not set, DefaultIdempotencyMaxBodySize is used.
+	IdempotencyMaxBodySize int64
+	// Explain allows clients to add the explain=1 query-string parameter to
+	// list requests to get a description of how the query was performed
+	// instead of the items: the query sent to the storage handler, the hooks
+	// modifying it, the duration of each stage and the plan of the storage
+	// handler if it implements resource.Explainer. As it exposes the
+	// internals of the API, it should only be enabled for debugging.
+	Explain bool
 	// index stores the resource router.
 	index resource.Index
 }
@@ -63,8 +84,21 @@ func (h *Handler) ServeHTTPC(ctx context.Context, w http.ResponseWriter, r *http
 	// Store the route and the router in the context
 	ctx = contextWithRoute(ctx, route)
 	ctx = contextWithIndex(ctx, h.index)
+	if h.Explain {
+		ctx = context.WithValue(ctx, explainKey, true)
+	}
+
+	if h.isIdempotent(r) {
+		h.serveIdempotent(ctx, w, r, func(w http.ResponseWriter) {
+			h.serveRoute(ctx, w, r, route, skipBody)
+		})
+		return
+	}
+	h.serveRoute(ctx, w, r, route, skipBody)
+}
 
-	// Execute the main route handler
+// serveRoute executes the main route handler and sends its response.
+func (h *Handler) serveRoute(ctx context.Context, w http.ResponseWriter, r *http.Request, route *RouteMatch, skipBody bool) {
 	status, headers, body := routeHandler(ctx, r, route)
 	if headers == nil {
 		headers = http.Header{}
@@ -95,12 +129,28 @@ func routeHandler(ctx context.Context, r *http.Request, route *RouteMatch) (stat
 	}
 	conf := rsrc.Conf()
 	isItem := route.ResourceID() != nil
+	if route.Action != "" {
+		mh := getAllowedActionHandler(isItem, route.Action, route.Method, conf)
+		if mh == nil {
+			headers = http.Header{}
+			setActionAllowHeader(headers, isItem, route.Action, conf)
+			return ErrInvalidMethod.Code, headers, ErrInvalidMethod
+		}
+		return mh(ctx, r, route)
+	}
 	mh := getAllowedMethodHandler(isItem, route.Method, conf)
 	if mh == nil {
 		headers = http.Header{}
 		setAllowHeader(headers, isItem, conf)
 		return ErrInvalidMethod.Code, headers, ErrInvalidMethod
 	}
+	if conf.SoftDeleteField != "" && (route.Method == http.MethodGet || route.Method == http.MethodHead) {
+		f, err := getDeletedFilterParam(route.Params)
+		if err != nil {
+			return err.Code, nil, err
+		}
+		ctx = resource.NewContextWithDeletedFilter(ctx, f)
+	}
 	return mh(ctx, r, route)
 }
 
@@ -0,0 +1,238 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	t1 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	init := func(modes ...resource.Mode) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.Background(), []*resource.Item{
+				{ID: "1", ETag: "b", Updated: t2, Payload: map[string]interface{}{"id": "1", "foo": "bar"}},
+				{ID: "3", ETag: "e", Updated: t1, Payload: map[string]interface{}{"id": "3", "foo": "bar"}},
+			})
+			h := mem.NewHistoryHandler()
+			h.Record(context.Background(), []*resource.Revision{
+				{Resource: "foo", ItemID: "1", Op: resource.RevisionInsert, ETag: "a", Time: t1, Actor: "john",
+					Payload: map[string]interface{}{"id": "1", "foo": "baz"}},
+				{Resource: "foo", ItemID: "1", Op: resource.RevisionUpdate, ETag: "b", Time: t2, Actor: "paul",
+					Payload: map[string]interface{}{"id": "1", "foo": "bar"}},
+				{Resource: "foo", ItemID: "2", Op: resource.RevisionInsert, ETag: "c", Time: t1,
+					Payload: map[string]interface{}{"id": "2", "foo": "baz"}},
+				{Resource: "foo", ItemID: "2", Op: resource.RevisionDelete, ETag: "c", Time: t2,
+					Payload: map[string]interface{}{"id": "2", "foo": "baz"}},
+				{Resource: "foo", ItemID: "3", Op: resource.RevisionInsert, ETag: "d", Time: t1,
+					Payload: map[string]interface{}{"id": "3", "foo": 42}},
+			})
+			index := resource.NewIndex()
+			index.Bind("foo", schema.Schema{Fields: schema.Fields{
+				"id":  {},
+				"foo": {Validator: &schema.String{}},
+			}}, s, resource.Conf{AllowedModes: modes, History: h})
+			return &requestTestVars{Index: index, Storers: map[string]resource.Storer{"foo": s}}
+		}
+	}
+	// denied initializes the resource with a hook denying reads.
+	denied := func(h interface{}) func() *requestTestVars {
+		return func() *requestTestVars {
+			vars := init(resource.ReadWrite...)()
+			rsrc, _ := vars.Index.GetResource("foo", nil)
+			rsrc.Use(h)
+			return vars
+		}
+	}
+	denyFind := resource.FindEventHandlerFunc(func(ctx context.Context, q *query.Query) error {
+		return resource.ErrForbidden
+	})
+	denyGet := resource.GetEventHandlerFunc(func(ctx context.Context, id interface{}) error {
+		return resource.ErrForbidden
+	})
+	tests := map[string]requestTest{
+		"History": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1/_history", nil)
+			},
+			ResponseCode:   http.StatusOK,
+			ResponseHeader: http.Header{"X-Total": []string{"2"}},
+			ResponseBody: `[
+				{"op": "insert", "time": "2018-01-01T00:00:00Z", "actor": "john", "payload": {"id": "1", "foo": "baz"}, "_etag": "a"},
+				{"op": "update", "time": "2018-01-01T01:00:00Z", "actor": "paul", "payload": {"id": "1", "foo": "bar"}, "_etag": "b"}
+			]`,
+		},
+		"History/Deleted": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/2/_history?fields=foo", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[
+				{"op": "insert", "time": "2018-01-01T00:00:00Z", "payload": {"foo": "baz"}, "_etag": "c"},
+				{"op": "delete", "time": "2018-01-01T01:00:00Z", "payload": {"foo": "baz"}, "_etag": "c"}
+			]`,
+		},
+		"History/NotFound": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/4/_history", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Not Found"}`,
+		},
+		"History/DeniedFind": {
+			Init: denied(denyFind),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1/_history", nil)
+			},
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: `{"code": 403, "message": "Forbidden"}`,
+		},
+		"History/DeniedGet": {
+			Init: denied(denyGet),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/2/_history", nil)
+			},
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: `{"code": 403, "message": "Forbidden"}`,
+		},
+		"History/InvalidMethod": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/foo/1/_history", nil)
+			},
+			ResponseCode:   http.StatusMethodNotAllowed,
+			ResponseHeader: http.Header{"Allow": []string{"GET, HEAD"}},
+			ResponseBody:   `{"code": 405, "message": "Invalid Method"}`,
+		},
+		"GetAt/Time": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1?at=2018-01-01T00:30:00Z", nil)
+			},
+			ResponseCode:   http.StatusOK,
+			ResponseHeader: http.Header{"Etag": []string{`W/"a"`}},
+			ResponseBody:   `{"id": "1", "foo": "baz"}`,
+		},
+		"GetAt/ETag": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1?at=a", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "foo": "baz"}`,
+		},
+		"GetAt/DeniedFind": {
+			Init: denied(denyFind),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1?at=a", nil)
+			},
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: `{"code": 403, "message": "Forbidden"}`,
+		},
+		"GetAt/DeniedGet": {
+			Init: denied(denyGet),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1?at=a", nil)
+			},
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: `{"code": 403, "message": "Forbidden"}`,
+		},
+		"GetAt/Before": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1?at=2017-01-01T00:00:00Z", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Revision Not Found"}`,
+		},
+		"GetAt/Deleted": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/2?at=2018-01-02T00:00:00Z", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Not Found"}`,
+		},
+		"GetAt/BeforeDelete": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/2?at=2018-01-01T00:00:00Z", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "2", "foo": "baz"}`,
+		},
+		"Revert": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/1/_revert?at=a", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "foo": "baz"}`,
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				rsrc, _ := vars.Index.GetResource("foo", nil)
+				h, err := rsrc.History(context.Background(), "1")
+				if assert.NoError(t, err) && assert.Len(t, h, 3) {
+					assert.Equal(t, resource.RevisionUpdate, h[2].Op)
+					assert.Equal(t, "baz", h[2].Payload["foo"])
+				}
+			},
+		},
+		"Revert/MissingAt": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/1/_revert", nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"at": ["required"]}
+			}`,
+		},
+		"Revert/Invalid": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/3/_revert?at=d", nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"foo": ["not a string"]}
+			}`,
+		},
+		"Revert/Deleted": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/2/_revert?at=c", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Not Found"}`,
+		},
+		"Revert/Denied": {
+			Init: init(resource.Read),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/1/_revert?at=a", nil)
+			},
+			ResponseCode:   http.StatusMethodNotAllowed,
+			ResponseHeader: http.Header{"Allow": nil},
+			ResponseBody:   `{"code": 405, "message": "Invalid Method"}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
@@ -0,0 +1,250 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+)
+
+// DefaultIdempotencyTTL is the time during which the response of a request
+// sent with an Idempotency-Key header is kept when Handler.IdempotencyTTL is
+// not set.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// DefaultIdempotencyMaxBodySize is the maximum size in bytes of the body of a
+// request sent with an Idempotency-Key header when
+// Handler.IdempotencyMaxBodySize is not set.
+const DefaultIdempotencyMaxBodySize = 1 << 20
+
+// idempotencyPurgeInterval is the minimum time between two purges of the
+// expired entries of a MemoryIdempotencyStore.
+const idempotencyPurgeInterval = time.Minute
+
+var (
+	// ErrIdempotencyKeyReused is returned when an Idempotency-Key is reused
+	// with a different request body.
+	ErrIdempotencyKeyReused = &Error{http.StatusUnprocessableEntity, "Idempotency-Key Reused With Different Request", nil}
+	// ErrIdempotentRequestInProgress is returned when a request is retried
+	// with an Idempotency-Key while the first request is still processed.
+	ErrIdempotentRequestInProgress = &Error{http.StatusConflict, "Request In Progress", nil}
+	// ErrIdempotentRequestTooLarge is returned when the body of a request sent
+	// with an Idempotency-Key exceeds the maximum size.
+	ErrIdempotentRequestTooLarge = &Error{http.StatusRequestEntityTooLarge, "Request Body Too Large", nil}
+)
+
+// IdempotentResponse is a response stored for a request sent with an
+// Idempotency-Key header.
+type IdempotentResponse struct {
+	// Fingerprint identifies the request body the response was produced for.
+	Fingerprint string
+	// Status is the HTTP status of the response. A zero status means the
+	// request is still being processed.
+	Status int
+	// Header holds the response headers.
+	Header http.Header
+	// Body holds the response body.
+	Body []byte
+}
+
+// IdempotencyStore stores the responses of the requests sent with an
+// Idempotency-Key header. The keys passed to the store already combine the
+// Idempotency-Key with the route and principal of the request.
+type IdempotencyStore interface {
+	// Reserve atomically marks key as being processed for the request with the
+	// given fingerprint for ttl, and returns nil. If key is already reserved
+	// or holds a response, the stored entry is returned instead and key is
+	// left untouched.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotentResponse, error)
+	// Save stores res for key for ttl, replacing the reservation.
+	Save(ctx context.Context, key string, res *IdempotentResponse, ttl time.Duration) error
+	// Release removes the reservation of key so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore keeping responses in memory.
+// It is only suitable for single process deployments. Expired entries are
+// ignored when looked up, and purged at most once a minute.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+	purgeAt time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	res     *IdempotentResponse
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty memory idempotency store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: map[string]memoryIdempotencyEntry{}}
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if e, found := s.entries[key]; found && now.Before(e.expires) {
+		return e.res, nil
+	}
+	// Purge expired entries so the store doesn't grow unbounded.
+	if !now.Before(s.purgeAt) {
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.purgeAt = now.Add(idempotencyPurgeInterval)
+	}
+	s.entries[key] = memoryIdempotencyEntry{
+		res:     &IdempotentResponse{Fingerprint: fingerprint},
+		expires: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Save implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Save(ctx context.Context, key string, res *IdempotentResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryIdempotencyEntry{res: res, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// isIdempotent returns true if r must be handled with the idempotency store.
+func (h *Handler) isIdempotent(r *http.Request) bool {
+	return h.IdempotencyStore != nil && r.Header.Get("Idempotency-Key") != "" &&
+		(r.Method == http.MethodPost || r.Method == http.MethodPatch)
+}
+
+// serveIdempotent handles a request sent with an Idempotency-Key header,
+// replaying the stored response of the first request with the same key if
+// any, or calling next and storing its response otherwise.
+func (h *Handler) serveIdempotent(ctx context.Context, w http.ResponseWriter, r *http.Request, next func(w http.ResponseWriter)) {
+	ttl := h.IdempotencyTTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	maxSize := h.IdempotencyMaxBodySize
+	if maxSize <= 0 {
+		maxSize = DefaultIdempotencyMaxBodySize
+	}
+	// Read one more byte than allowed to detect larger bodies.
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		h.sendResponse(ctx, w, 0, http.Header{}, &Error{400, "Cannot read request body", nil}, false)
+		return
+	}
+	if int64(len(body)) > maxSize {
+		h.sendResponse(ctx, w, 0, http.Header{}, ErrIdempotentRequestTooLarge, false)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	key, err := idempotencyKey(ctx, r)
+	if err != nil {
+		h.sendResponse(ctx, w, 0, http.Header{}, err, false)
+		return
+	}
+	fingerprint := digest(r.URL.RawQuery, string(body))
+	stored, err := h.IdempotencyStore.Reserve(ctx, key, fingerprint, ttl)
+	if err != nil {
+		h.sendResponse(ctx, w, 0, http.Header{}, err, false)
+		return
+	}
+	if stored != nil {
+		switch {
+		case stored.Fingerprint != fingerprint:
+			h.sendResponse(ctx, w, 0, http.Header{}, ErrIdempotencyKeyReused, false)
+		case stored.Status == 0:
+			h.sendResponse(ctx, w, 0, http.Header{}, ErrIdempotentRequestInProgress, false)
+		default:
+			for k, v := range stored.Header {
+				w.Header()[k] = v
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+		}
+		return
+	}
+	rec := &responseRecorder{ResponseWriter: w}
+	next(rec)
+	if rec.status == 0 || rec.status >= 500 {
+		// Server errors are not stored so the request can be retried.
+		err = h.IdempotencyStore.Release(ctx, key)
+	} else {
+		err = h.IdempotencyStore.Save(ctx, key, &IdempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			Header:      w.Header().Clone(),
+			Body:        rec.body.Bytes(),
+		}, ttl)
+	}
+	if err != nil {
+		logErrorf(ctx, "Cannot store idempotent response: %v", err)
+	}
+}
+
+// idempotencyKey returns the store key of r, combining its Idempotency-Key
+// header with its method, path and principal.
+func idempotencyKey(ctx context.Context, r *http.Request) (string, error) {
+	var principal []byte
+	if p, ok := resource.PrincipalFromContext(ctx); ok {
+		var err error
+		if principal, err = json.Marshal(p); err != nil {
+			return "", err
+		}
+	}
+	return digest(r.Header.Get("Idempotency-Key"), r.Method, r.URL.Path, string(principal)), nil
+}
+
+// digest returns the hex encoded SHA-256 sum of the parts.
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		// Prefix each part with its length so parts can't collide.
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder is a http.ResponseWriter recording the status and body
+// written to the wrapped writer.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerIdempotency(t *testing.T) {
+	s := mem.NewHandler()
+	index := resource.NewIndex()
+	index.Bind("foo", schema.Schema{Fields: schema.Fields{
+		"id":  schema.IDField,
+		"foo": {},
+	}}, s, resource.DefaultConf)
+	h, err := NewHandler(index)
+	if !assert.NoError(t, err) {
+		return
+	}
+	store := NewMemoryIdempotencyStore()
+	h.IdempotencyStore = store
+	serve := func(method, body, key string, p resource.Principal) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "/foo", bytes.NewBufferString(body))
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		if p != nil {
+			r = r.WithContext(resource.NewContextWithPrincipal(r.Context(), p))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	count := func() int {
+		l, _ := s.Find(context.Background(), &query.Query{})
+		return len(l.Items)
+	}
+
+	w1 := serve("POST", `{"foo": "bar"}`, "a", nil)
+	assert.Equal(t, http.StatusCreated, w1.Code)
+	assert.Equal(t, "", w1.Header().Get("Idempotent-Replayed"))
+	w2 := serve("POST", `{"foo": "bar"}`, "a", nil)
+	assert.Equal(t, http.StatusCreated, w2.Code)
+	assert.Equal(t, "true", w2.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, w1.Header().Get("Etag"), w2.Header().Get("Etag"))
+	assert.Equal(t, w1.Body.String(), w2.Body.String())
+	assert.Equal(t, 1, count())
+
+	// Reusing the key with another body is rejected.
+	w := serve("POST", `{"foo": "baz"}`, "a", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, count())
+
+	// Keys are scoped by principal.
+	w = serve("POST", `{"foo": "bar"}`, "a", resource.Principal{"id": "john"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "", w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 2, count())
+
+	// Requests without key are not deduplicated.
+	serve("POST", `{"foo": "bar"}`, "", nil)
+	serve("POST", `{"foo": "bar"}`, "", nil)
+	assert.Equal(t, 4, count())
+
+	// Client errors are stored too.
+	w1 = serve("POST", `{"bar": "baz"}`, "b", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w1.Code)
+	w2 = serve("POST", `{"bar": "baz"}`, "b", nil)
+	assert.Equal(t, "true", w2.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, w1.Body.String(), w2.Body.String())
+
+	// Retrying while the first request is processed is a conflict.
+	r := httptest.NewRequest("POST", "/foo", nil)
+	r.Header.Set("Idempotency-Key", "c")
+	key, _ := idempotencyKey(context.Background(), r)
+	store.Reserve(context.Background(), key, digest("", `{"foo": "bar"}`), time.Minute)
+	w = serve("POST", `{"foo": "bar"}`, "c", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 4, count())
+
+	// Bodies larger than the limit are rejected.
+	h.IdempotencyMaxBodySize = 10
+	w = serve("POST", `{"foo": "bar"}`, "d", nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, 4, count())
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryIdempotencyStore()
+	res, err := s.Reserve(ctx, "a", "f", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	res, _ = s.Reserve(ctx, "a", "g", time.Minute)
+	assert.Equal(t, &IdempotentResponse{Fingerprint: "f"}, res)
+
+	stored := &IdempotentResponse{Fingerprint: "f", Status: 201}
+	assert.NoError(t, s.Save(ctx, "a", stored, time.Minute))
+	res, _ = s.Reserve(ctx, "a", "f", time.Minute)
+	assert.Equal(t, stored, res)
+
+	assert.NoError(t, s.Release(ctx, "a"))
+	res, _ = s.Reserve(ctx, "a", "f", -time.Second)
+	assert.Nil(t, res)
+	// The reservation is expired.
+	res, _ = s.Reserve(ctx, "a", "f", time.Minute)
+	assert.Nil(t, res)
+
+	// Expired entries are purged at most once per interval.
+	s.Reserve(ctx, "b", "f", -time.Second)
+	s.Reserve(ctx, "c", "f", time.Minute)
+	assert.Len(t, s.entries, 3)
+	s.purgeAt = time.Time{}
+	s.Reserve(ctx, "d", "f", time.Minute)
+	assert.Len(t, s.entries, 3)
+	assert.NotContains(t, s.entries, "b")
+}
@@ -0,0 +1,132 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestQueryLimits(t *testing.T) {
+	initWithDefault := func(defaultLimit int) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.Background(), []*resource.Item{
+				{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "name": "a"}},
+				{ID: "2", ETag: "b", Payload: map[string]interface{}{"id": "2", "name": "b"}},
+				{ID: "3", ETag: "c", Payload: map[string]interface{}{"id": "3", "name": "c"}},
+			})
+			index := resource.NewIndex()
+			index.Bind("foo", schema.Schema{Fields: schema.Fields{
+				"id":   {Sortable: true},
+				"name": {Filterable: true},
+			}}, s, resource.Conf{
+				AllowedModes:           resource.ReadWrite,
+				PaginationDefaultLimit: defaultLimit,
+				Limits: resource.QueryLimits{
+					MaxPredicateDepth:   2,
+					MaxPredicateClauses: 3,
+					AllowedOperators:    map[string][]string{"name": {"$eq", "$in"}},
+					MaxLimit:            2,
+					MaxSkip:             2,
+				},
+			})
+			return &requestTestVars{Index: index}
+		}
+	}
+	init := initWithDefault(0)
+	tests := map[string]requestTest{
+		"DefaultLimit": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=id&filter={name:{$in:["a","b","c"]}}`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[
+				{"id": "1", "name": "a", "_etag": "a"},
+				{"id": "2", "name": "b", "_etag": "b"}
+			]`,
+		},
+		"DefaultLimit/Capped": {
+			Init: initWithDefault(20),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=id`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[
+				{"id": "1", "name": "a", "_etag": "a"},
+				{"id": "2", "name": "b", "_etag": "b"}
+			]`,
+		},
+		"Limit": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?limit=3&skip=3`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"limit": ["exceeds the maximum of 2"],
+					"skip": ["exceeds the maximum of 2"]
+				}
+			}`,
+		},
+		"Page": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?page=3`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"page": ["offset exceeds the maximum of 2"]}
+			}`,
+		},
+		"Operator": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={name:{$regex:"^a"}}`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"filter": ["name: operator $regex not allowed"]}
+			}`,
+		},
+		"Depth": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={$or:[{$and:[{name:"a"}]}]}`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"filter": ["exceeds the maximum depth of 2"]}
+			}`,
+		},
+		"Clauses": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={name:"a"}&filter={$or:[{name:"b"},{name:"c"},{name:"d"}]}`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"filter": ["exceeds the maximum of 3 clauses"]}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// aggregateFuncs lists the accumulator functions accepted as query-string
+// parameters by the _aggregate action, in the order they are computed.
+var aggregateFuncs = []query.AccumulatorFunc{
+	query.AccumulatorSum,
+	query.AccumulatorAvg,
+	query.AccumulatorMin,
+	query.AccumulatorMax,
+}
+
+// listAggregate handles GET and HEAD requests on the _aggregate URL of a
+// resource.
+func listAggregate(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	a, e := getAggregation(route)
+	if e != nil {
+		return e.Code, nil, e
+	}
+	groups, err := route.Resource().Aggregate(ctx, q, a)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	headers = http.Header{}
+	headers.Set("X-Total", strconv.Itoa(len(groups)))
+	if r.Method == http.MethodHead {
+		return 200, headers, nil
+	}
+	return 200, headers, groups
+}
+
+// getAggregation parses the aggregation requested thru the group, having and
+// accumulator function (sum, avg, min and max) query-string parameters. The
+// count of items is always computed.
+func getAggregation(route *RouteMatch) (*query.Aggregation, *Error) {
+	qp := queryParser{rsc: route.Resource()}
+	a := &query.Aggregation{
+		Group:        splitParams(route.Params, "group"),
+		Accumulators: []query.Accumulator{{Func: query.AccumulatorCount}},
+	}
+	for _, f := range aggregateFuncs {
+		for _, field := range splitParams(route.Params, string(f)) {
+			a.Accumulators = append(a.Accumulators, query.Accumulator{Func: f, Field: field})
+		}
+	}
+	if having := route.Params.Get("having"); having != "" {
+		p, err := query.ParsePredicate(having)
+		if err != nil {
+			qp.addIssue("having", err.Error())
+			return nil, &Error{422, "URL parameters contain error(s)", qp.issues}
+		}
+		a.Having = p
+	}
+	if err := a.Validate(qp.rsc.Validator()); err != nil {
+		qp.addIssue("aggregate", err.Error())
+	}
+	if _, e := qp.results(); e != nil {
+		return nil, e
+	}
+	return a, nil
+}
+
+// splitParams returns the comma separated values of the name query-string
+// parameters.
+func splitParams(params url.Values, name string) []string {
+	var values []string
+	for _, param := range params[name] {
+		for _, v := range strings.Split(param, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,91 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestAggregate(t *testing.T) {
+	init := func(modes ...resource.Mode) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.Background(), []*resource.Item{
+				{ID: "1", Payload: map[string]interface{}{"id": "1", "status": "paid", "amount": 10}},
+				{ID: "2", Payload: map[string]interface{}{"id": "2", "status": "paid", "amount": 20}},
+				{ID: "3", Payload: map[string]interface{}{"id": "3", "status": "draft", "amount": 5}},
+			})
+			index := resource.NewIndex()
+			index.Bind("orders", schema.Schema{Fields: schema.Fields{
+				"id":     {},
+				"status": {Filterable: true},
+				"amount": {Filterable: true, Validator: &schema.Integer{}},
+				"note":   {},
+			}}, s, resource.Conf{AllowedModes: modes})
+			return &requestTestVars{Index: index}
+		}
+	}
+	tests := map[string]requestTest{
+		"Group": {
+			Init: init(append(resource.ReadWrite, resource.Aggregate)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/orders/_aggregate?group=status&sum=amount&max=amount", nil)
+			},
+			ResponseCode:   http.StatusOK,
+			ResponseHeader: http.Header{"X-Total": []string{"2"}},
+			ResponseBody: `[
+				{"status": "draft", "count": 1, "sum_amount": 5, "max_amount": 5},
+				{"status": "paid", "count": 2, "sum_amount": 30, "max_amount": 20}
+			]`,
+		},
+		"Filter": {
+			Init: init(append(resource.ReadWrite, resource.Aggregate)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/orders/_aggregate?avg=amount&filter={amount:{$gt:5}}`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"count": 2, "avg_amount": 15}]`,
+		},
+		"Having": {
+			Init: init(append(resource.ReadWrite, resource.Aggregate)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/orders/_aggregate?group=status&having={count:{$gt:1}}`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"status": "paid", "count": 2}]`,
+		},
+		"Invalid": {
+			Init: init(append(resource.ReadWrite, resource.Aggregate)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/orders/_aggregate?group=note", nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"aggregate": ["note: field is not filterable"]}
+			}`,
+		},
+		"NotAllowed": {
+			Init: func() *requestTestVars {
+				vars := init(resource.ReadWrite...)()
+				rsrc, _ := vars.Index.GetResource("orders", nil)
+				rsrc.Insert(context.Background(), []*resource.Item{{ID: "_aggregate", Payload: map[string]interface{}{"id": "_aggregate"}}})
+				return vars
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/orders/_aggregate", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "_aggregate"}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
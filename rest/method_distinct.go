@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// listDistinct handles GET and HEAD requests on the _distinct/{field} URL of a
+// resource.
+func listDistinct(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	field := route.ActionTarget
+	if field == "" {
+		return ErrNotFound.Code, nil, ErrNotFound
+	}
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	if f := rsrc.Validator().GetField(field); f == nil {
+		e = &Error{422, "URL parameters contain error(s)", map[string][]interface{}{"field": {"unknown field"}}}
+	} else if !f.Filterable {
+		e = &Error{422, "URL parameters contain error(s)", map[string][]interface{}{"field": {"field is not filterable"}}}
+	}
+	if e != nil {
+		return e.Code, nil, e
+	}
+	values, err := rsrc.Distinct(ctx, q, field)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	headers = http.Header{}
+	headers.Set("X-Total", strconv.Itoa(len(values)))
+	if r.Method == http.MethodHead {
+		return 200, headers, nil
+	}
+	return 200, headers, values
+}
@@ -0,0 +1,114 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestDistinct(t *testing.T) {
+	init := func(modes ...resource.Mode) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.Background(), []*resource.Item{
+				{ID: "1", Payload: map[string]interface{}{"id": "1", "country": "fr", "age": 20, "tags": []interface{}{"a", "b"}}},
+				{ID: "2", Payload: map[string]interface{}{"id": "2", "country": "us", "age": 30, "tags": []interface{}{"b"}}},
+				{ID: "3", Payload: map[string]interface{}{"id": "3", "country": "us", "age": 40}},
+				{ID: "4", Payload: map[string]interface{}{"id": "4", "age": 50}},
+				{ID: "_distinct", Payload: map[string]interface{}{"id": "_distinct"}},
+			})
+			index := resource.NewIndex()
+			index.Bind("users", schema.Schema{Fields: schema.Fields{
+				"id":      {},
+				"country": {Filterable: true},
+				"age":     {Filterable: true, Validator: &schema.Integer{}},
+				"tags":    {Filterable: true},
+				"name":    {},
+			}}, s, resource.Conf{AllowedModes: modes})
+			return &requestTestVars{Index: index}
+		}
+	}
+	tests := map[string]requestTest{
+		"Field": {
+			Init: init(append(resource.ReadWrite, resource.Distinct)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct/country", nil)
+			},
+			ResponseCode:   http.StatusOK,
+			ResponseHeader: http.Header{"X-Total": []string{"2"}},
+			ResponseBody:   `[{"value": "us", "count": 2}, {"value": "fr", "count": 1}]`,
+		},
+		"Array": {
+			Init: init(append(resource.ReadWrite, resource.Distinct)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct/tags", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"value": "b", "count": 2}, {"value": "a", "count": 1}]`,
+		},
+		"Filter": {
+			Init: init(append(resource.ReadWrite, resource.Distinct)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/users/_distinct/country?filter={age:{$gt:25}}&limit=1`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"value": "us", "count": 2}]`,
+		},
+		"NotFilterable": {
+			Init: init(append(resource.ReadWrite, resource.Distinct)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct/name", nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"field": ["field is not filterable"]}
+			}`,
+		},
+		"UnknownField": {
+			Init: init(append(resource.ReadWrite, resource.Distinct)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct/foo", nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"field": ["unknown field"]}
+			}`,
+		},
+		"MissingField": {
+			Init: init(append(resource.ReadWrite, resource.Distinct)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Not Found"}`,
+		},
+		"NotAllowed": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "_distinct"}`,
+		},
+		"NotAllowed/Field": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_distinct/country", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Resource Not Found"}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
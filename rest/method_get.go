@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/rs/rest-layer/resource"
 )
@@ -24,10 +25,18 @@ func listGet(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 			return 422, nil, &Error{422, "Cannot use `total' parameter: denied by configuration", nil}
 		}
 	}
+	ex, explain := newExplanation(ctx, route)
+	if explain {
+		ctx = resource.NewContextWithExplanation(ctx, ex)
+	}
+	start := time.Now()
 	q, e := route.Query()
 	if e != nil {
 		return e.Code, nil, e
 	}
+	if explain {
+		ex.AddStage("parse", start)
+	}
 	var list *resource.ItemList
 	var err error
 	if forceTotal {
@@ -39,6 +48,9 @@ func listGet(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 		e = NewError(err)
 		return e.Code, nil, e
 	}
+	if explain {
+		return 200, nil, explanationBody(ex, list)
+	}
 	if win := q.Window; win != nil && win.Offset > 0 {
 		list.Offset = win.Offset
 	}
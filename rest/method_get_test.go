@@ -344,6 +344,85 @@ func TestGetListFilter(t *testing.T) {
 	}
 }
 
+func TestGetListSort(t *testing.T) {
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.TODO(), []*resource.Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1", "age": 20, "name": "b", "tags": []interface{}{"x"}}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2", "name": "A", "tags": []interface{}{"z"}}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3", "age": 30, "name": "c", "tags": []interface{}{"Y"}}},
+		})
+
+		idx := resource.NewIndex()
+		idx.Bind("foo", schema.Schema{
+			Fields: schema.Fields{
+				"id":   {},
+				"age":  {Sortable: true, Validator: &schema.Integer{}},
+				"name": {Sortable: true, Validator: &schema.String{}},
+				"tags": {Validator: &schema.Array{Values: schema.Field{Sortable: true, Validator: &schema.String{}}}},
+			},
+		}, s, resource.DefaultConf)
+
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"foo": s},
+		}
+	}
+
+	tests := map[string]requestTest{
+		`sort:nulls`: {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=-age&fields=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id":"3"},{"id":"1"},{"id":"2"}]`,
+		},
+		`sort:nullsfirst`: {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=-age:nullsfirst&fields=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id":"2"},{"id":"3"},{"id":"1"}]`,
+		},
+		`sort:nocase`: {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=name:nocase&fields=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id":"2"},{"id":"1"},{"id":"3"}]`,
+		},
+		`sort:nested`: {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=-tags.0:nocase&fields=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id":"2"},{"id":"3"},{"id":"1"}]`,
+		},
+		`sort:invalid-modifier`: {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=age:nullsmiddle`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"sort": ["age: unknown sort modifier \"nullsmiddle\""]
+				}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
 func TestGetListArray(t *testing.T) {
 	sharedInit := func() *requestTestVars {
 		s := mem.NewHandler()
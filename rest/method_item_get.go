@@ -10,6 +10,9 @@ import (
 
 // itemGet handles GET and HEAD resquests on an item URL.
 func itemGet(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	if at := route.Params.Get("at"); at != "" && route.Resource().Conf().History != nil {
+		return itemGetAt(ctx, r, route, at)
+	}
 	q, e := route.Query()
 	if e != nil {
 		return e.Code, nil, e
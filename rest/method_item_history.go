@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+var errRevisionNotFound = &Error{http.StatusNotFound, "Revision Not Found", nil}
+
+// itemHistory handles GET and HEAD requests on the _history URL of an item.
+func itemHistory(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	h, e := getHistory(ctx, route, q)
+	if e != nil {
+		return e.Code, nil, e
+	}
+	list := &resource.ItemList{Total: len(h), Items: make([]*resource.Item, 0, len(h))}
+	for _, rev := range h {
+		payload, err := q.Projection.Eval(ctx, rev.Payload, restResource{rsrc})
+		if err != nil {
+			e = NewError(err)
+			return e.Code, nil, e
+		}
+		d := map[string]interface{}{
+			"op":      rev.Op,
+			"time":    rev.Time,
+			"payload": payload,
+		}
+		if rev.Actor != nil {
+			d["actor"] = rev.Actor
+		}
+		list.Items = append(list.Items, &resource.Item{
+			ID:      rev.ItemID,
+			ETag:    rev.ETag,
+			Updated: rev.Time,
+			Payload: d,
+		})
+	}
+	return 200, nil, list
+}
+
+// itemGetAt handles GET and HEAD requests on an item URL with the at
+// query-string parameter, returning the item as it was at the requested
+// revision.
+func itemGetAt(ctx context.Context, r *http.Request, route *RouteMatch, at string) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	rev, e := getRevision(ctx, route, q, at)
+	if e != nil {
+		return e.Code, nil, e
+	}
+	if rev.Op == resource.RevisionDelete {
+		return ErrNotFound.Code, nil, ErrNotFound
+	}
+	payload, err := q.Projection.Eval(ctx, rev.Payload, restResource{rsrc})
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	return 200, nil, &resource.Item{
+		ID:      rev.ItemID,
+		ETag:    rev.ETag,
+		Updated: rev.Time,
+		Payload: payload,
+	}
+}
+
+// getHistory returns the revisions of the item targeted by route matching the
+// lookup of q.
+//
+// The item is first looked up the same way as an item GET, so the hooks and
+// restrictions of reads apply to its history. As the history of deleted items
+// remains available, the item may not be found if its last revision is a
+// deletion.
+func getHistory(ctx context.Context, route *RouteMatch, q *query.Query) (resource.History, *Error) {
+	rsrc := route.Resource()
+	lq := *q
+	lq.Window = &query.Window{Limit: 1}
+	list, err := rsrc.Find(resource.NewContextWithDeletedFilter(ctx, resource.IncludeDeleted), &lq)
+	if err != nil {
+		return nil, NewError(err)
+	}
+	h, err := rsrc.History(ctx, route.ResourceID())
+	if err != nil {
+		return nil, NewError(err)
+	}
+	if len(list.Items) == 0 && h[len(h)-1].Op != resource.RevisionDelete {
+		return nil, ErrNotFound
+	}
+	res := make(resource.History, 0, len(h))
+	for _, rev := range h {
+		if q.Predicate.Match(rev.Payload) {
+			res = append(res, rev)
+		}
+	}
+	if len(res) == 0 {
+		return nil, ErrNotFound
+	}
+	return res, nil
+}
+
+// getRevision returns the revision of the item targeted by route selected by
+// at, either a RFC3339 time or an ETag.
+func getRevision(ctx context.Context, route *RouteMatch, q *query.Query, at string) (*resource.Revision, *Error) {
+	h, e := getHistory(ctx, route, q)
+	if e != nil {
+		return nil, e
+	}
+	var rev *resource.Revision
+	if t, err := time.Parse(time.RFC3339Nano, at); err == nil {
+		rev = h.At(t)
+	} else {
+		rev = h.ETag(at)
+	}
+	if rev == nil {
+		return nil, errRevisionNotFound
+	}
+	return rev, nil
+}
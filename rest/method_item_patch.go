@@ -89,7 +89,8 @@ func itemPatch(ctx context.Context, r *http.Request, route *RouteMatch) (status
 	}
 	doc, errs := rsrc.Validator().Validate(changes, base)
 	if len(errs) > 0 {
-		return 422, nil, &Error{422, "Document contains error(s)", errs}
+		e := newDocumentError(errs)
+		return e.Code, nil, e
 	}
 	if id, found := doc["id"]; found && id != original.ID {
 		return 422, nil, &Error{422, "Cannot change document ID", nil}
@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// itemPurge handles DELETE requests on the _purge URL of an item.
+func itemPurge(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	q.Window = &query.Window{Limit: 1}
+	l, err := rsrc.Find(resource.NewContextWithDeletedFilter(ctx, resource.IncludeDeleted), q)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	if len(l.Items) == 0 {
+		return ErrNotFound.Code, nil, ErrNotFound
+	}
+	original := l.Items[0]
+	// If-Match / If-Unmodified-Since handling.
+	if err := checkIntegrityRequest(r, original); err != nil {
+		return err.Code, nil, err
+	}
+	if err := rsrc.Purge(ctx, original); err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	return 204, nil, nil
+}
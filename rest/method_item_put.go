@@ -70,7 +70,8 @@ func itemPut(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 	}
 	doc, errs := rsrc.Validator().Validate(changes, base)
 	if len(errs) > 0 {
-		return 422, nil, &Error{422, "Document contains error(s)", errs}
+		e := newDocumentError(errs)
+		return e.Code, nil, e
 	}
 	if original != nil {
 		if id, found := doc["id"]; found && id != original.ID {
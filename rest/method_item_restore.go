@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// itemRestore handles POST requests on the _restore URL of a soft deleted item.
+func itemRestore(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	q.Window = &query.Window{Limit: 1}
+	l, err := rsrc.Find(resource.NewContextWithDeletedFilter(ctx, resource.OnlyDeleted), q)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	if len(l.Items) == 0 {
+		return ErrNotFound.Code, nil, ErrNotFound
+	}
+	original := l.Items[0]
+	// If-Match / If-Unmodified-Since handling.
+	if err := checkIntegrityRequest(r, original); err != nil {
+		return err.Code, nil, err
+	}
+	item, err := rsrc.Restore(ctx, original)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	// Evaluate projection so response gets the same format as read requests.
+	item.Payload, err = q.Projection.Eval(ctx, item.Payload, restResource{rsrc})
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	return 200, nil, item
+}
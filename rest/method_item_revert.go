@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+)
+
+// itemRevert handles POST requests on the _revert URL of an item. The item is
+// replaced by the payload of the revision selected by the at query-string
+// parameter, going through the same validation as PUT.
+func itemRevert(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	at := route.Params.Get("at")
+	if at == "" {
+		return 422, nil, &Error{422, "URL parameters contain error(s)", map[string][]interface{}{
+			"at": {"required"},
+		}}
+	}
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	q.Window = &query.Window{Limit: 1}
+	l, err := rsrc.Find(ctx, q)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	if len(l.Items) == 0 {
+		return ErrNotFound.Code, nil, ErrNotFound
+	}
+	original := l.Items[0]
+	// If-Match / If-Unmodified-Since handling.
+	if err := checkIntegrityRequest(r, original); err != nil {
+		return err.Code, nil, err
+	}
+	rev, e := getRevision(ctx, route, q, at)
+	if e != nil {
+		return e.Code, nil, e
+	}
+	if rev.Op == resource.RevisionDelete {
+		return errRevisionNotFound.Code, nil, errRevisionNotFound
+	}
+	// Stored values of read-only fields can't go through validation again, so
+	// they keep their current value. Hidden fields are left out so Prepare
+	// keeps their current value too, as stored values of hidden fields (i.e.:
+	// hashed passwords) would be altered by validation.
+	payload := make(map[string]interface{}, len(rev.Payload))
+	for k, v := range rev.Payload {
+		payload[k] = v
+	}
+	for name, def := range rsrc.Schema().Fields {
+		switch {
+		case def.ReadOnly:
+			if v, found := original.Payload[name]; found {
+				payload[name] = v
+			} else {
+				delete(payload, name)
+			}
+		case def.Hidden:
+			delete(payload, name)
+		}
+	}
+	changes, base := rsrc.Validator().Prepare(ctx, payload, &original.Payload, true)
+	// Append lookup fields to base payload so it isn't caught by ReadOnly
+	// (i.e.: contains id and parent resource refs if any).
+	for k, v := range route.ResourcePath.Values() {
+		base[k] = v
+		// Also, ensure there's no tombstone set on the field
+		if changes[k] == schema.Tombstone {
+			delete(changes, k)
+		}
+	}
+	doc, errs := rsrc.Validator().Validate(changes, base)
+	if len(errs) > 0 {
+		e := newDocumentError(errs)
+		return e.Code, nil, e
+	}
+	item, err := resource.NewItem(doc)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	if err = rsrc.Update(ctx, item, original); err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	// Evaluate projection so response gets the same format as read requests.
+	item.Payload, err = q.Projection.Eval(ctx, item.Payload, restResource{rsrc})
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	return 200, nil, item
+}
@@ -28,7 +28,8 @@ func listPost(ctx context.Context, r *http.Request, route *RouteMatch) (status i
 	}
 	doc, errs := rsrc.Validator().Validate(changes, base)
 	if len(errs) > 0 {
-		return 422, nil, &Error{422, "Document contains error(s)", errs}
+		e := newDocumentError(errs)
+		return e.Code, nil, e
 	}
 	item, err := resource.NewItem(doc)
 	if err != nil {
@@ -48,6 +48,53 @@ func TestHandlerPostList(t *testing.T) {
 				}
 			},
 		},
+		"ForbiddenField": {
+			Init: func() *requestTestVars {
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":  {},
+					"foo": {},
+					"bar": {Writable: func(ctx context.Context) bool { return false }},
+				}}, mem.NewHandler(), resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"foo": "baz", "bar": "baz"}`))
+			},
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: `{
+				"code": 403,
+				"message": "Document contains forbidden change(s)",
+				"issues": {
+					"bar": ["forbidden"]
+				}
+			}`,
+		},
+		"Unique": {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{
+					{ID: "1", Payload: map[string]interface{}{"id": "1", "foo": "bar"}},
+				})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":  {},
+					"foo": {Unique: true},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"id": "2", "foo": "bar"}`))
+			},
+			ResponseCode: http.StatusConflict,
+			ResponseBody: `{
+				"code": 409,
+				"message": "Conflict",
+				"issues": {
+					"foo": ["must be unique"]
+				}
+			}`,
+		},
 		"BadPayload": {
 			Init: func() *requestTestVars {
 				index := resource.NewIndex()
@@ -0,0 +1,23 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// listPurge handles DELETE requests on the _purge URL of a resource.
+func listPurge(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	total, err := route.Resource().PurgeAll(ctx, q)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	headers = http.Header{}
+	headers.Set("X-Total", strconv.Itoa(total))
+	return 204, headers, nil
+}
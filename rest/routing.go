@@ -24,13 +24,42 @@ type RouteMatch struct {
 	ResourcePath ResourcePath
 	// Params is the list of client provided parameters (thru query-string or alias).
 	Params url.Values
+	// Action is the operation requested through a reserved path component
+	// (i.e.: _aggregate, _distinct, or _restore, _purge, _history and _revert
+	// on resources with soft delete or history enabled), if any.
+	Action string
+	// ActionTarget is the path component following the action, if any (i.e.:
+	// the field of /resource/_distinct/field).
+	ActionTarget string
 }
 
+const (
+	// restoreAction is the path component used to restore a soft deleted
+	// item (/resource/id/_restore).
+	restoreAction = "_restore"
+	// purgeAction is the path component used to permanently delete soft
+	// deleted items (/resource/id/_purge or /resource/_purge).
+	purgeAction = "_purge"
+	// historyAction is the path component used to list the revisions of an
+	// item (/resource/id/_history).
+	historyAction = "_history"
+	// revertAction is the path component used to revert an item to one of its
+	// revisions (/resource/id/_revert).
+	revertAction = "_revert"
+	// aggregateAction is the path component used to compute aggregations on a
+	// collection (/resource/_aggregate).
+	aggregateAction = "_aggregate"
+	// distinctAction is the path component used to list the distinct values
+	// of a field on a collection (/resource/_distinct/field).
+	distinctAction = "_distinct"
+)
+
 type key int
 
 const (
 	routeKey key = iota
 	indexKey
+	explainKey
 )
 
 var routePool = sync.Pool{
@@ -97,6 +126,20 @@ func findRoute(path string, index resource.Index, route *RouteMatch) error {
 			var id string
 			id, path = nextPathComponent(path)
 
+			// Handle item actions (/resource/id/_restore, /resource/id/_purge,
+			// /resource/id/_history or /resource/id/_revert).
+			if comp, rest := nextPathComponent(path); rest == "" && isAction(rsrc, comp, true) {
+				route.Action = comp
+				return route.ResourcePath.append(rsrc, "id", id, name)
+			}
+
+			// Handle collection actions with a target (/resource/_distinct/field).
+			if comp, rest := nextPathComponent(path); rest == "" && comp != "" && id == distinctAction && isAction(rsrc, id, false) {
+				route.Action = id
+				route.ActionTarget = comp
+				return route.ResourcePath.append(rsrc, "", nil, name)
+			}
+
 			// Handle sub-resources (/resource1/id1/resource2/id2).
 			if len(path) >= 1 {
 				subPathComp, _ := nextPathComponent(path)
@@ -118,7 +161,10 @@ func findRoute(path string, index resource.Index, route *RouteMatch) error {
 			}
 
 			// Handle aliases (/resource/alias or /resource1/id1/resource2/alias).
-			if alias, found := rsrc.GetAlias(id); found {
+			if isAction(rsrc, id, false) {
+				// Handle actions on the collection (/resource/_purge).
+				route.Action = id
+			} else if alias, found := rsrc.GetAlias(id); found {
 				// Apply aliases query to the request.
 				for key, values := range alias {
 					for _, value := range values {
@@ -137,6 +183,25 @@ func findRoute(path string, index resource.Index, route *RouteMatch) error {
 	return errResourceNotFound
 }
 
+// isAction returns true if comp is the path component of an action enabled on
+// an item or on the collection of rsrc.
+func isAction(rsrc *resource.Resource, comp string, isItem bool) bool {
+	conf := rsrc.Conf()
+	switch comp {
+	case purgeAction:
+		return conf.SoftDeleteField != ""
+	case restoreAction:
+		return isItem && conf.SoftDeleteField != ""
+	case historyAction, revertAction:
+		return isItem && conf.History != nil
+	case aggregateAction:
+		return !isItem && conf.IsModeAllowed(resource.Aggregate)
+	case distinctAction:
+		return !isItem && conf.IsModeAllowed(resource.Distinct)
+	}
+	return false
+}
+
 // nextPathComponent returns the next path component and the remaining path
 //
 // Input: /comp1/comp2/comp3
@@ -216,6 +281,8 @@ func (r *RouteMatch) Query() (*query.Query, *Error) {
 func (r *RouteMatch) Release() {
 	r.Params = nil
 	r.Method = ""
+	r.Action = ""
+	r.ActionTarget = ""
 	r.ResourcePath.clear()
 	routePool.Put(r)
 }
@@ -248,6 +315,8 @@ func (qp *queryParser) parseProjection(params url.Values) {
 			qp.addIssue("fields", err.Error())
 		} else if err := p.Validate(qp.rsc.Validator()); err != nil {
 			qp.addIssue("fields", err.Error())
+		} else if err := qp.rsc.Conf().Limits.CheckProjection(p); err != nil {
+			qp.addIssue("fields", err.Error())
 		} else {
 			qp.q.Projection = p
 		}
@@ -256,16 +325,22 @@ func (qp *queryParser) parseProjection(params url.Values) {
 
 func (qp *queryParser) parsePredicate(params url.Values) {
 	if filters, found := params["filter"]; found {
-		// If several filter parameters are present, merge them using $and
+		// If several filter parameters are present, merge them using $and. The
+		// query limits apply to the merged filters.
+		var user query.Predicate
 		for _, filter := range filters {
 			if p, err := query.ParsePredicate(filter); err != nil {
 				qp.addIssue("filter", err.Error())
 			} else if err := p.Prepare(qp.rsc.Validator()); err != nil {
 				qp.addIssue("filter", err.Error())
 			} else {
-				qp.q.Predicate = append(qp.q.Predicate, p...)
+				user = append(user, p...)
 			}
 		}
+		if err := qp.rsc.Conf().Limits.CheckPredicate(user); err != nil {
+			qp.addIssue("filter", err.Error())
+		}
+		qp.q.Predicate = append(qp.q.Predicate, user...)
 	}
 }
 
@@ -282,15 +357,22 @@ func (qp *queryParser) parseSort(params url.Values) {
 }
 
 func (qp *queryParser) parseWindow(params url.Values, allowDefaultLimit bool) {
+	conf := qp.rsc.Conf()
 	limit := -1
 	if l, found, err := getUintParam(params, "limit"); found {
 		if err != nil {
 			qp.addIssue("limit", err.Error())
+		} else if err = conf.Limits.CheckLimit(l); err != nil {
+			qp.addIssue("limit", err.Error())
 		} else {
 			limit = l
 		}
 	} else if allowDefaultLimit {
-		if l := qp.rsc.Conf().PaginationDefaultLimit; l > 0 {
+		if l := conf.PaginationDefaultLimit; l > 0 {
+			limit = l
+		}
+		// The default limit can't exceed the maximum limit.
+		if l := conf.Limits.MaxLimit; l > 0 && (limit < 0 || limit > l) {
 			limit = l
 		}
 	}
@@ -298,6 +380,8 @@ func (qp *queryParser) parseWindow(params url.Values, allowDefaultLimit bool) {
 	if s, found, err := getUintParam(params, "skip"); found {
 		if err != nil {
 			qp.addIssue("skip", err.Error())
+		} else if err = conf.Limits.CheckSkip(s); err != nil {
+			qp.addIssue("skip", err.Error())
 		} else {
 			skip = s
 		}
@@ -315,4 +399,10 @@ func (qp *queryParser) parseWindow(params url.Values, allowDefaultLimit bool) {
 	}
 
 	qp.q.Window = query.Page(page, limit, skip)
+	if w := qp.q.Window; w != nil && w.Offset > skip {
+		// The offset resulting from the page is limited like the skip.
+		if err := conf.Limits.CheckSkip(w.Offset); err != nil {
+			qp.addIssue("page", "offset "+err.Error())
+		}
+	}
 }
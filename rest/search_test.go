@@ -0,0 +1,58 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestTextSearch(t *testing.T) {
+	init := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.Background(), []*resource.Item{
+			{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "title": "The quick fox", "body": "A fox"}},
+			{ID: "2", ETag: "b", Payload: map[string]interface{}{"id": "2", "title": "Foxes, the fox family", "body": "Fox, fox and fox"}},
+			{ID: "3", ETag: "c", Payload: map[string]interface{}{"id": "3", "title": "Dogs", "body": "No fox here"}},
+		})
+		index := resource.NewIndex()
+		index.Bind("posts", schema.Schema{Fields: schema.Fields{
+			"id":    {},
+			"title": {Searchable: true},
+			"body":  {},
+		}}, s, resource.DefaultConf)
+		return &requestTestVars{Index: index}
+	}
+	tests := map[string]requestTest{
+		"Score": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/posts?filter={$text:"fox foxes"}&sort=-_score`, nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[
+				{"id": "2", "title": "Foxes, the fox family", "body": "Fox, fox and fox", "_etag": "b"},
+				{"id": "1", "title": "The quick fox", "body": "A fox", "_etag": "a"}
+			]`,
+		},
+		"NotSearchable": {
+			Init: init,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/posts?filter={body:{$text:"fox"}}`, nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"filter": ["body: field is not searchable"]}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
@@ -0,0 +1,199 @@
+package rest_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/rest-layer/resource"
+	"github.com/rs/rest-layer/resource/testing/mem"
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftDelete(t *testing.T) {
+	deleted := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	init := func(modes ...resource.Mode) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.Background(), []*resource.Item{
+				{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "foo": "bar"}},
+				{ID: "2", ETag: "b", Payload: map[string]interface{}{"id": "2", "foo": "baz", "deleted": deleted}},
+			})
+			index := resource.NewIndex()
+			index.Bind("foo", schema.Schema{Fields: schema.Fields{
+				"id":      {},
+				"foo":     {Filterable: true},
+				"deleted": {ReadOnly: true},
+			}}, s, resource.Conf{AllowedModes: modes, SoftDeleteField: "deleted"})
+			return &requestTestVars{Index: index, Storers: map[string]resource.Storer{"foo": s}}
+		}
+	}
+	// stored returns the ids of the items in the storage, deleted or not.
+	stored := func(t *testing.T, vars *requestTestVars) map[interface{}]bool {
+		l, err := vars.Storers["foo"].Find(context.Background(), &query.Query{})
+		assert.NoError(t, err)
+		ids := map[interface{}]bool{}
+		for _, item := range l.Items {
+			_, deleted := item.Payload["deleted"]
+			ids[item.ID] = deleted
+		}
+		return ids
+	}
+	tests := map[string]requestTest{
+		"List": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"id": "1", "foo": "bar", "_etag": "a"}]`,
+		},
+		"List/Only": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?deleted=only", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"id": "2", "foo": "baz", "deleted": "2018-01-01T00:00:00Z", "_etag": "b"}]`,
+		},
+		"List/Invalid": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?deleted=all", nil)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"deleted": ["must be either include or only"]}
+			}`,
+		},
+		"Get/Deleted": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/2", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Not Found"}`,
+		},
+		"Get/Include": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/2?deleted=include", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "2", "foo": "baz", "deleted": "2018-01-01T00:00:00Z"}`,
+		},
+		"Delete": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/foo/1", nil)
+			},
+			ResponseCode: http.StatusNoContent,
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				assert.Equal(t, map[interface{}]bool{"1": true, "2": true}, stored(t, vars))
+			},
+		},
+		"Clear": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/foo", nil)
+			},
+			ResponseCode:   http.StatusNoContent,
+			ResponseHeader: http.Header{"X-Total": []string{"1"}},
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				assert.Equal(t, map[interface{}]bool{"1": true, "2": true}, stored(t, vars))
+			},
+		},
+		"Patch/DeletedField": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("PATCH", "/foo/1", bytes.NewBufferString(`{"deleted": "2018-01-01T00:00:00Z"}`))
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"deleted": ["read-only"]}
+			}`,
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				assert.Equal(t, map[interface{}]bool{"1": false, "2": true}, stored(t, vars))
+			},
+		},
+		"Restore": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/2/_restore", nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "2", "foo": "baz"}`,
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				assert.Equal(t, map[interface{}]bool{"1": false, "2": false}, stored(t, vars))
+			},
+		},
+		"Restore/NotDeleted": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/1/_restore", nil)
+			},
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: `{"code": 404, "message": "Not Found"}`,
+		},
+		"Restore/Denied": {
+			Init: init(resource.Read, resource.Delete),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/2/_restore", bytes.NewBufferString("{}"))
+			},
+			ResponseCode:   http.StatusMethodNotAllowed,
+			ResponseHeader: http.Header{"Allow": nil},
+			ResponseBody:   `{"code": 405, "message": "Invalid Method"}`,
+		},
+		"Purge": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/foo/1/_purge", nil)
+			},
+			ResponseCode: http.StatusNoContent,
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				assert.Equal(t, map[interface{}]bool{"2": true}, stored(t, vars))
+			},
+		},
+		"Purge/NotInReadWrite": {
+			Init: init(resource.ReadWrite...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/foo/2/_purge", nil)
+			},
+			ResponseCode:   http.StatusMethodNotAllowed,
+			ResponseHeader: http.Header{"Allow": nil},
+			ResponseBody:   `{"code": 405, "message": "Invalid Method"}`,
+		},
+		"Purge/InvalidMethod": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/1/_purge", nil)
+			},
+			ResponseCode:   http.StatusMethodNotAllowed,
+			ResponseHeader: http.Header{"Allow": []string{"DELETE"}},
+			ResponseBody:   `{"code": 405, "message": "Invalid Method"}`,
+		},
+		"PurgeAll": {
+			Init: init(append(resource.ReadWrite, resource.SoftDeleteModes...)...),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/foo/_purge", nil)
+			},
+			ResponseCode:   http.StatusNoContent,
+			ResponseHeader: http.Header{"X-Total": []string{"1"}},
+			ExtraTest: func(t *testing.T, vars *requestTestVars) {
+				assert.Equal(t, map[interface{}]bool{"1": false}, stored(t, vars))
+			},
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -81,6 +82,65 @@ func getAllowedMethodHandler(isItem bool, method string, conf resource.Conf) met
 	return nil
 }
 
+// getAllowedActionHandler returns the handler of the action requested through
+// a reserved path component if the method matches the action and the resource
+// configuration allows it.
+func getAllowedActionHandler(isItem bool, action, method string, conf resource.Conf) methodHandler {
+	switch {
+	case action == restoreAction && isItem && method == http.MethodPost && conf.IsModeAllowed(resource.Restore):
+		return itemRestore
+	case action == purgeAction && method == http.MethodDelete && conf.IsModeAllowed(resource.Purge):
+		if isItem {
+			return itemPurge
+		}
+		return listPurge
+	case action == historyAction && isItem && (method == http.MethodGet || method == http.MethodHead) && conf.IsModeAllowed(resource.Read):
+		return itemHistory
+	case action == revertAction && isItem && method == http.MethodPost && conf.IsModeAllowed(resource.Update):
+		return itemRevert
+	case action == aggregateAction && !isItem && (method == http.MethodGet || method == http.MethodHead) && conf.IsModeAllowed(resource.Aggregate):
+		return listAggregate
+	case action == distinctAction && !isItem && (method == http.MethodGet || method == http.MethodHead) && conf.IsModeAllowed(resource.Distinct):
+		return listDistinct
+	}
+	return nil
+}
+
+// setActionAllowHeader builds a Allow header for an action based on
+// the resource configuration.
+func setActionAllowHeader(headers http.Header, isItem bool, action string, conf resource.Conf) {
+	switch {
+	case action == restoreAction && isItem && conf.IsModeAllowed(resource.Restore):
+		headers.Set("Allow", "POST")
+	case action == purgeAction && conf.IsModeAllowed(resource.Purge):
+		headers.Set("Allow", "DELETE")
+	case action == historyAction && isItem && conf.IsModeAllowed(resource.Read):
+		headers.Set("Allow", "GET, HEAD")
+	case action == revertAction && isItem && conf.IsModeAllowed(resource.Update):
+		headers.Set("Allow", "POST")
+	case action == aggregateAction && !isItem && conf.IsModeAllowed(resource.Aggregate):
+		headers.Set("Allow", "GET, HEAD")
+	case action == distinctAction && !isItem && conf.IsModeAllowed(resource.Distinct):
+		headers.Set("Allow", "GET, HEAD")
+	}
+}
+
+// getDeletedFilterParam returns the filter of soft deleted items requested
+// with the deleted query-string parameter.
+func getDeletedFilterParam(params url.Values) (resource.DeletedFilter, *Error) {
+	switch params.Get("deleted") {
+	case "":
+		return resource.ExcludeDeleted, nil
+	case "include":
+		return resource.IncludeDeleted, nil
+	case "only":
+		return resource.OnlyDeleted, nil
+	}
+	return 0, &Error{422, "URL parameters contain error(s)", map[string][]interface{}{
+		"deleted": {"must be either include or only"},
+	}}
+}
+
 // setAllowHeader builds a Allow header based on the resource configuration.
 func setAllowHeader(headers http.Header, isItem bool, conf resource.Conf) {
 	methods := []string{}
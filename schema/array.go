@@ -83,12 +83,17 @@ func (v Array) Validate(value interface{}) (interface{}, error) {
 
 // GetField implements the FieldGetter interface. It will return
 // a Field if name corespond to a legal array index according to
-// parameters set on v.
+// parameters set on v. The index may be followed by the path of a sub-field
+// of the values, like in "0.name".
 func (v Array) GetField(name string) *Field {
-	if i, err := strconv.Atoi(name); err != nil {
+	index, remaining, wasSplit := splitFieldPath(name)
+	if i, err := strconv.Atoi(index); err != nil {
 		return nil
 	} else if i < 0 || (v.MaxLen > 0 && i >= v.MaxLen) {
 		return nil
 	}
+	if wasSplit {
+		return getSubField(&v.Values, remaining)
+	}
 	return &v.Values
 }
@@ -3,6 +3,7 @@
 package schema_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/rs/rest-layer/schema"
@@ -156,3 +157,24 @@ func TestArrayQueryValidator(t *testing.T) {
 		testCases[i].Run(t)
 	}
 }
+
+func TestArrayGetField(t *testing.T) {
+	f := schema.Field{Description: "foobar", Sortable: true}
+	a := schema.Array{
+		MaxLen: 2,
+		Values: schema.Field{Validator: &schema.Object{Schema: &schema.Schema{
+			Fields: schema.Fields{"baz": f},
+		}}},
+	}
+	if gf := a.GetField("1"); gf == nil || !reflect.DeepEqual(a.Values, *gf) {
+		t.Errorf("a.GetField(1) returned %#v, expected %#v", gf, a.Values)
+	}
+	if gf := a.GetField("1.baz"); gf == nil || !reflect.DeepEqual(f, *gf) {
+		t.Errorf("a.GetField(1.baz) returned %#v, expected %#v", gf, f)
+	}
+	for _, name := range []string{"2", "foo", "2.baz", "1.qux"} {
+		if gf := a.GetField(name); gf != nil {
+			t.Errorf("a.GetField(%s) returned %#v, expected nil", name, *gf)
+		}
+	}
+}
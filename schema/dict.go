@@ -71,12 +71,17 @@ func (v Dict) Validate(value interface{}) (interface{}, error) {
 	return dest, nil
 }
 
-// GetField implements the FieldGetter interface.
+// GetField implements the FieldGetter interface. The key may be followed by
+// the path of a sub-field of the values, like in "key.name".
 func (v Dict) GetField(name string) *Field {
+	key, remaining, wasSplit := splitFieldPath(name)
 	if v.KeysValidator != nil {
-		if _, err := v.KeysValidator.Validate(name); err != nil {
+		if _, err := v.KeysValidator.Validate(key); err != nil {
 			return nil
 		}
 	}
+	if wasSplit {
+		return getSubField(&v.Values, remaining)
+	}
 	return &v.Values
 }
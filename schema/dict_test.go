@@ -146,4 +146,21 @@ func TestDictGetField(t *testing.T) {
 			t.Errorf("d.GetField(invalid) returned %#v, expected nil", *gf)
 		}
 	})
+	t.Run("{Values=Object}.GetField(nested)", func(t *testing.T) {
+		d := schema.Dict{
+			KeysValidator: schema.String{Allowed: []string{"foo", "bar"}},
+			Values: schema.Field{Validator: &schema.Object{Schema: &schema.Schema{
+				Fields: schema.Fields{"baz": f},
+			}}},
+		}
+		if gf := d.GetField("foo.baz"); gf == nil || !reflect.DeepEqual(f, *gf) {
+			t.Errorf("d.GetField(nested) returned %#v, expected %#v", gf, f)
+		}
+		if gf := d.GetField("invalid.baz"); gf != nil {
+			t.Errorf("d.GetField(invalid.baz) returned %#v, expected nil", *gf)
+		}
+		if gf := d.GetField("foo.qux"); gf != nil {
+			t.Errorf("d.GetField(foo.qux) returned %#v, expected nil", *gf)
+		}
+	})
 }
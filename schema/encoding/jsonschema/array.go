@@ -16,18 +16,9 @@ func (v arrayBuilder) BuildJSONSchema() (map[string]interface{}, error) {
 	}
 
 	// Retrieve values validator JSON schema.
-	var valuesSchema map[string]interface{}
-	if v.Values.Validator != nil {
-		b, err := ValidatorBuilder(v.Values.Validator)
-		if err != nil {
-			return nil, err
-		}
-		valuesSchema, err = b.BuildJSONSchema()
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		valuesSchema = map[string]interface{}{}
+	valuesSchema, err := fieldSchema(v.Values)
+	if err != nil {
+		return nil, err
 	}
 	addFieldProperties(valuesSchema, v.Values)
 	if len(valuesSchema) > 0 {
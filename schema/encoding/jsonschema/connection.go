@@ -0,0 +1,19 @@
+package jsonschema
+
+import "github.com/rs/rest-layer/schema"
+
+type connectionBuilder schema.Connection
+
+func (v connectionBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	m := map[string]interface{}{
+		"type": "array",
+	}
+	if s, ok := validatorSchema(v.Validator); ok {
+		items := map[string]interface{}{}
+		if err := addSchemaProperties(items, s); err != nil {
+			return nil, err
+		}
+		m["items"] = items
+	}
+	return m, nil
+}
@@ -0,0 +1,45 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestConnectionValidatorEncode(t *testing.T) {
+	testCases := []encoderTestCase{
+		{
+			name: `Validator=nil`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"c": {
+						ReadOnly:  true,
+						Validator: &schema.Connection{Path: ".posts", Field: "user"},
+					},
+				},
+			},
+			customValidate: fieldValidator("c", `{"type": "array", "readOnly": true}`),
+		},
+		{
+			name: `Validator=Schema`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"c": {
+						Validator: &schema.Connection{
+							Path:      ".posts",
+							Field:     "user",
+							Validator: &simpleSchema,
+						},
+					},
+				},
+			},
+			customValidate: fieldValidator("c", `{
+				"type": "array",
+				"items": `+simpleSchemaJSON+`
+			}`),
+		},
+	}
+	for i := range testCases {
+		testCases[i].Run(t)
+	}
+}
@@ -0,0 +1,686 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+var (
+	// ErrUnsupportedKeyword is reported through a DecodeError when a JSON
+	// Schema keyword has no equivalent in the schema package.
+	ErrUnsupportedKeyword = errors.New("unsupported keyword")
+	// ErrUnsupportedValue is reported through a DecodeError when a keyword is
+	// known, but the value it holds can not be expressed with the schema
+	// package.
+	ErrUnsupportedValue = errors.New("unsupported value")
+	// ErrCircularReference is reported through a DecodeError when a $ref
+	// resolves, directly or indirectly, to itself.
+	ErrCircularReference = errors.New("circular reference")
+)
+
+// DecodeError is returned by the Decoder when a JSON Schema document can not be
+// converted into a schema.Schema.
+type DecodeError struct {
+	// Pointer is a JSON Pointer (RFC 6901) in URI fragment form, locating the
+	// offending keyword within the decoded document.
+	Pointer string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the built-in error interface.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Err)
+}
+
+// Decoder reads and decodes JSON Schema (draft-07) documents from an input
+// stream into schema.Schema instances. Only the keywords that can be expressed
+// with the FieldValidator types of the schema package are supported; a
+// DecodeError pointing to the offending keyword is returned for any other.
+//
+// The produced schema is not compiled. As for any schema.Schema used as a
+// standalone library, it is the caller's responsibility to call Compile before
+// using it for validation.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a new JSON Schema Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the next JSON Schema document from its input and stores the
+// equivalent schema definition in s. The root of the document must describe an
+// object.
+func (d *Decoder) Decode(s *schema.Schema) error {
+	var root interface{}
+	if err := json.NewDecoder(d.r).Decode(&root); err != nil {
+		return err
+	}
+	m, ok := root.(map[string]interface{})
+	if !ok {
+		return &DecodeError{Pointer: "#", Err: errors.New("not an object")}
+	}
+	dec := &decoder{root: m, resolving: map[string]bool{}}
+	if typ, found := m["type"]; found && typ != "object" {
+		return &DecodeError{Pointer: "#/type", Err: ErrUnsupportedValue}
+	}
+	if ref, found := m["$ref"].(string); found {
+		target, err := dec.resolve("#/$ref", ref)
+		if err != nil {
+			return err
+		}
+		defer delete(dec.resolving, ref)
+		return dec.schema(ref, target, s, true)
+	}
+	return dec.schema("#", m, s, true)
+}
+
+// decoder holds the state of a single Decode call.
+type decoder struct {
+	root      map[string]interface{}
+	resolving map[string]bool
+}
+
+var (
+	// annotationKeywords are accepted everywhere and ignored, as they don't
+	// influence validation.
+	annotationKeywords = []string{"$schema", "$id", "$comment", "title", "examples"}
+	// fieldKeywords are the keywords mapped to schema.Field properties rather
+	// than to a FieldValidator.
+	fieldKeywords = []string{"description", "readOnly", "default"}
+	// rootKeywords are keywords only allowed at the root of the document.
+	rootKeywords = []string{"definitions", "$defs"}
+)
+
+// schema decodes m into s, m being a JSON Schema of type object declaring
+// properties.
+func (d *decoder) schema(ptr string, m map[string]interface{}, s *schema.Schema, isRoot bool) error {
+	allowed := []string{"type", "description", "properties", "required", "additionalProperties", "minProperties", "maxProperties"}
+	if isRoot {
+		allowed = append(allowed, rootKeywords...)
+		allowed = append(allowed, "$ref")
+	} else {
+		allowed = append(allowed, fieldKeywords...)
+	}
+	if err := checkKeywords(ptr, m, allowed); err != nil {
+		return err
+	}
+	if desc, found := m["description"]; found {
+		str, ok := desc.(string)
+		if !ok {
+			return &DecodeError{Pointer: ptr + "/description", Err: errors.New("not a string")}
+		}
+		s.Description = str
+	}
+	if ap, found := m["additionalProperties"]; found && ap != false {
+		// A schema.Schema never allows fields that are not declared.
+		return &DecodeError{Pointer: ptr + "/additionalProperties", Err: ErrUnsupportedValue}
+	}
+	var err error
+	if s.MinLen, err = intKeyword(ptr, m, "minProperties"); err != nil {
+		return err
+	}
+	if s.MaxLen, err = intKeyword(ptr, m, "maxProperties"); err != nil {
+		return err
+	}
+	props := map[string]interface{}{}
+	if p, found := m["properties"]; found {
+		if props, found = p.(map[string]interface{}); !found {
+			return &DecodeError{Pointer: ptr + "/properties", Err: errors.New("not an object")}
+		}
+	}
+	s.Fields = make(schema.Fields, len(props))
+	for name, prop := range props {
+		f, err := d.field(ptr+"/properties/"+escapePointer(name), prop)
+		if err != nil {
+			return err
+		}
+		s.Fields[name] = f
+	}
+	if r, found := m["required"]; found {
+		required, ok := r.([]interface{})
+		if !ok {
+			return &DecodeError{Pointer: ptr + "/required", Err: errors.New("not an array")}
+		}
+		for i, name := range required {
+			rptr := ptr + "/required/" + strconv.Itoa(i)
+			str, ok := name.(string)
+			if !ok {
+				return &DecodeError{Pointer: rptr, Err: errors.New("not a string")}
+			}
+			f, found := s.Fields[str]
+			if !found {
+				return &DecodeError{Pointer: rptr, Err: fmt.Errorf("unknown property %q", str)}
+			}
+			f.Required = true
+			s.Fields[str] = f
+		}
+	}
+	return nil
+}
+
+// field decodes a property definition into a schema.Field.
+func (d *decoder) field(ptr string, v interface{}) (schema.Field, error) {
+	f := schema.Field{}
+	m, err := schemaMap(ptr, v)
+	if err != nil {
+		return f, err
+	}
+	if desc, found := m["description"]; found {
+		str, ok := desc.(string)
+		if !ok {
+			return f, &DecodeError{Pointer: ptr + "/description", Err: errors.New("not a string")}
+		}
+		f.Description = str
+	}
+	if ro, found := m["readOnly"]; found {
+		b, ok := ro.(bool)
+		if !ok {
+			return f, &DecodeError{Pointer: ptr + "/readOnly", Err: errors.New("not a boolean")}
+		}
+		f.ReadOnly = b
+	}
+	if f.Validator, err = d.validator(ptr, m); err != nil {
+		return f, err
+	}
+	if def, found := m["default"]; found {
+		f.Default = normalizeDefault(f.Validator, def)
+	}
+	return f, nil
+}
+
+// validator decodes a JSON Schema into a schema.FieldValidator. A nil
+// validator is returned for schemas accepting any value.
+func (d *decoder) validator(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if ref, found := m["$ref"]; found {
+		return d.ref(ptr, m, ref)
+	}
+	for _, kw := range []string{"anyOf", "allOf"} {
+		if _, found := m[kw]; found {
+			return d.combinator(ptr, m, kw)
+		}
+	}
+	if _, found := m["oneOf"]; found {
+		return d.ip(ptr, m)
+	}
+	typ, found := m["type"]
+	if !found {
+		if _, found := m["properties"]; found {
+			typ = "object"
+		} else {
+			return nil, checkKeywords(ptr, m, fieldKeywords)
+		}
+	}
+	switch t := typ.(type) {
+	case string:
+		return d.typed(ptr, m, t)
+	case []interface{}:
+		// Only nullable types, expressed as [<type>, "null"], are supported.
+		var types []string
+		for i := range t {
+			if s, ok := t[i].(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+		if len(types) != 1 || len(t) != 2 {
+			return nil, &DecodeError{Pointer: ptr + "/type", Err: ErrUnsupportedValue}
+		}
+		v, err := d.typed(ptr, m, types[0])
+		if err != nil {
+			return nil, err
+		}
+		return &schema.AnyOf{v, &schema.Null{}}, nil
+	default:
+		return nil, &DecodeError{Pointer: ptr + "/type", Err: errors.New("not a string or array")}
+	}
+}
+
+// typed decodes a JSON Schema with a single type into a FieldValidator.
+func (d *decoder) typed(ptr string, m map[string]interface{}, typ string) (schema.FieldValidator, error) {
+	switch typ {
+	case "null":
+		return &schema.Null{}, checkKeywords(ptr, m, []string{"type"}, fieldKeywords)
+	case "boolean":
+		return &schema.Bool{}, checkKeywords(ptr, m, []string{"type"}, fieldKeywords)
+	case "string":
+		return d.string(ptr, m)
+	case "integer":
+		return d.integer(ptr, m)
+	case "number":
+		return d.number(ptr, m)
+	case "array":
+		return d.array(ptr, m)
+	case "object":
+		if _, found := m["properties"]; found {
+			s := &schema.Schema{}
+			if err := d.schema(ptr, m, s, false); err != nil {
+				return nil, err
+			}
+			return &schema.Object{Schema: s}, nil
+		}
+		return d.dict(ptr, m)
+	default:
+		return nil, &DecodeError{Pointer: ptr + "/type", Err: ErrUnsupportedValue}
+	}
+}
+
+func (d *decoder) string(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if f, found := m["format"]; found {
+		return d.format(ptr, m, f)
+	}
+	if err := checkKeywords(ptr, m, []string{"type", "pattern", "enum", "minLength", "maxLength"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	v := &schema.String{}
+	if p, found := m["pattern"]; found {
+		str, ok := p.(string)
+		if !ok {
+			return nil, &DecodeError{Pointer: ptr + "/pattern", Err: errors.New("not a string")}
+		}
+		v.Regexp = str
+	}
+	if e, found := m["enum"]; found {
+		values, ok := e.([]interface{})
+		if !ok {
+			return nil, &DecodeError{Pointer: ptr + "/enum", Err: errors.New("not an array")}
+		}
+		for i := range values {
+			str, ok := values[i].(string)
+			if !ok {
+				return nil, &DecodeError{Pointer: ptr + "/enum/" + strconv.Itoa(i), Err: errors.New("not a string")}
+			}
+			v.Allowed = append(v.Allowed, str)
+		}
+	}
+	var err error
+	if v.MinLen, err = intKeyword(ptr, m, "minLength"); err != nil {
+		return nil, err
+	}
+	if v.MaxLen, err = intKeyword(ptr, m, "maxLength"); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// format decodes string schemas with a format keyword into the matching
+// specialized validator.
+func (d *decoder) format(ptr string, m map[string]interface{}, f interface{}) (schema.FieldValidator, error) {
+	switch f {
+	case "date-time":
+		return &schema.Time{}, checkKeywords(ptr, m, []string{"type", "format"}, fieldKeywords)
+	case "date":
+		return &schema.Time{TimeLayouts: []string{"2006-01-02"}}, checkKeywords(ptr, m, []string{"type", "format"}, fieldKeywords)
+	case "time":
+		return &schema.Time{TimeLayouts: []string{"15:04:05Z07:00"}}, checkKeywords(ptr, m, []string{"type", "format"}, fieldKeywords)
+	case "uri":
+		return &schema.URL{}, checkKeywords(ptr, m, []string{"type", "format"}, fieldKeywords)
+	case "uri-reference":
+		return &schema.URL{AllowRelative: true}, checkKeywords(ptr, m, []string{"type", "format"}, fieldKeywords)
+	case "ipv4", "ipv6":
+		return &schema.IP{}, checkKeywords(ptr, m, []string{"type", "format"}, fieldKeywords)
+	case "password":
+		if err := checkKeywords(ptr, m, []string{"type", "format", "minLength", "maxLength"}, fieldKeywords); err != nil {
+			return nil, err
+		}
+		v := &schema.Password{}
+		var err error
+		if v.MinLen, err = intKeyword(ptr, m, "minLength"); err != nil {
+			return nil, err
+		}
+		if v.MaxLen, err = intKeyword(ptr, m, "maxLength"); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		return nil, &DecodeError{Pointer: ptr + "/format", Err: ErrUnsupportedValue}
+	}
+}
+
+// ip decodes the oneOf construct produced by the Encoder for schema.IP, which
+// is the only supported use of oneOf.
+func (d *decoder) ip(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{"type", "oneOf"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	list, ok := m["oneOf"].([]interface{})
+	if !ok || len(list) != 2 || m["type"] != "string" {
+		return nil, &DecodeError{Pointer: ptr + "/oneOf", Err: ErrUnsupportedValue}
+	}
+	formats := map[interface{}]bool{}
+	for i := range list {
+		sm, ok := list[i].(map[string]interface{})
+		if !ok || len(sm) != 1 {
+			return nil, &DecodeError{Pointer: ptr + "/oneOf/" + strconv.Itoa(i), Err: ErrUnsupportedValue}
+		}
+		formats[sm["format"]] = true
+	}
+	if !formats["ipv4"] || !formats["ipv6"] {
+		return nil, &DecodeError{Pointer: ptr + "/oneOf", Err: ErrUnsupportedValue}
+	}
+	return &schema.IP{}, nil
+}
+
+func (d *decoder) integer(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{"type", "enum", "minimum", "maximum"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	v := &schema.Integer{}
+	if e, found := m["enum"]; found {
+		values, ok := e.([]interface{})
+		if !ok {
+			return nil, &DecodeError{Pointer: ptr + "/enum", Err: errors.New("not an array")}
+		}
+		for i := range values {
+			f, ok := values[i].(float64)
+			if !ok || f != math.Trunc(f) {
+				return nil, &DecodeError{Pointer: ptr + "/enum/" + strconv.Itoa(i), Err: errors.New("not an integer")}
+			}
+			v.Allowed = append(v.Allowed, int(f))
+		}
+	}
+	var err error
+	v.Boundaries, err = boundaries(ptr, m)
+	return v, err
+}
+
+func (d *decoder) number(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{"type", "enum", "minimum", "maximum"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	v := &schema.Float{}
+	if e, found := m["enum"]; found {
+		values, ok := e.([]interface{})
+		if !ok {
+			return nil, &DecodeError{Pointer: ptr + "/enum", Err: errors.New("not an array")}
+		}
+		for i := range values {
+			f, ok := values[i].(float64)
+			if !ok {
+				return nil, &DecodeError{Pointer: ptr + "/enum/" + strconv.Itoa(i), Err: errors.New("not a number")}
+			}
+			v.Allowed = append(v.Allowed, f)
+		}
+	}
+	var err error
+	v.Boundaries, err = boundaries(ptr, m)
+	return v, err
+}
+
+func (d *decoder) array(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{"type", "items", "minItems", "maxItems"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	v := &schema.Array{}
+	if items, found := m["items"]; found {
+		if _, isTuple := items.([]interface{}); isTuple {
+			return nil, &DecodeError{Pointer: ptr + "/items", Err: ErrUnsupportedValue}
+		}
+		f, err := d.field(ptr+"/items", items)
+		if err != nil {
+			return nil, err
+		}
+		v.Values = f
+	}
+	var err error
+	if v.MinLen, err = intKeyword(ptr, m, "minItems"); err != nil {
+		return nil, err
+	}
+	if v.MaxLen, err = intKeyword(ptr, m, "maxItems"); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// dict decodes an object schema without properties into a schema.Dict. Keys
+// can be restricted either with propertyNames, or with a single
+// patternProperties entry when additionalProperties is false, which is the
+// form produced by the Encoder.
+func (d *decoder) dict(ptr string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{"type", "additionalProperties", "patternProperties", "propertyNames", "minProperties", "maxProperties"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	v := &schema.Dict{}
+	var err error
+	if v.MinLen, err = intKeyword(ptr, m, "minProperties"); err != nil {
+		return nil, err
+	}
+	if v.MaxLen, err = intKeyword(ptr, m, "maxProperties"); err != nil {
+		return nil, err
+	}
+	if pn, found := m["propertyNames"]; found {
+		pm, err := schemaMap(ptr+"/propertyNames", pn)
+		if err != nil {
+			return nil, err
+		}
+		if v.KeysValidator, err = d.string(ptr+"/propertyNames", pm); err != nil {
+			return nil, err
+		}
+	}
+	ap, apFound := m["additionalProperties"]
+	if pp, found := m["patternProperties"]; found {
+		patterns, ok := pp.(map[string]interface{})
+		if !ok || len(patterns) != 1 || v.KeysValidator != nil {
+			return nil, &DecodeError{Pointer: ptr + "/patternProperties", Err: ErrUnsupportedValue}
+		}
+		if ap != false {
+			return nil, &DecodeError{Pointer: ptr + "/additionalProperties", Err: ErrUnsupportedValue}
+		}
+		for pattern, values := range patterns {
+			v.KeysValidator = &schema.String{Regexp: pattern}
+			if v.Values, err = d.field(ptr+"/patternProperties/"+escapePointer(pattern), values); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	}
+	if apFound {
+		if ap == false {
+			return nil, &DecodeError{Pointer: ptr + "/additionalProperties", Err: ErrUnsupportedValue}
+		}
+		if v.Values, err = d.field(ptr+"/additionalProperties", ap); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// combinator decodes anyOf and allOf keywords.
+func (d *decoder) combinator(ptr string, m map[string]interface{}, kw string) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{kw}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	list, ok := m[kw].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, &DecodeError{Pointer: ptr + "/" + kw, Err: errors.New("not a non-empty array")}
+	}
+	validators := make([]schema.FieldValidator, 0, len(list))
+	for i := range list {
+		sptr := ptr + "/" + kw + "/" + strconv.Itoa(i)
+		sm, err := schemaMap(sptr, list[i])
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.validator(sptr, sm)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, v)
+	}
+	if kw == "anyOf" {
+		v := schema.AnyOf(validators)
+		return &v, nil
+	}
+	v := schema.AllOf(validators)
+	return &v, nil
+}
+
+// ref decodes the schema referenced by a local $ref in place.
+func (d *decoder) ref(ptr string, m map[string]interface{}, ref interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(ptr, m, []string{"$ref"}, fieldKeywords); err != nil {
+		return nil, err
+	}
+	str, ok := ref.(string)
+	if !ok {
+		return nil, &DecodeError{Pointer: ptr + "/$ref", Err: errors.New("not a string")}
+	}
+	target, err := d.resolve(ptr+"/$ref", str)
+	if err != nil {
+		return nil, err
+	}
+	defer delete(d.resolving, str)
+	return d.validator(str, target)
+}
+
+// resolve returns the schema located by the local JSON Pointer ref, and marks
+// it as being resolved. The caller is responsible for clearing the mark.
+func (d *decoder) resolve(ptr, ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, &DecodeError{Pointer: ptr, Err: ErrUnsupportedValue}
+	}
+	if d.resolving[ref] {
+		return nil, &DecodeError{Pointer: ptr, Err: ErrCircularReference}
+	}
+	var cur interface{} = d.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		switch t := cur.(type) {
+		case map[string]interface{}:
+			cur = t[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(t) {
+				cur = nil
+			} else {
+				cur = t[i]
+			}
+		default:
+			cur = nil
+		}
+		if cur == nil {
+			return nil, &DecodeError{Pointer: ptr, Err: fmt.Errorf("can't resolve %q", ref)}
+		}
+	}
+	m, err := schemaMap(ptr, cur)
+	if err != nil {
+		return nil, err
+	}
+	d.resolving[ref] = true
+	return m, nil
+}
+
+// schemaMap returns v as a JSON Schema object. The boolean schema true is
+// accepted as an empty schema.
+func schemaMap(ptr string, v interface{}) (map[string]interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, nil
+	case bool:
+		if t {
+			return map[string]interface{}{}, nil
+		}
+		return nil, &DecodeError{Pointer: ptr, Err: ErrUnsupportedValue}
+	default:
+		return nil, &DecodeError{Pointer: ptr, Err: errors.New("not a schema")}
+	}
+}
+
+// checkKeywords returns a DecodeError for the first keyword of m (in
+// alphabetical order) that is not part of any of the allowed lists nor an
+// annotation keyword.
+func checkKeywords(ptr string, m map[string]interface{}, allowed ...[]string) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !contains(annotationKeywords, k) && !containsAny(allowed, k) {
+			return &DecodeError{Pointer: ptr + "/" + escapePointer(k), Err: ErrUnsupportedKeyword}
+		}
+	}
+	return nil
+}
+
+func containsAny(lists [][]string, s string) bool {
+	for _, l := range lists {
+		if contains(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(l []string, s string) bool {
+	for i := range l {
+		if l[i] == s {
+			return true
+		}
+	}
+	return false
+}
+
+// intKeyword returns the value of the non-negative integer keyword kw of m, or
+// 0 if not set.
+func intKeyword(ptr string, m map[string]interface{}, kw string) (int, error) {
+	v, found := m[kw]
+	if !found {
+		return 0, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, &DecodeError{Pointer: ptr + "/" + kw, Err: errors.New("not a non-negative integer")}
+	}
+	return int(f), nil
+}
+
+// boundaries decodes the minimum and maximum keywords of m. Nil is returned if
+// none is set.
+func boundaries(ptr string, m map[string]interface{}) (*schema.Boundaries, error) {
+	min, minFound := m["minimum"]
+	max, maxFound := m["maximum"]
+	if !minFound && !maxFound {
+		return nil, nil
+	}
+	b := &schema.Boundaries{Min: math.Inf(-1), Max: math.Inf(1)}
+	if minFound {
+		f, ok := min.(float64)
+		if !ok {
+			return nil, &DecodeError{Pointer: ptr + "/minimum", Err: errors.New("not a number")}
+		}
+		b.Min = f
+	}
+	if maxFound {
+		f, ok := max.(float64)
+		if !ok {
+			return nil, &DecodeError{Pointer: ptr + "/maximum", Err: errors.New("not a number")}
+		}
+		b.Max = f
+	}
+	return b, nil
+}
+
+// normalizeDefault converts JSON numbers used as default value of integer
+// fields to int, as expected by schema.Integer.
+func normalizeDefault(v schema.FieldValidator, value interface{}) interface{} {
+	if _, ok := v.(*schema.Integer); ok {
+		if f, ok := value.(float64); ok && f == math.Trunc(f) {
+			return int(f)
+		}
+	}
+	return value
+}
+
+// escapePointer escapes a JSON Pointer reference token.
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
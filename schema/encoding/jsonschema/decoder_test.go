@@ -0,0 +1,244 @@
+package jsonschema_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/encoding/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+// decoderTestCase is used to test the Decoder.Decode() function.
+type decoderTestCase struct {
+	name, input, expectError string
+	expect                   schema.Schema
+}
+
+func (tc *decoderTestCase) Run(t *testing.T) {
+	t.Run(tc.name, func(t *testing.T) {
+		t.Parallel()
+
+		var s schema.Schema
+		err := jsonschema.NewDecoder(strings.NewReader(tc.input)).Decode(&s)
+		if tc.expectError != "" {
+			assert.EqualError(t, err, tc.expectError)
+			return
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expect, s)
+	})
+}
+
+func TestDecoder(t *testing.T) {
+	testCases := []decoderTestCase{
+		{
+			name: "String",
+			input: `{
+				"type": "object",
+				"description": "a schema",
+				"additionalProperties": false,
+				"required": ["s"],
+				"properties": {
+					"s": {
+						"type": "string",
+						"description": "a string",
+						"pattern": "^[a-z]+$",
+						"enum": ["foo", "bar"],
+						"minLength": 1,
+						"maxLength": 3,
+						"readOnly": true,
+						"default": "foo"
+					}
+				}
+			}`,
+			expect: schema.Schema{
+				Description: "a schema",
+				Fields: schema.Fields{
+					"s": {
+						Description: "a string",
+						Required:    true,
+						ReadOnly:    true,
+						Default:     "foo",
+						Validator: &schema.String{
+							Regexp:  "^[a-z]+$",
+							Allowed: []string{"foo", "bar"},
+							MinLen:  1,
+							MaxLen:  3,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Integer,Float",
+			input: `{
+				"properties": {
+					"i": {"type": "integer", "enum": [1, 2], "minimum": 0, "default": 1},
+					"f": {"type": "number", "maximum": 1.5}
+				}
+			}`,
+			expect: schema.Schema{
+				Fields: schema.Fields{
+					"i": {
+						Default: 1,
+						Validator: &schema.Integer{
+							Allowed:    []int{1, 2},
+							Boundaries: &schema.Boundaries{Min: 0, Max: math.Inf(1)},
+						},
+					},
+					"f": {
+						Validator: &schema.Float{
+							Boundaries: &schema.Boundaries{Min: math.Inf(-1), Max: 1.5},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Array,Object,Dict",
+			input: `{
+				"properties": {
+					"a": {"type": "array", "minItems": 1, "items": {"type": "boolean"}},
+					"o": {
+						"type": "object",
+						"additionalProperties": false,
+						"properties": {"n": {"type": "null"}}
+					},
+					"d": {
+						"type": "object",
+						"additionalProperties": false,
+						"maxProperties": 2,
+						"patternProperties": {"^[a-z]+$": {"type": "string"}}
+					},
+					"any": {"description": "anything"}
+				}
+			}`,
+			expect: schema.Schema{
+				Fields: schema.Fields{
+					"a": {
+						Validator: &schema.Array{
+							MinLen: 1,
+							Values: schema.Field{Validator: &schema.Bool{}},
+						},
+					},
+					"o": {
+						Validator: &schema.Object{
+							Schema: &schema.Schema{
+								Fields: schema.Fields{
+									"n": {Validator: &schema.Null{}},
+								},
+							},
+						},
+					},
+					"d": {
+						Validator: &schema.Dict{
+							MaxLen:        2,
+							KeysValidator: &schema.String{Regexp: "^[a-z]+$"},
+							Values:        schema.Field{Validator: &schema.String{}},
+						},
+					},
+					"any": {Description: "anything"},
+				},
+			},
+		},
+		{
+			name: "AnyOf,AllOf,nullable,formats",
+			input: `{
+				"properties": {
+					"any": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+					"all": {"allOf": [{"type": "string", "format": "date-time"}]},
+					"null": {"type": ["string", "null"], "format": "uri"}
+				}
+			}`,
+			expect: schema.Schema{
+				Fields: schema.Fields{
+					"any": {
+						Validator: &schema.AnyOf{&schema.String{}, &schema.Integer{}},
+					},
+					"all": {
+						Validator: &schema.AllOf{&schema.Time{}},
+					},
+					"null": {
+						Validator: &schema.AnyOf{&schema.URL{}, &schema.Null{}},
+					},
+				},
+			},
+		},
+		{
+			name: "$ref",
+			input: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"definitions": {
+					"name": {"type": "string", "maxLength": 10}
+				},
+				"properties": {
+					"name": {"$ref": "#/definitions/name", "description": "a name"}
+				}
+			}`,
+			expect: schema.Schema{
+				Fields: schema.Fields{
+					"name": {
+						Description: "a name",
+						Validator:   &schema.String{MaxLen: 10},
+					},
+				},
+			},
+		},
+		{
+			name:        "unsupported keyword",
+			input:       `{"properties": {"i": {"type": "integer", "exclusiveMinimum": 0}}}`,
+			expectError: "#/properties/i/exclusiveMinimum: unsupported keyword",
+		},
+		{
+			name:        "unsupported nested keyword",
+			input:       `{"properties": {"a": {"type": "array", "items": {"type": "string", "const": "x"}}}}`,
+			expectError: "#/properties/a/items/const: unsupported keyword",
+		},
+		{
+			name:        "unsupported format",
+			input:       `{"properties": {"e": {"type": "string", "format": "email"}}}`,
+			expectError: "#/properties/e/format: unsupported value",
+		},
+		{
+			name:        "additionalProperties",
+			input:       `{"additionalProperties": true, "properties": {}}`,
+			expectError: "#/additionalProperties: unsupported value",
+		},
+		{
+			name:        "unknown required",
+			input:       `{"properties": {}, "required": ["foo"]}`,
+			expectError: `#/required/0: unknown property "foo"`,
+		},
+		{
+			name: "circular $ref",
+			input: `{
+				"definitions": {"node": {"type": "array", "items": {"$ref": "#/definitions/node"}}},
+				"properties": {"tree": {"$ref": "#/definitions/node"}}
+			}`,
+			expectError: "#/definitions/node/items/$ref: circular reference",
+		},
+		{
+			name:        "root type",
+			input:       `{"type": "string"}`,
+			expectError: "#/type: unsupported value",
+		},
+	}
+	for i := range testCases {
+		testCases[i].Run(t)
+	}
+}
+
+func TestDecoderRoundTrip(t *testing.T) {
+	b := new(bytes.Buffer)
+	assert.NoError(t, jsonschema.NewEncoder(b).Encode(&arrayOfObjectsSchema))
+	var s schema.Schema
+	assert.NoError(t, jsonschema.NewDecoder(b).Decode(&s))
+	b2 := new(bytes.Buffer)
+	assert.NoError(t, jsonschema.NewEncoder(b2).Encode(&s))
+	b.Reset()
+	jsonschema.NewEncoder(b).Encode(&arrayOfObjectsSchema)
+	assert.JSONEq(t, b.String(), b2.String())
+}
@@ -0,0 +1,163 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+const (
+	// definitionsKeyword is the keyword under which referenced schemas are
+	// stored. It is renamed to $defs when targeting Draft202012.
+	definitionsKeyword = "definitions"
+	definitionsPrefix  = "#/" + definitionsKeyword + "/"
+)
+
+// schemaGetter is implemented by schema.Validator implementations wrapping a
+// schema.Schema, such as the resource.Resource validator.
+type schemaGetter interface {
+	Schema() schema.Schema
+}
+
+// validatorSchema returns the schema.Schema behind v if any.
+func validatorSchema(v schema.Validator) (*schema.Schema, bool) {
+	switch t := v.(type) {
+	case schema.Schema:
+		return &t, true
+	case *schema.Schema:
+		return t, t != nil
+	case schemaGetter:
+		s := t.Schema()
+		return &s, true
+	}
+	return nil, false
+}
+
+// definitionRef returns the JSON Pointer to the definition of the resource
+// stored under path.
+func definitionRef(path string) string {
+	return definitionsPrefix + escapePointer(path)
+}
+
+// buildDefinitions returns the definitions of all the schemas referenced from
+// s, directly or through other referenced schemas.
+func buildDefinitions(s *schema.Schema) (map[string]interface{}, error) {
+	refs := map[string]*schema.Reference{}
+	collectSchemaRefs(s, refs)
+	defs := map[string]interface{}{}
+	for len(refs) > 0 {
+		for path, ref := range refs {
+			delete(refs, path)
+			if _, found := defs[path]; found {
+				continue
+			}
+			def := map[string]interface{}{}
+			if rs, ok := validatorSchema(ref.SchemaValidator); ok {
+				if err := addSchemaProperties(def, rs); err != nil {
+					return nil, err
+				}
+				collectSchemaRefs(rs, refs)
+			} else {
+				// Only the id can be described for opaque validators.
+				idSchema := map[string]interface{}{}
+				if f := ref.SchemaValidator.GetField("id"); f != nil {
+					b, err := ValidatorBuilder(f.Validator)
+					if err != nil {
+						return nil, err
+					}
+					if idSchema, err = b.BuildJSONSchema(); err != nil {
+						return nil, err
+					}
+					collectFieldRefs(*f, refs)
+				}
+				def["type"] = "object"
+				def["properties"] = map[string]interface{}{"id": idSchema}
+			}
+			defs[path] = def
+		}
+	}
+	return defs, nil
+}
+
+func collectSchemaRefs(s *schema.Schema, refs map[string]*schema.Reference) {
+	if s == nil {
+		return
+	}
+	for _, f := range s.Fields {
+		collectFieldRefs(f, refs)
+	}
+}
+
+func collectFieldRefs(f schema.Field, refs map[string]*schema.Reference) {
+	if f.Schema != nil {
+		collectSchemaRefs(f.Schema, refs)
+		return
+	}
+	collectValidatorRefs(f.Validator, refs)
+}
+
+func collectValidatorRefs(v schema.FieldValidator, refs map[string]*schema.Reference) {
+	switch t := v.(type) {
+	case *schema.Reference:
+		if t.SchemaValidator != nil {
+			refs[t.Path] = t
+		}
+	case *schema.Connection:
+		if s, ok := validatorSchema(t.Validator); ok {
+			collectSchemaRefs(s, refs)
+		}
+	case *schema.Array:
+		collectFieldRefs(t.Values, refs)
+	case *schema.Dict:
+		collectValidatorRefs(t.KeysValidator, refs)
+		collectFieldRefs(t.Values, refs)
+	case *schema.Object:
+		collectSchemaRefs(t.Schema, refs)
+	case *schema.AnyOf:
+		for _, sv := range *t {
+			collectValidatorRefs(sv, refs)
+		}
+	case *schema.AllOf:
+		for _, sv := range *t {
+			collectValidatorRefs(sv, refs)
+		}
+	}
+}
+
+// rewriteDefinitionRefs converts m into a generic JSON value with definitions
+// stored under the $defs keyword, as expected by JSON Schema 2020-12.
+func rewriteDefinitionRefs(m map[string]interface{}) (interface{}, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	root := v.(map[string]interface{})
+	if defs, found := root[definitionsKeyword]; found {
+		delete(root, definitionsKeyword)
+		root["$defs"] = defs
+	}
+	rewriteRefs(v)
+	return v, nil
+}
+
+func rewriteRefs(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, sv := range t {
+			if ref, ok := sv.(string); ok && k == "$ref" && strings.HasPrefix(ref, definitionsPrefix) {
+				t[k] = "#/$defs/" + ref[len(definitionsPrefix):]
+				continue
+			}
+			rewriteRefs(sv)
+		}
+	case []interface{}:
+		for _, sv := range t {
+			rewriteRefs(sv)
+		}
+	}
+}
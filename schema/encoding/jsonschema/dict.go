@@ -11,8 +11,8 @@ import (
 type dictBuilder schema.Dict
 
 var (
-	//ErrKeysValidatorNotSupported is returned when Dict.KeysValidator is not a
-	//*schema.String instance or nil.
+	//ErrKeysValidatorNotSupported is returned when Dict.KeysValidator
+	//describes values that are not strings, and can thus never validate a key.
 	ErrKeysValidatorNotSupported = errors.New("KeysValidator type not supported")
 )
 
@@ -51,22 +51,26 @@ func (v dictBuilder) BuildJSONSchema() (map[string]interface{}, error) {
 		}
 	case nil:
 	default:
-		return nil, ErrKeysValidatorNotSupported
-	}
-
-	// Retrieve values validator JSON schema.
-	var valuesSchema map[string]interface{}
-	if v.Values.Validator != nil {
-		b, err := ValidatorBuilder(v.Values.Validator)
+		// Other validators are described using propertyNames, as long as
+		// they accept strings.
+		b, err := ValidatorBuilder(kv)
 		if err != nil {
 			return nil, err
 		}
-		valuesSchema, err = b.BuildJSONSchema()
+		names, err := b.BuildJSONSchema()
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		valuesSchema = map[string]interface{}{}
+		if t, found := names["type"]; found && t != "string" {
+			return nil, ErrKeysValidatorNotSupported
+		}
+		m["propertyNames"] = names
+	}
+
+	// Retrieve values validator JSON schema.
+	valuesSchema, err := fieldSchema(v.Values)
+	if err != nil {
+		return nil, err
 	}
 	addFieldProperties(valuesSchema, v.Values)
 
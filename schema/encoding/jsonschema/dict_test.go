@@ -285,6 +285,23 @@ func TestDictValidatorEncode(t *testing.T) {
 				}
 			}`),
 		},
+		{
+			name: `KeysValidator=Time{}"`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"d": {
+						Validator: &schema.Dict{
+							KeysValidator: &schema.Time{},
+						},
+					},
+				},
+			},
+			customValidate: fieldValidator("d", `{
+				"type": "object",
+				"additionalProperties": true,
+				"propertyNames": {"type": "string", "format": "date-time"}
+			}`),
+		},
 	}
 	for i := range testCases {
 		testCases[i].Run(t)
@@ -1,5 +1,6 @@
-// Package jsonschema provides JSON Schema Draft 4 encoding support for
-// schema.Schema. Note that the current implementation is incomplete, and not all
-// FieldValidator types are yet supported. Custom validators are also not
-// supported at the moment.
+// Package jsonschema provides JSON Schema encoding support for schema.Schema,
+// targeting Draft 4 by default, draft-07 or 2020-12, as well as decoding of
+// JSON Schema draft-07 documents into schema.Schema. All the FieldValidator
+// types of the schema package can be encoded; custom validators must implement
+// the Builder interface to be supported.
 package jsonschema
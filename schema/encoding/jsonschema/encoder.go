@@ -7,13 +7,46 @@ import (
 	"github.com/rs/rest-layer/schema"
 )
 
+// Draft identifies the JSON Schema specification version targeted by an
+// Encoder.
+type Draft int
+
+const (
+	// Draft4 targets JSON Schema Draft 4. This is the default, and for
+	// backward compatibility, the $schema keyword is not emitted.
+	Draft4 Draft = iota
+	// Draft7 targets JSON Schema draft-07.
+	Draft7
+	// Draft202012 targets JSON Schema 2020-12. Definitions are stored under
+	// the $defs keyword instead of definitions.
+	Draft202012
+)
+
+// URI returns the meta-schema URI identifying the draft d.
+func (d Draft) URI() string {
+	switch d {
+	case Draft7:
+		return "http://json-schema.org/draft-07/schema#"
+	case Draft202012:
+		return "https://json-schema.org/draft/2020-12/schema"
+	default:
+		return "http://json-schema.org/draft-04/schema#"
+	}
+}
+
 // Encoder writes the JSON Schema representation of a schema.Schema to an output
-// stream. Note that only a sub-set of the FieldValidator types in the schema
-// package is supported at the moment. Custom validators are also not yet
-// handled. Attempting to encode a schema containing such fields will result in
+// stream. All FieldValidator types in the schema package are supported. Custom
+// validators must implement the Builder interface, or encoding will result in
 // a ErrNotImplemented error.
+//
+// Fields validated by a schema.Reference are encoded as a $ref to the id
+// property of the referenced schema, stored once in the definitions section of
+// the document under the reference path. The referenced schema is known when
+// the reference's SchemaValidator is a schema.Schema or implements a
+// Schema() schema.Schema method, as the validators of resource.Resource do.
 type Encoder struct {
-	w io.Writer
+	w     io.Writer
+	draft Draft
 }
 
 // NewEncoder returns a new JSONSchema Encoder that writes to w.
@@ -21,6 +54,11 @@ func NewEncoder(w io.Writer) *Encoder {
 	return &Encoder{w: w}
 }
 
+// SetDraft sets the JSON Schema specification version targeted by the encoder.
+func (e *Encoder) SetDraft(d Draft) {
+	e.draft = d
+}
+
 // Encode writes the JSON Schema representation of s to the stream, followed by
 // a newline character.
 func (e *Encoder) Encode(s *schema.Schema) error {
@@ -28,8 +66,24 @@ func (e *Encoder) Encode(s *schema.Schema) error {
 	if err := addSchemaProperties(m, s); err != nil {
 		return err
 	}
+	defs, err := buildDefinitions(s)
+	if err != nil {
+		return err
+	}
+	if len(defs) > 0 {
+		m[definitionsKeyword] = defs
+	}
+	if e.draft != Draft4 {
+		m["$schema"] = e.draft.URI()
+	}
+	var v interface{} = m
+	if e.draft == Draft202012 {
+		if v, err = rewriteDefinitionRefs(m); err != nil {
+			return err
+		}
+	}
 	enc := json.NewEncoder(e.w)
-	return enc.Encode(m)
+	return enc.Encode(v)
 
 }
 
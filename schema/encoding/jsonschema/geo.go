@@ -0,0 +1,25 @@
+package jsonschema
+
+import "github.com/rs/rest-layer/schema"
+
+type geoPointBuilder schema.GeoPoint
+
+func (v geoPointBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	m := map[string]interface{}{
+		"type":     "object",
+		"required": []string{"type", "coordinates"},
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"Point"},
+			},
+			"coordinates": map[string]interface{}{
+				"type":     "array",
+				"items":    map[string]interface{}{"type": "number"},
+				"minItems": 2,
+				"maxItems": 2,
+			},
+		},
+	}
+	return m, nil
+}
@@ -0,0 +1,34 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+func TestGeoPointValidatorEncode(t *testing.T) {
+	testCase := encoderTestCase{
+		name: ``,
+		schema: schema.Schema{
+			Fields: schema.Fields{
+				"location": {
+					Validator: &schema.GeoPoint{},
+				},
+			},
+		},
+		customValidate: fieldValidator("location", `{
+			"type": "object",
+			"required": ["type", "coordinates"],
+			"properties": {
+				"type": {"type": "string", "enum": ["Point"]},
+				"coordinates": {
+					"type": "array",
+					"items": {"type": "number"},
+					"minItems": 2,
+					"maxItems": 2
+				}
+			}
+		}`),
+	}
+	testCase.Run(t)
+}
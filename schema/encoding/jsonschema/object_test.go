@@ -51,3 +51,18 @@ func TestObjectValidatorEncode(t *testing.T) {
 		testCases[i].Run(t)
 	}
 }
+
+func TestFieldSchemaEncode(t *testing.T) {
+	testCase := encoderTestCase{
+		name: `Schema=simpleSchema`,
+		schema: schema.Schema{
+			Fields: schema.Fields{
+				"student": {
+					Schema: &simpleSchema,
+				},
+			},
+		},
+		customValidate: fieldValidator("student", simpleSchemaJSON),
+	}
+	testCase.Run(t)
+}
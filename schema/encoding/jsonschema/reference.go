@@ -0,0 +1,15 @@
+package jsonschema
+
+import "github.com/rs/rest-layer/schema"
+
+type referenceBuilder schema.Reference
+
+func (v referenceBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	if v.SchemaValidator == nil {
+		// The reference is not compiled, so the referenced schema is unknown.
+		return map[string]interface{}{}, nil
+	}
+	return map[string]interface{}{
+		"$ref": definitionRef(v.Path) + "/properties/id",
+	}, nil
+}
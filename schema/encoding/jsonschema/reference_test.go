@@ -1,11 +1,27 @@
 package jsonschema_test
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/rs/rest-layer/schema"
+	"github.com/rs/rest-layer/schema/encoding/jsonschema"
+	"github.com/stretchr/testify/assert"
 )
 
+// referenceChecker implements schema.ReferenceChecker for a static set of
+// schemas.
+type referenceChecker map[string]schema.Schema
+
+func (rc referenceChecker) ReferenceChecker(path string) (schema.FieldValidator, schema.Validator) {
+	s, found := rc[path]
+	if !found {
+		return nil, nil
+	}
+	return s.Fields["id"].Validator, s
+}
+
 func TestReferenceValidatorEncode(t *testing.T) {
 	testCase := encoderTestCase{
 		name: ``,
@@ -20,3 +36,105 @@ func TestReferenceValidatorEncode(t *testing.T) {
 	}
 	testCase.Run(t)
 }
+
+func TestReferenceDefinitionsEncode(t *testing.T) {
+	users := schema.Schema{
+		Fields: schema.Fields{
+			"id":   {Validator: &schema.String{}},
+			"boss": {Validator: &schema.Reference{Path: "users"}},
+		},
+	}
+	rc := referenceChecker{"users": users}
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"owner": {
+				Validator: &schema.Reference{Path: "users"},
+			},
+			"readers": {
+				Validator: &schema.Array{
+					Values: schema.Field{Validator: &schema.Reference{Path: "users"}},
+				},
+			},
+		},
+	}
+	assert.NoError(t, users.Compile(rc))
+	assert.NoError(t, s.Compile(rc))
+	usersJSON := `{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string"},
+			"boss": {"$ref": "#/%s/users/properties/id"}
+		}
+	}`
+
+	t.Run("Draft4", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		assert.NoError(t, jsonschema.NewEncoder(b).Encode(&s))
+		assert.JSONEq(t, `{
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"owner": {"$ref": "#/definitions/users/properties/id"},
+				"readers": {
+					"type": "array",
+					"items": {"$ref": "#/definitions/users/properties/id"}
+				}
+			},
+			"definitions": {
+				"users": `+fmt.Sprintf(usersJSON, "definitions")+`
+			}
+		}`, b.String())
+	})
+	t.Run("Draft7", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		enc := jsonschema.NewEncoder(b)
+		enc.SetDraft(jsonschema.Draft7)
+		assert.NoError(t, enc.Encode(&s))
+		assert.JSONEq(t, `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"owner": {"$ref": "#/definitions/users/properties/id"},
+				"readers": {
+					"type": "array",
+					"items": {"$ref": "#/definitions/users/properties/id"}
+				}
+			},
+			"definitions": {
+				"users": `+fmt.Sprintf(usersJSON, "definitions")+`
+			}
+		}`, b.String())
+	})
+	t.Run("Draft202012", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		enc := jsonschema.NewEncoder(b)
+		enc.SetDraft(jsonschema.Draft202012)
+		assert.NoError(t, enc.Encode(&s))
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"owner": {"$ref": "#/$defs/users/properties/id"},
+				"readers": {
+					"type": "array",
+					"items": {"$ref": "#/$defs/users/properties/id"}
+				}
+			},
+			"$defs": {
+				"users": `+fmt.Sprintf(usersJSON, "$defs")+`
+			}
+		}`, b.String())
+	})
+	t.Run("Decode", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		enc := jsonschema.NewEncoder(b)
+		enc.SetDraft(jsonschema.Draft202012)
+		assert.NoError(t, enc.Encode(&s))
+		var d schema.Schema
+		assert.NoError(t, jsonschema.NewDecoder(b).Decode(&d))
+		assert.Equal(t, &schema.String{}, d.Fields["owner"].Validator)
+	})
+}
@@ -43,11 +43,7 @@ func addFields(m map[string]interface{}, fields schema.Fields) error {
 		if field.Required {
 			required = append(required, fieldName)
 		}
-		builder, err := ValidatorBuilder(field.Validator)
-		if err != nil {
-			return err
-		}
-		fieldMap, err := builder.BuildJSONSchema()
+		fieldMap, err := fieldSchema(field)
 		if err != nil {
 			return err
 		}
@@ -63,6 +59,23 @@ func addFields(m map[string]interface{}, fields schema.Fields) error {
 	return nil
 }
 
+// fieldSchema returns the JSON Schema for the value of field, described either
+// by its sub-schema or its validator.
+func fieldSchema(field schema.Field) (map[string]interface{}, error) {
+	if field.Schema != nil {
+		m := map[string]interface{}{}
+		if err := addSchemaProperties(m, field.Schema); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	builder, err := ValidatorBuilder(field.Validator)
+	if err != nil {
+		return nil, err
+	}
+	return builder.BuildJSONSchema()
+}
+
 func addFieldProperties(m map[string]interface{}, field schema.Field) {
 	if field.Description != "" {
 		m["description"] = field.Description
@@ -98,6 +111,8 @@ func ValidatorBuilder(v schema.FieldValidator) (Builder, error) {
 		return (*urlBuilder)(t), nil
 	case *schema.Time:
 		return (*timeBuilder)(t), nil
+	case *schema.GeoPoint:
+		return (*geoPointBuilder)(t), nil
 	case *schema.Integer:
 		return (*integerBuilder)(t), nil
 	case *schema.Float:
@@ -113,7 +128,9 @@ func ValidatorBuilder(v schema.FieldValidator) (Builder, error) {
 	case *schema.AllOf:
 		return (*allOfBuilder)(t), nil
 	case *schema.Reference:
-		return builderFunc(nilBuilder), nil
+		return (*referenceBuilder)(t), nil
+	case *schema.Connection:
+		return (*connectionBuilder)(t), nil
 	default:
 		return nil, ErrNotImplemented
 	}
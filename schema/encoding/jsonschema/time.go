@@ -1,12 +1,47 @@
 package jsonschema
 
-import "github.com/rs/rest-layer/schema"
+import (
+	"time"
+
+	"github.com/rs/rest-layer/schema"
+)
 
 type timeBuilder schema.Time
 
 func (v timeBuilder) BuildJSONSchema() (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"type":   "string",
-		"format": "date-time",
-	}, nil
+	m := map[string]interface{}{
+		"type": "string",
+	}
+	if f := timeFormat(v.TimeLayouts); f != "" {
+		m["format"] = f
+	}
+	return m, nil
+}
+
+// timeFormat returns the JSON Schema format matching all the layouts, or an
+// empty string if there is none. The default layouts are reported as
+// date-time.
+func timeFormat(layouts []string) string {
+	if len(layouts) == 0 {
+		return "date-time"
+	}
+	format := ""
+	for _, layout := range layouts {
+		var f string
+		switch layout {
+		case time.RFC3339, time.RFC3339Nano:
+			f = "date-time"
+		case "2006-01-02":
+			f = "date"
+		case "15:04:05", "15:04:05Z07:00":
+			f = "time"
+		default:
+			return ""
+		}
+		if format != "" && format != f {
+			return ""
+		}
+		format = f
+	}
+	return format
 }
@@ -4,21 +4,59 @@ package jsonschema_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/rs/rest-layer/schema"
 )
 
 func TestTimeValidatorEncode(t *testing.T) {
-	testCase := encoderTestCase{
-		name: ``,
-		schema: schema.Schema{
-			Fields: schema.Fields{
-				"t": {
-					Validator: &schema.Time{},
+	testCases := []encoderTestCase{
+		{
+			name: ``,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"t": {
+						Validator: &schema.Time{},
+					},
 				},
 			},
+			customValidate: fieldValidator("t", `{"type": "string", "format": "date-time"}`),
 		},
-		customValidate: fieldValidator("t", `{"type": "string", "format": "date-time"}`),
+		{
+			name: `TimeLayouts=[RFC3339Nano]`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"t": {
+						Validator: &schema.Time{TimeLayouts: []string{time.RFC3339Nano}},
+					},
+				},
+			},
+			customValidate: fieldValidator("t", `{"type": "string", "format": "date-time"}`),
+		},
+		{
+			name: `TimeLayouts=["2006-01-02"]`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"t": {
+						Validator: &schema.Time{TimeLayouts: []string{"2006-01-02"}},
+					},
+				},
+			},
+			customValidate: fieldValidator("t", `{"type": "string", "format": "date"}`),
+		},
+		{
+			name: `TimeLayouts=["2006-01-02",RFC3339]`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"t": {
+						Validator: &schema.Time{TimeLayouts: []string{"2006-01-02", time.RFC3339}},
+					},
+				},
+			},
+			customValidate: fieldValidator("t", `{"type": "string"}`),
+		},
+	}
+	for i := range testCases {
+		testCases[i].Run(t)
 	}
-	testCase.Run(t)
 }
@@ -1,15 +1,29 @@
 package jsonschema
 
-import "github.com/rs/rest-layer/schema"
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rs/rest-layer/schema"
+)
 
 type urlBuilder schema.URL
 
 func (v urlBuilder) BuildJSONSchema() (map[string]interface{}, error) {
-	// TODO: Currently the JSON Schema representation ignores any validation
-	// configuration set in schema.URL.
 	m := map[string]interface{}{
 		"type":   "string",
 		"format": "uri",
 	}
+	if v.AllowRelative {
+		m["format"] = "uri-reference"
+	}
+	if len(v.AllowedSchemes) > 0 {
+		schemes := make([]string, 0, len(v.AllowedSchemes))
+		for _, s := range v.AllowedSchemes {
+			schemes = append(schemes, regexp.QuoteMeta(s))
+		}
+		m["pattern"] = "^(" + strings.Join(schemes, "|") + "):"
+	}
+	// AllowLocale and AllowNonHTTP are not reflected.
 	return m, nil
 }
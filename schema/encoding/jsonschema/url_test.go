@@ -7,19 +7,41 @@ import (
 )
 
 func TestURLValidatorEncode(t *testing.T) {
-	testCase := encoderTestCase{
-		name: ``,
-		schema: schema.Schema{
-			Fields: schema.Fields{
-				"url": {
-					Validator: &schema.URL{},
+	testCases := []encoderTestCase{
+		{
+			name: ``,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"url": {
+						Validator: &schema.URL{},
+					},
 				},
 			},
+			customValidate: fieldValidator("url", `{
+				"type": "string",
+				"format": "uri"
+			}`),
 		},
-		customValidate: fieldValidator("url", `{
-			"type": "string",
-			"format": "uri"
-		}`),
+		{
+			name: `AllowRelative=true,AllowedSchemes=["ftp","git+ssh"]`,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"url": {
+						Validator: &schema.URL{
+							AllowRelative:  true,
+							AllowedSchemes: []string{"ftp", "git+ssh"},
+						},
+					},
+				},
+			},
+			customValidate: fieldValidator("url", `{
+				"type": "string",
+				"format": "uri-reference",
+				"pattern": "^(ftp|git\\+ssh):"
+			}`),
+		},
+	}
+	for i := range testCases {
+		testCases[i].Run(t)
 	}
-	testCase.Run(t)
 }
@@ -25,6 +25,15 @@ type Field struct {
 	// this field is enabled, PUTing the document without the field would not
 	// remove the field but use the previous document's value if any.
 	Hidden bool
+	// Readable defines a permission, evaluated with the request context, the
+	// client must be granted to see the field's content. When not granted, the
+	// field is omitted from the output as if it was hidden.
+	Readable FieldPermission
+	// Writable defines a permission, evaluated with the request context, the
+	// client must be granted to change the field. Changing the field without
+	// this permission results in a "forbidden" error. When not granted,
+	// PUTing the document without the field keeps the previous value.
+	Writable FieldPermission
 	// Default defines the value be stored on the field when when item is
 	// created and this field is not provided by the client.
 	Default interface{}
@@ -59,6 +68,16 @@ type Field struct {
 	// When this property is set to `true`, you may want to ensure the backend
 	// database has this field indexed.
 	Sortable bool
+	// Searchable defines that the field can be used with the `$text` full-text
+	// search operator of the `filter` parameter. When this property is set to
+	// `true`, you may want to ensure the backend database has this field
+	// indexed for full-text search.
+	Searchable bool
+	// Unique defines that the field's value must be unique among the items of
+	// the resource. The constraint is enforced by the resource on insert and
+	// update (see resource.Conf.Unique for compound constraints). Only top
+	// level fields can be unique.
+	Unique bool
 	// Schema can be set to a sub-schema to allow multi-level schema.
 	Schema *Schema
 }
@@ -90,6 +109,17 @@ func (f Field) Compile(rc ReferenceChecker) error {
 // parameters
 type FieldHandler func(ctx context.Context, value interface{}, params map[string]interface{}) (interface{}, error)
 
+// FieldPermission is a function returning true if the operation it protects
+// on a field is allowed for the request described by ctx (i.e.: using the user
+// stored in the context by an authentication middleware).
+type FieldPermission func(ctx context.Context) bool
+
+// Allowed returns true if the permission is granted for ctx. A nil permission
+// is always granted.
+func (p FieldPermission) Allowed(ctx context.Context) bool {
+	return p == nil || p(ctx)
+}
+
 // FieldValidator is an interface for all individual validators. It takes a
 // value to validate as argument and returned the normalized value or an error
 // if validation failed.
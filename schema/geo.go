@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GeoPoint validates geographic locations. Locations can be given as a GeoJSON
+// point ({"type": "Point", "coordinates": [lon, lat]}), as an object with lat
+// and lon fields, or as a [lon, lat] array. They are stored as GeoJSON points.
+type GeoPoint struct{}
+
+// Validate implements FieldValidator.
+func (v GeoPoint) Validate(value interface{}) (interface{}, error) {
+	lon, lat, err := ParseGeoPoint(value)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{lon, lat},
+	}, nil
+}
+
+// ParseGeoPoint returns the longitude and latitude of value, a location in one
+// of the formats accepted by GeoPoint. An error is returned if the value is
+// not a location or if its coordinates are out of bounds.
+func ParseGeoPoint(value interface{}) (lon, lat float64, err error) {
+	var lonV, latV interface{}
+	switch t := value.(type) {
+	case map[string]interface{}:
+		if typ, found := t["type"]; found {
+			if typ != "Point" {
+				return 0, 0, errors.New("not a GeoJSON point")
+			}
+			return ParseGeoPoint(t["coordinates"])
+		}
+		lonV, latV = t["lon"], t["lat"]
+	case []interface{}:
+		if len(t) != 2 {
+			return 0, 0, errors.New("invalid coordinates: expected [lon, lat]")
+		}
+		lonV, latV = t[0], t[1]
+	default:
+		return 0, 0, errors.New("invalid type")
+	}
+	var ok bool
+	if lon, ok = geoFloat(lonV); !ok {
+		return 0, 0, errors.New("invalid longitude")
+	}
+	if lat, ok = geoFloat(latV); !ok {
+		return 0, 0, errors.New("invalid latitude")
+	}
+	if lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("longitude %g out of bounds [-180, 180]", lon)
+	}
+	if lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("latitude %g out of bounds [-90, 90]", lat)
+	}
+	return lon, lat, nil
+}
+
+// geoFloat returns v as a float64 if it's a number.
+func geoFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoPointValidator(t *testing.T) {
+	point := map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{2.35, 48.85},
+	}
+	v, err := GeoPoint{}.Validate(map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.35, 48.85}})
+	assert.NoError(t, err)
+	assert.Equal(t, point, v)
+	v, err = GeoPoint{}.Validate(map[string]interface{}{"lat": 48.85, "lon": 2.35})
+	assert.NoError(t, err)
+	assert.Equal(t, point, v)
+	v, err = GeoPoint{}.Validate([]interface{}{2.35, 48.85})
+	assert.NoError(t, err)
+	assert.Equal(t, point, v)
+	v, err = GeoPoint{}.Validate([]interface{}{2, -45})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.0, -45.0}}, v)
+	v, err = GeoPoint{}.Validate(map[string]interface{}{"type": "LineString", "coordinates": []interface{}{}})
+	assert.EqualError(t, err, "not a GeoJSON point")
+	assert.Nil(t, v)
+	v, err = GeoPoint{}.Validate("2.35,48.85")
+	assert.EqualError(t, err, "invalid type")
+	assert.Nil(t, v)
+	v, err = GeoPoint{}.Validate([]interface{}{2.35})
+	assert.EqualError(t, err, "invalid coordinates: expected [lon, lat]")
+	assert.Nil(t, v)
+	v, err = GeoPoint{}.Validate(map[string]interface{}{"lat": 48.85})
+	assert.EqualError(t, err, "invalid longitude")
+	assert.Nil(t, v)
+	v, err = GeoPoint{}.Validate([]interface{}{2.35, "48.85"})
+	assert.EqualError(t, err, "invalid latitude")
+	assert.Nil(t, v)
+	v, err = GeoPoint{}.Validate([]interface{}{181, 0})
+	assert.EqualError(t, err, "longitude 181 out of bounds [-180, 180]")
+	assert.Nil(t, v)
+	v, err = GeoPoint{}.Validate([]interface{}{0, -90.5})
+	assert.EqualError(t, err, "latitude -90.5 out of bounds [-90, 90]")
+	assert.Nil(t, v)
+}
@@ -0,0 +1,274 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+// AccumulatorFunc is the function computing the value of an Accumulator.
+type AccumulatorFunc string
+
+const (
+	// AccumulatorCount counts the items of the group.
+	AccumulatorCount AccumulatorFunc = "count"
+	// AccumulatorSum sums the numeric values of the field.
+	AccumulatorSum AccumulatorFunc = "sum"
+	// AccumulatorAvg averages the numeric values of the field.
+	AccumulatorAvg AccumulatorFunc = "avg"
+	// AccumulatorMin returns the lowest value of the field.
+	AccumulatorMin AccumulatorFunc = "min"
+	// AccumulatorMax returns the highest value of the field.
+	AccumulatorMax AccumulatorFunc = "max"
+)
+
+// Accumulator is a value computed over the items of each group of an
+// Aggregation.
+type Accumulator struct {
+	Func AccumulatorFunc
+	// Field is the field the function is applied on. It's ignored by
+	// AccumulatorCount.
+	Field string
+}
+
+// Name returns the key of the accumulator in the aggregation results: count
+// for AccumulatorCount, or the function and the field separated by an
+// underscore otherwise (i.e.: sum_amount).
+func (a Accumulator) Name() string {
+	if a.Func == AccumulatorCount {
+		return string(a.Func)
+	}
+	return string(a.Func) + "_" + strings.Replace(a.Field, ".", "_", -1)
+}
+
+// Aggregation groups the items matching a query by the values of some fields
+// and computes accumulators over each group.
+//
+// The result of an aggregation is a list of maps holding, for each group, the
+// values of the group fields keyed by field name, and the accumulators keyed
+// by name (see Accumulator.Name). Groups are ordered by their group values.
+type Aggregation struct {
+	// Group lists the fields whose combination of values defines the groups.
+	// When empty, all the items are aggregated in a single group.
+	Group []string
+	// Accumulators lists the values to compute for each group.
+	Accumulators []Accumulator
+	// Having filters the groups on their group values and accumulators.
+	Having Predicate
+}
+
+// Validate validates the aggregation against the provided validator. Group
+// and accumulated fields must be filterable, and the Having predicate is
+// prepared against the fields of the results.
+func (a *Aggregation) Validate(validator schema.Validator) error {
+	row := schema.Schema{Fields: schema.Fields{}}
+	for _, field := range a.Group {
+		f, err := getValidatorField(field, validator)
+		if err != nil {
+			return err
+		}
+		row.Fields[field] = *f
+	}
+	for _, acc := range a.Accumulators {
+		def := schema.Field{Filterable: true}
+		switch acc.Func {
+		case AccumulatorCount:
+			def.Validator = &schema.Integer{}
+		case AccumulatorSum, AccumulatorAvg, AccumulatorMin, AccumulatorMax:
+			f, err := getValidatorField(acc.Field, validator)
+			if err != nil {
+				return err
+			}
+			if acc.Func == AccumulatorMin || acc.Func == AccumulatorMax {
+				def = *f
+				break
+			}
+			switch f.Validator.(type) {
+			case *schema.Integer, schema.Integer, *schema.Float, schema.Float:
+			default:
+				return fmt.Errorf("%s: field is not numeric", acc.Field)
+			}
+			def.Validator = &schema.Float{}
+		default:
+			return fmt.Errorf("%s: unknown accumulator", acc.Func)
+		}
+		row.Fields[acc.Name()] = def
+	}
+	return a.Having.Prepare(row)
+}
+
+// Groups accumulates items into the groups of an aggregation.
+type Groups struct {
+	aggregation *Aggregation
+	groups      map[string]*group
+}
+
+type group struct {
+	values map[string]interface{}
+	count  int
+	sums   []float64
+	counts []int
+	bounds []interface{}
+}
+
+// Accumulate returns an empty set of groups for the aggregation. Items are
+// added to it using Add.
+func (a *Aggregation) Accumulate() *Groups {
+	return &Groups{aggregation: a, groups: map[string]*group{}}
+}
+
+// Apply returns the result of the aggregation over the provided payloads.
+func (a *Aggregation) Apply(payloads []map[string]interface{}) []map[string]interface{} {
+	g := a.Accumulate()
+	for _, p := range payloads {
+		g.Add(p)
+	}
+	return g.Results()
+}
+
+// Add adds the item payload to its group.
+func (g *Groups) Add(payload map[string]interface{}) {
+	a := g.aggregation
+	values := make(map[string]interface{}, len(a.Group))
+	key := make([]string, len(a.Group))
+	for i, field := range a.Group {
+		v := getField(payload, field)
+		values[field] = v
+		key[i] = valueString(v)
+	}
+	gr := g.groups[strings.Join(key, ",")]
+	if gr == nil {
+		gr = &group{
+			values: values,
+			sums:   make([]float64, len(a.Accumulators)),
+			counts: make([]int, len(a.Accumulators)),
+			bounds: make([]interface{}, len(a.Accumulators)),
+		}
+		g.groups[strings.Join(key, ",")] = gr
+	}
+	gr.count++
+	for i, acc := range a.Accumulators {
+		v := getField(payload, acc.Field)
+		switch acc.Func {
+		case AccumulatorSum, AccumulatorAvg:
+			if n, ok := isNumber(v); ok {
+				gr.sums[i] += n
+				gr.counts[i]++
+			}
+		case AccumulatorMin, AccumulatorMax:
+			if v == nil {
+				continue
+			}
+			c := compareValues(v, gr.bounds[i])
+			if gr.bounds[i] == nil || acc.Func == AccumulatorMin && c < 0 || acc.Func == AccumulatorMax && c > 0 {
+				gr.bounds[i] = v
+			}
+		}
+	}
+}
+
+// Results returns the groups matching the Having predicate of the aggregation,
+// ordered by their group values.
+func (g *Groups) Results() []map[string]interface{} {
+	a := g.aggregation
+	res := []map[string]interface{}{}
+	for _, gr := range g.groups {
+		row := make(map[string]interface{}, len(a.Group)+len(a.Accumulators))
+		for field, v := range gr.values {
+			row[field] = v
+		}
+		for i, acc := range a.Accumulators {
+			var v interface{}
+			switch acc.Func {
+			case AccumulatorCount:
+				v = gr.count
+			case AccumulatorSum:
+				v = gr.sums[i]
+			case AccumulatorAvg:
+				if gr.counts[i] > 0 {
+					v = gr.sums[i] / float64(gr.counts[i])
+				}
+			case AccumulatorMin, AccumulatorMax:
+				v = gr.bounds[i]
+			}
+			row[acc.Name()] = v
+		}
+		if a.Having.Match(row) {
+			res = append(res, row)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		for _, field := range a.Group {
+			if c := compareValues(res[i][field], res[j][field]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return res
+}
+
+// compareValues returns -1, 0 or 1 if a is respectively lower, equal or
+// greater than b. Values of different types are ordered by type: nil, bool,
+// numbers, strings, times and others.
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch t := a.(type) {
+	case bool:
+		if t == b.(bool) {
+			return 0
+		} else if !t {
+			return -1
+		}
+		return 1
+	case string:
+		return strings.Compare(t, b.(string))
+	case time.Time:
+		if t.Before(b.(time.Time)) {
+			return -1
+		} else if t.After(b.(time.Time)) {
+			return 1
+		}
+		return 0
+	}
+	if n, ok := isNumber(a); ok {
+		m, _ := isNumber(b)
+		if n < m {
+			return -1
+		} else if n > m {
+			return 1
+		}
+		return 0
+	}
+	if ra == 5 {
+		return strings.Compare(valueString(a), valueString(b))
+	}
+	return 0
+}
+
+func typeRank(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+	switch v.(type) {
+	case bool:
+		return 1
+	case string:
+		return 3
+	case time.Time:
+		return 4
+	}
+	if _, ok := isNumber(v); ok {
+		return 2
+	}
+	return 5
+}
@@ -0,0 +1,84 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationApply(t *testing.T) {
+	payloads := []map[string]interface{}{
+		{"status": "paid", "amount": 10, "customer": "b"},
+		{"status": "paid", "amount": 30.5, "customer": "a"},
+		{"status": "draft", "amount": 5, "customer": "a"},
+		{"status": "draft", "customer": "c"},
+		{"amount": 1},
+	}
+	a := &Aggregation{
+		Group: []string{"status"},
+		Accumulators: []Accumulator{
+			{Func: AccumulatorCount},
+			{Func: AccumulatorSum, Field: "amount"},
+			{Func: AccumulatorAvg, Field: "amount"},
+			{Func: AccumulatorMin, Field: "customer"},
+			{Func: AccumulatorMax, Field: "amount"},
+		},
+	}
+	assert.Equal(t, []map[string]interface{}{
+		{"status": nil, "count": 1, "sum_amount": 1.0, "avg_amount": 1.0, "min_customer": nil, "max_amount": 1},
+		{"status": "draft", "count": 2, "sum_amount": 5.0, "avg_amount": 5.0, "min_customer": "a", "max_amount": 5},
+		{"status": "paid", "count": 2, "sum_amount": 40.5, "avg_amount": 20.25, "min_customer": "a", "max_amount": 30.5},
+	}, a.Apply(payloads))
+
+	a = &Aggregation{
+		Accumulators: []Accumulator{{Func: AccumulatorCount}},
+	}
+	assert.Equal(t, []map[string]interface{}{{"count": 5}}, a.Apply(payloads))
+}
+
+func TestAggregationValidate(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"status": {Filterable: true},
+		"amount": {Filterable: true, Validator: &schema.Integer{}},
+		"name":   {Filterable: true, Validator: &schema.String{}},
+		"secret": {},
+	}}
+	cases := []struct {
+		a   Aggregation
+		err string
+	}{
+		{Aggregation{Group: []string{"status"}, Accumulators: []Accumulator{{Func: AccumulatorSum, Field: "amount"}}}, ""},
+		{Aggregation{Group: []string{"foo"}}, "foo: unknown query field"},
+		{Aggregation{Group: []string{"secret"}}, "secret: field is not filterable"},
+		{Aggregation{Accumulators: []Accumulator{{Func: AccumulatorAvg, Field: "name"}}}, "name: field is not numeric"},
+		{Aggregation{Accumulators: []Accumulator{{Func: "median", Field: "amount"}}}, "median: unknown accumulator"},
+		{Aggregation{Accumulators: []Accumulator{{Func: AccumulatorCount}}, Having: MustParsePredicate(`{count: {$gt: 1}}`)}, ""},
+		{Aggregation{Accumulators: []Accumulator{{Func: AccumulatorCount}}, Having: MustParsePredicate(`{amount: 1}`)}, "amount: unknown query field"},
+	}
+	for _, tc := range cases {
+		err := tc.a.Validate(s)
+		if tc.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tc.err)
+		}
+	}
+
+	// Having filters the groups once prepared.
+	a := &Aggregation{
+		Group:        []string{"status"},
+		Accumulators: []Accumulator{{Func: AccumulatorCount}, {Func: AccumulatorSum, Field: "amount"}},
+		Having:       MustParsePredicate(`{count: {$gt: 1}, sum_amount: {$gte: 3}}`),
+	}
+	assert.NoError(t, a.Validate(s))
+	assert.Equal(t, []map[string]interface{}{
+		{"status": "paid", "count": 2, "sum_amount": 3.0},
+	}, a.Apply([]map[string]interface{}{
+		{"status": "paid", "amount": 1},
+		{"status": "paid", "amount": 2},
+		{"status": "draft", "amount": 5},
+		{"status": "sent", "amount": 1},
+		{"status": "sent", "amount": 1},
+	}))
+}
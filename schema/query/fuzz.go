@@ -2,6 +2,8 @@
 
 package query
 
+import "fmt"
+
 // FuzzPredicate is used by the https://github.com/dvyukov/go-fuzz framework.
 //
 // It's method signature must match the prescribed format and it is expected to
@@ -11,10 +13,20 @@ package query
 //     $ go-fuzz-build -func FuzzPredicate -o fuzz-query-predicate.zip github.com/rs/rest-layer/schema/query
 //     $ go-fuzz -bin=fuzz-query-predicate.zip -workdir=schema/query/testdata/fuzz-predicate
 func FuzzPredicate(data []byte) int {
-	_, err := ParsePredicate(string(data))
+	p, err := ParsePredicate(string(data))
 	if err != nil {
 		return 0
 	}
+	// The string representation of a predicate must parse back to the same
+	// predicate.
+	s := p.String()
+	p2, err := ParsePredicate(s)
+	if err != nil {
+		panic(fmt.Sprintf("cannot parse %q back: %v", s, err))
+	}
+	if s2 := p2.String(); s2 != s {
+		panic(fmt.Sprintf("%q parsed back as %q", s, s2))
+	}
 	return 1
 }
 
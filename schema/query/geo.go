@@ -0,0 +1,309 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+// DistanceField is the sort field ordering items by their distance to the
+// point of the Near expression of the predicate, closest first.
+const DistanceField = "_distance"
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Point is a geographic location.
+type Point struct {
+	Lon float64
+	Lat float64
+}
+
+// Distance returns the great-circle distance between p and o in meters.
+func (p Point) Distance(o Point) float64 {
+	lat1, lat2 := p.Lat*math.Pi/180, o.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (o.Lon - p.Lon) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func (p Point) String() string {
+	return "[" + valueString(p.Lon) + ", " + valueString(p.Lat) + "]"
+}
+
+// toPoint returns the location held by v, in one of the formats accepted by
+// schema.GeoPoint.
+func toPoint(v interface{}) (Point, error) {
+	lon, lat, err := schema.ParseGeoPoint(v)
+	return Point{Lon: lon, Lat: lat}, err
+}
+
+// Shape is an area used by the Within expression.
+type Shape interface {
+	// Contains returns true if p is within the shape.
+	Contains(p Point) bool
+	// String returns the shape in the predicate syntax.
+	String() string
+}
+
+// Box is a rectangle defined by its south-west (Min) and north-east (Max)
+// corners. A box with a Min longitude greater than its Max longitude crosses
+// the antimeridian.
+type Box struct {
+	Min Point
+	Max Point
+}
+
+// Contains implements Shape.
+func (b Box) Contains(p Point) bool {
+	if p.Lat < b.Min.Lat || p.Lat > b.Max.Lat {
+		return false
+	}
+	if b.Min.Lon <= b.Max.Lon {
+		return p.Lon >= b.Min.Lon && p.Lon <= b.Max.Lon
+	}
+	return p.Lon >= b.Min.Lon || p.Lon <= b.Max.Lon
+}
+
+// String implements Shape.
+func (b Box) String() string {
+	return "{$box: [" + b.Min.String() + ", " + b.Max.String() + "]}"
+}
+
+// Polygon is an area delimited by a closed ring of points. Edges are treated
+// as straight lines on the longitude/latitude plane.
+type Polygon []Point
+
+// Contains implements Shape.
+func (poly Polygon) Contains(p Point) bool {
+	in := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			in = !in
+		}
+	}
+	return in
+}
+
+// String implements Shape.
+func (poly Polygon) String() string {
+	s := make([]string, len(poly))
+	for i, p := range poly {
+		s[i] = p.String()
+	}
+	return "{$polygon: [" + strings.Join(s, ", ") + "]}"
+}
+
+// Circle is the area within Radius meters of Center.
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Contains implements Shape.
+func (c Circle) Contains(p Point) bool {
+	return c.Center.Distance(p) <= c.Radius
+}
+
+// String implements Shape.
+func (c Circle) String() string {
+	return "{$circle: [" + c.Center.String() + ", " + valueString(c.Radius) + "]}"
+}
+
+// Near matches locations within MaxDistance meters of a point. If MaxDistance
+// is nil, all locations match.
+type Near struct {
+	Field       string
+	Point       Point
+	MaxDistance *float64
+}
+
+// Match implements Expression interface.
+func (e Near) Match(payload map[string]interface{}) bool {
+	d, ok := e.Distance(payload)
+	return ok && (e.MaxDistance == nil || d <= *e.MaxDistance)
+}
+
+// Distance returns the distance in meters between the point and the location
+// held by the field in payload, or false if the field isn't a location.
+func (e Near) Distance(payload map[string]interface{}) (float64, bool) {
+	p, err := toPoint(getField(payload, e.Field))
+	if err != nil {
+		return 0, false
+	}
+	return e.Point.Distance(p), true
+}
+
+// Prepare implements Expression interface.
+func (e *Near) Prepare(validator schema.Validator) error {
+	return validateGeoField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Near) String() string {
+	s := quoteField(e.Field) + ": {" + opNear + ": {" + opGeometry + ": " + e.Point.String()
+	if e.MaxDistance != nil {
+		s += ", " + opMaxDistance + ": " + valueString(*e.MaxDistance)
+	}
+	return s + "}}"
+}
+
+// Within matches locations within a shape.
+type Within struct {
+	Field string
+	Shape Shape
+}
+
+// Match implements Expression interface.
+func (e Within) Match(payload map[string]interface{}) bool {
+	p, err := toPoint(getField(payload, e.Field))
+	return err == nil && e.Shape.Contains(p)
+}
+
+// Prepare implements Expression interface.
+func (e *Within) Prepare(validator schema.Validator) error {
+	return validateGeoField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Within) String() string {
+	return quoteField(e.Field) + ": {" + opWithin + ": " + e.Shape.String() + "}"
+}
+
+// validateGeoField checks field is a filterable schema.GeoPoint field.
+func validateGeoField(field string, validator schema.Validator) error {
+	f, err := getValidatorField(field, validator)
+	if err != nil {
+		return err
+	}
+	switch f.Validator.(type) {
+	case schema.GeoPoint, *schema.GeoPoint:
+		return nil
+	}
+	return fmt.Errorf("%s: not a geo point field", field)
+}
+
+// Distance returns the distance in meters between the point of the first Near
+// expression of the predicate and its field in payload, as used to sort on
+// DistanceField. If the predicate has no Near expression or the field isn't a
+// location, +Inf is returned.
+func (e Predicate) Distance(payload map[string]interface{}) float64 {
+	for _, exp := range e {
+		var near *Near
+		switch t := exp.(type) {
+		case *Near:
+			near = t
+		case *And:
+			if d := Predicate(*t).Distance(payload); !math.IsInf(d, 1) {
+				return d
+			}
+		}
+		if near != nil {
+			if d, ok := near.Distance(payload); ok {
+				return d
+			}
+		}
+	}
+	return math.Inf(1)
+}
+
+// parseNear parses the argument of a $near operator:
+//
+//	{$geometry: [lon, lat], $maxDistance: meters}
+func parseNear(field string, v Value) (*Near, error) {
+	m, ok := v.(map[string]Value)
+	if !ok {
+		return nil, errors.New("expected an object")
+	}
+	e := &Near{Field: field}
+	for k, arg := range m {
+		switch k {
+		case opGeometry:
+			p, err := toPoint(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			e.Point = p
+		case opMaxDistance:
+			d, ok := arg.(float64)
+			if !ok || d < 0 {
+				return nil, fmt.Errorf("%s: expected a positive number", k)
+			}
+			e.MaxDistance = &d
+		default:
+			return nil, fmt.Errorf("unknown argument %s", k)
+		}
+	}
+	if _, found := m[opGeometry]; !found {
+		return nil, fmt.Errorf("missing %s", opGeometry)
+	}
+	return e, nil
+}
+
+// parseWithin parses the argument of a $within operator:
+//
+//	{$box: [[lon, lat], [lon, lat]]}
+//	{$polygon: [[lon, lat], [lon, lat], [lon, lat]...]}
+//	{$circle: [[lon, lat], meters]}
+func parseWithin(field string, v Value) (*Within, error) {
+	m, ok := v.(map[string]Value)
+	if !ok || len(m) != 1 {
+		return nil, errors.New("expected an object with one shape")
+	}
+	for k, arg := range m {
+		values, ok := arg.([]Value)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected an array", k)
+		}
+		switch k {
+		case opBox:
+			if len(values) != 2 {
+				return nil, fmt.Errorf("%s: expected 2 points", k)
+			}
+			min, err := toPoint(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			max, err := toPoint(values[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			return &Within{Field: field, Shape: Box{Min: min, Max: max}}, nil
+		case opPolygon:
+			if len(values) < 3 {
+				return nil, fmt.Errorf("%s: expected at least 3 points", k)
+			}
+			poly := make(Polygon, len(values))
+			for i, v := range values {
+				p, err := toPoint(v)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", k, err)
+				}
+				poly[i] = p
+			}
+			return &Within{Field: field, Shape: poly}, nil
+		case opCircle:
+			if len(values) != 2 {
+				return nil, fmt.Errorf("%s: expected a point and a radius", k)
+			}
+			center, err := toPoint(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			radius, ok := values[1].(float64)
+			if !ok || radius < 0 {
+				return nil, fmt.Errorf("%s: expected a positive radius", k)
+			}
+			return &Within{Field: field, Shape: Circle{Center: center, Radius: radius}}, nil
+		default:
+			return nil, fmt.Errorf("unknown shape %s", k)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"math"
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeo(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"loc":  {Filterable: true, Validator: &schema.GeoPoint{}},
+		"name": {Filterable: true},
+		"pos":  {Validator: &schema.GeoPoint{}},
+	}}
+	paris := map[string]interface{}{"loc": map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.3522, 48.8566}}}
+	london := map[string]interface{}{"loc": map[string]interface{}{"type": "Point", "coordinates": []interface{}{-0.1276, 51.5072}}}
+	tokyo := map[string]interface{}{"loc": []interface{}{139.6917, 35.6895}}
+	none := map[string]interface{}{"name": "nowhere"}
+
+	d := Point{Lon: 2.3522, Lat: 48.8566}.Distance(Point{Lon: -0.1276, Lat: 51.5072})
+	assert.InDelta(t, 343500, d, 1000)
+
+	p := MustParsePredicate(`{loc: {$near: {$geometry: [2.35, 48.85], $maxDistance: 400000}}}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.True(t, p.Match(paris))
+	assert.True(t, p.Match(london))
+	assert.False(t, p.Match(tokyo))
+	assert.False(t, p.Match(none))
+	assert.True(t, p.Distance(paris) < p.Distance(london))
+	assert.True(t, math.IsInf(p.Distance(none), 1))
+	assert.True(t, math.IsInf(Predicate{}.Distance(paris), 1))
+
+	p = MustParsePredicate(`{loc: {$near: {$geometry: [2.3522, 48.8566], $maxDistance: 0}}}`)
+	assert.True(t, p.Match(paris))
+	assert.False(t, p.Match(london))
+
+	p = MustParsePredicate(`{loc: {$near: {$geometry: [2.35, 48.85]}}}`)
+	assert.True(t, p.Match(london))
+	assert.True(t, p.Match(tokyo))
+
+	p = MustParsePredicate(`{loc: {$within: {$box: [[-1, 48], [3, 52]]}}}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.True(t, p.Match(paris))
+	assert.True(t, p.Match(london))
+	assert.False(t, p.Match(tokyo))
+
+	// Boxes may cross the antimeridian.
+	p = MustParsePredicate(`{loc: {$within: {$box: [[130, 30], [-170, 40]]}}}`)
+	assert.True(t, p.Match(tokyo))
+	assert.False(t, p.Match(paris))
+
+	p = MustParsePredicate(`{loc: {$within: {$polygon: [[0, 48], [4, 48], [4, 50], [0, 50]]}}}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.True(t, p.Match(paris))
+	assert.False(t, p.Match(london))
+
+	p = MustParsePredicate(`{loc: {$within: {$circle: [[-0.12, 51.5], 10000]}}}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.False(t, p.Match(paris))
+	assert.True(t, p.Match(london))
+
+	for query, err := range map[string]string{
+		`{name: {$near: {$geometry: [0, 0]}}}`:       "name: not a geo point field",
+		`{pos: {$within: {$box: [[0, 0], [1, 1]]}}}`: "pos: field is not filterable",
+		`{foo: {$near: {$geometry: [0, 0]}}}`:        "foo: unknown query field",
+	} {
+		assert.EqualError(t, MustParsePredicate(query).Prepare(s), err, query)
+	}
+}
@@ -0,0 +1,338 @@
+package query
+
+import (
+	"sort"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+// Normalize returns an equivalent predicate in a canonical form:
+//
+//   - nested $and are flattened into the predicate, and nested $or into their
+//     parent $or;
+//   - duplicate expressions are removed and expressions are sorted, as well as
+//     the values of $in, $nin and $all;
+//   - range expressions on the same field are merged into the narrowest lower
+//     and upper bounds;
+//   - $in and $nin with a single value are folded into an equality or a
+//     non-equality.
+//
+// If the predicate can never match, as with contradictory ranges or an empty
+// $in, the returned predicate is an empty $or and IsFalse returns true.
+//
+// The String representation of two normalized predicates differing only by
+// the order or the nesting of their expressions is the same, making it usable
+// as a cache key. The predicate is not modified.
+func (e Predicate) Normalize() Predicate {
+	exps, ok := normalizeAnd(e)
+	if !ok {
+		return Predicate{&Or{}}
+	}
+	return Predicate(exps)
+}
+
+// IsFalse returns true if the predicate can never match because it contains an
+// empty $or or an empty $in, as produced by Normalize for contradictory
+// predicates.
+func (e Predicate) IsFalse() bool {
+	for _, exp := range e {
+		switch t := exp.(type) {
+		case *Or:
+			if len(*t) == 0 {
+				return true
+			}
+		case *In:
+			if len(t.Values) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeAnd normalizes expressions joined by a logical AND. It returns false
+// if the expressions can never match.
+func normalizeAnd(exps []Expression) ([]Expression, bool) {
+	out := make([]Expression, 0, len(exps))
+	for _, exp := range exps {
+		switch t := exp.(type) {
+		case *And:
+			sub, ok := normalizeAnd(*t)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, sub...)
+		case *Or:
+			alts, always := normalizeOr(*t)
+			switch {
+			case always:
+				// An alternative always matches, the $or is a no-op.
+			case len(alts) == 0:
+				return nil, false
+			case len(alts) == 1:
+				if and, ok := alts[0].(*And); ok {
+					out = append(out, (*and)...)
+				} else {
+					out = append(out, alts[0])
+				}
+			default:
+				or := Or(alts)
+				out = append(out, &or)
+			}
+		default:
+			n, ok := normalizeExpression(exp)
+			if !ok {
+				return nil, false
+			}
+			if n != nil {
+				out = append(out, n)
+			}
+		}
+	}
+	out, ok := mergeRanges(out)
+	if !ok {
+		return nil, false
+	}
+	return sortExpressions(out), true
+}
+
+// normalizeOr normalizes expressions joined by a logical OR. Alternatives that
+// can never match are removed. It returns true if one of the alternatives
+// always matches.
+func normalizeOr(exps []Expression) (alts []Expression, always bool) {
+	alts = make([]Expression, 0, len(exps))
+	for _, exp := range exps {
+		sub, ok := normalizeAnd([]Expression{exp})
+		if !ok {
+			continue
+		}
+		switch len(sub) {
+		case 0:
+			return nil, true
+		case 1:
+			if or, ok := sub[0].(*Or); ok {
+				alts = append(alts, (*or)...)
+			} else {
+				alts = append(alts, sub[0])
+			}
+		default:
+			and := And(sub)
+			alts = append(alts, &and)
+		}
+	}
+	return sortExpressions(alts), false
+}
+
+// normalizeExpression normalizes a single expression. It returns a nil
+// expression if exp always matches, and false if it never matches.
+func normalizeExpression(exp Expression) (Expression, bool) {
+	switch t := exp.(type) {
+	case *In:
+		switch {
+		case len(t.Values) == 0:
+			return nil, false
+		case len(t.Values) == 1 && !isArray(t.Values[0]):
+			return &Equal{Field: t.Field, Value: t.Values[0]}, true
+		}
+		return &In{Field: t.Field, Values: sortValues(t.Values)}, true
+	case *NotIn:
+		switch {
+		case len(t.Values) == 0:
+			return nil, true
+		case len(t.Values) == 1 && !isArray(t.Values[0]):
+			return &NotEqual{Field: t.Field, Value: t.Values[0]}, true
+		}
+		return &NotIn{Field: t.Field, Values: sortValues(t.Values)}, true
+	case *All:
+		return &All{Field: t.Field, Values: sortValues(t.Values)}, true
+	case *ElemMatch:
+		exps, ok := normalizeAnd(t.Exps)
+		if !ok {
+			return nil, false
+		}
+		return &ElemMatch{Field: t.Field, Exps: exps}, true
+	}
+	return exp, true
+}
+
+func isArray(v Value) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// sortValues returns a sorted copy of values without duplicates.
+func sortValues(values []Value) []Value {
+	type entry struct {
+		key   string
+		value Value
+	}
+	entries := make([]entry, 0, len(values))
+	for _, v := range values {
+		entries = append(entries, entry{valueString(v), v})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	sorted := make([]Value, 0, len(entries))
+	for i, e := range entries {
+		if i > 0 && e.key == entries[i-1].key {
+			continue
+		}
+		sorted = append(sorted, e.value)
+	}
+	return sorted
+}
+
+// sortExpressions sorts exps by their string representation and removes
+// duplicates.
+func sortExpressions(exps []Expression) []Expression {
+	type entry struct {
+		key string
+		exp Expression
+	}
+	entries := make([]entry, 0, len(exps))
+	for _, exp := range exps {
+		entries = append(entries, entry{exp.String(), exp})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	sorted := make([]Expression, 0, len(entries))
+	for i, e := range entries {
+		if i > 0 && e.key == entries[i-1].key {
+			continue
+		}
+		sorted = append(sorted, e.exp)
+	}
+	return sorted
+}
+
+// bound is the lower or upper bound of a range expression.
+type bound struct {
+	exp    Expression
+	value  Value
+	less   schema.LessFunc
+	strict bool
+}
+
+// compare returns -1, 0 or 1 if b's value is respectively lower, equal or
+// greater than o's, or false if the values can't be compared.
+func (b bound) compare(o bound) (int, bool) {
+	if b.less != nil {
+		switch {
+		case b.less(b.value, o.value):
+			return -1, true
+		case b.less(o.value, b.value):
+			return 1, true
+		}
+		return 0, true
+	}
+	r := typeRank(b.value)
+	if r != typeRank(o.value) || (r != 2 && r != 3 && r != 4) {
+		// Only numbers, strings and times have a natural order.
+		return 0, false
+	}
+	return compareValues(b.value, o.value), true
+}
+
+// rangeBounds returns the bound represented by exp if it's a range
+// expression.
+func rangeBounds(exp Expression) (field string, b bound, lower bool, ok bool) {
+	switch t := exp.(type) {
+	case *GreaterThan:
+		return t.Field, bound{exp, t.Value, t.less, true}, true, true
+	case *GreaterOrEqual:
+		return t.Field, bound{exp, t.Value, t.less, false}, true, true
+	case *LowerThan:
+		return t.Field, bound{exp, t.Value, t.less, true}, false, true
+	case *LowerOrEqual:
+		return t.Field, bound{exp, t.Value, t.less, false}, false, true
+	}
+	return "", bound{}, false, false
+}
+
+// mergeRanges replaces the range expressions on the same field by the
+// narrowest lower and upper bounds. It returns false if the bounds of a field
+// are contradictory. Fields with bounds that can't be compared are left as is.
+func mergeRanges(exps []Expression) ([]Expression, bool) {
+	type bounds struct {
+		lower, upper []bound
+	}
+	fields := map[string]*bounds{}
+	for _, exp := range exps {
+		field, b, lower, ok := rangeBounds(exp)
+		if !ok {
+			continue
+		}
+		fb := fields[field]
+		if fb == nil {
+			fb = &bounds{}
+			fields[field] = fb
+		}
+		if lower {
+			fb.lower = append(fb.lower, b)
+		} else {
+			fb.upper = append(fb.upper, b)
+		}
+	}
+	drop := map[Expression]bool{}
+	for _, fb := range fields {
+		if len(fb.lower)+len(fb.upper) < 2 {
+			continue
+		}
+		lower, ok := narrowest(fb.lower, 1)
+		if !ok {
+			continue
+		}
+		upper, ok := narrowest(fb.upper, -1)
+		if !ok {
+			continue
+		}
+		if lower != nil && upper != nil {
+			c, ok := lower.compare(*upper)
+			if !ok {
+				continue
+			}
+			if c > 0 || (c == 0 && (lower.strict || upper.strict)) {
+				return nil, false
+			}
+		}
+		for _, b := range append(fb.lower, fb.upper...) {
+			if (lower == nil || b.exp != lower.exp) && (upper == nil || b.exp != upper.exp) {
+				drop[b.exp] = true
+			}
+		}
+	}
+	if len(drop) == 0 {
+		return exps, true
+	}
+	out := make([]Expression, 0, len(exps)-len(drop))
+	for _, exp := range exps {
+		if !drop[exp] {
+			out = append(out, exp)
+		}
+	}
+	return out, true
+}
+
+// narrowest returns the bound of bs with the greatest (dir 1) or lowest (dir
+// -1) value, preferring strict bounds on equal values. It returns false if the
+// values can't be compared.
+func narrowest(bs []bound, dir int) (*bound, bool) {
+	var best *bound
+	for i := range bs {
+		b := &bs[i]
+		if best == nil {
+			best = b
+			continue
+		}
+		c, ok := b.compare(*best)
+		if !ok {
+			return nil, false
+		}
+		if c == dir || (c == 0 && b.strict && !best.strict) {
+			best = b
+		}
+	}
+	return best, true
+}
@@ -0,0 +1,66 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		`{}`:           `{}`,
+		`{b: 1, a: 1}`: `{a: 1, b: 1}`,
+		`{a: 1, $and: [{b: 2}, {$and: [{c: 3}, {a: 1}]}]}`:    `{a: 1, b: 2, c: 3}`,
+		`{$or: [{a: 1}, {$or: [{c: 3}, {b: 2}]}]}`:            `{$or: [{a: 1}, {b: 2}, {c: 3}]}`,
+		`{$or: [{a: 1}, {a: 1}]}`:                             `{a: 1}`,
+		`{$or: [{$and: [{a: 1}, {b: 2}]}], c: 3}`:             `{a: 1, b: 2, c: 3}`,
+		`{$or: [{a: 1}, {c: {$nin: []}}], b: 2}`:              `{b: 2}`,
+		`{$or: [{a: {$in: []}}, {b: 2}]}`:                     `{b: 2}`,
+		`{a: {$in: [1]}}`:                                     `{a: 1}`,
+		`{a: {$in: [[1, 2]]}}`:                                `{a: {$in: [[1,2]]}}`,
+		`{a: {$nin: ["x"]}}`:                                  `{a: {$ne: "x"}}`,
+		`{a: {$nin: []}}`:                                     `{}`,
+		`{a: {$in: ["c", "a", "b", "a"]}}`:                    `{a: {$in: ["a", "b", "c"]}}`,
+		`{a: {$all: [2, 1]}}`:                                 `{a: {$all: [1, 2]}}`,
+		`{a: {$gt: 1}, b: 0, a: {$gte: 3}, a: {$lt: 10}}`:     `{a: {$gte: 3}, a: {$lt: 10}, b: 0}`,
+		`{a: {$gte: 3}, a: {$gt: 3}}`:                         `{a: {$gt: 3}}`,
+		`{a: {$lte: 5}, a: {$lt: 7}, a: {$lte: 5}}`:           `{a: {$lte: 5}}`,
+		`{a: {$gte: 5}, a: {$lte: 5}}`:                        `{a: {$gte: 5}, a: {$lte: 5}}`,
+		`{a: {$gt: "b"}, a: {$gt: "a"}}`:                      `{a: {$gt: "b"}}`,
+		`{a: {$gt: "b"}, a: {$gt: 1}}`:                        `{a: {$gt: "b"}, a: {$gt: 1}}`,
+		`{a: {$gt: 5}, a: {$lt: 5}}`:                          `{$or: []}`,
+		`{a: {$gte: 5}, a: {$lt: 5}}`:                         `{$or: []}`,
+		`{a: {$gt: 6}, a: {$lte: 5}}`:                         `{$or: []}`,
+		`{a: {$in: []}, b: 1}`:                                `{$or: []}`,
+		`{$or: [{a: {$gt: 2}, a: {$lt: 1}}, {a: {$in: []}}]}`: `{$or: []}`,
+		`{a: {$elemMatch: {b: {$gt: 2}, b: {$gt: 1}}}}`:       `{a: {$elemMatch: {b: {$gt: 2}}}}`,
+		`{a: {$elemMatch: {b: {$gt: 2}, b: {$lt: 1}}}, c: 1}`: `{$or: []}`,
+	}
+	for query, want := range tests {
+		p := MustParsePredicate(query)
+		before := p.String()
+		n := p.Normalize()
+		assert.Equal(t, want, n.String(), query)
+		assert.Equal(t, want == `{$or: []}`, n.IsFalse(), query)
+		assert.Equal(t, before, p.String(), "%s: predicate modified", query)
+	}
+
+	// Equivalent predicates share the same canonical string.
+	assert.Equal(t,
+		MustParsePredicate(`{b: {$in: [2, 1]}, $and: [{a: {$gt: 1}}, {a: {$gt: 0}}]}`).Normalize().String(),
+		MustParsePredicate(`{a: {$gt: 1}, b: {$in: [1, 2]}}`).Normalize().String())
+}
+
+func TestNormalizePrepared(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"a": {Filterable: true, Validator: &schema.Integer{}},
+	}}
+	p := MustParsePredicate(`{a: {$gt: 1}, a: {$gte: 4}, a: {$lt: 10}}`)
+	assert.NoError(t, p.Prepare(s))
+	n := p.Normalize()
+	assert.Equal(t, `{a: {$gte: 4}, a: {$lt: 10}}`, n.String())
+	// Merged expressions keep their prepared state.
+	assert.True(t, n.Match(map[string]interface{}{"a": 4}))
+	assert.False(t, n.Match(map[string]interface{}{"a": 3}))
+}
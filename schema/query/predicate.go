@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/rs/rest-layer/schema"
@@ -23,6 +24,22 @@ const (
 	opRegex          = "$regex"
 	opElemMatch      = "$elemMatch"
 	opNot            = "$not"
+	opText           = "$text"
+	opNear           = "$near"
+	opWithin         = "$within"
+	opGeometry       = "$geometry"
+	opMaxDistance    = "$maxDistance"
+	opBox            = "$box"
+	opPolygon        = "$polygon"
+	opCircle         = "$circle"
+	opSize           = "$size"
+	opAll            = "$all"
+	opStartsWith     = "$startsWith"
+	opEndsWith       = "$endsWith"
+	opContains       = "$contains"
+	opEqualFold      = "$ieq"
+	opMod            = "$mod"
+	opType           = "$type"
 )
 
 // Predicate defines an expression against a schema to perform a match on schema's data.
@@ -489,8 +506,8 @@ func (e Regex) Match(payload map[string]interface{}) bool {
 
 // Prepare implements Expression interface.
 func (e *Regex) Prepare(validator schema.Validator) error {
-	_, err := prepareValue(e.Field, e.Value.String(), validator)
-	return err
+	pattern := e.Value.String()
+	return preparePattern(e.Field, &pattern, validator)
 }
 
 // String implements Expression interface.
@@ -560,3 +577,278 @@ func (e ElemMatch) String() string {
 	}
 	return quoteField(e.Field) + ": {" + opElemMatch + ": {" + strings.Join(s, ", ") + "}}"
 }
+
+// Size matches arrays with the specified number of elements.
+type Size struct {
+	Field string
+	Value int
+}
+
+// Match implements Expression interface.
+func (e Size) Match(payload map[string]interface{}) bool {
+	arr, ok := getField(payload, e.Field).([]interface{})
+	return ok && len(arr) == e.Value
+}
+
+// Prepare implements Expression interface.
+func (e *Size) Prepare(validator schema.Validator) error {
+	return validateArrayField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Size) String() string {
+	return quoteField(e.Field) + ": {" + opSize + ": " + strconv.Itoa(e.Value) + "}"
+}
+
+// All matches arrays containing all the specified values.
+type All struct {
+	Field  string
+	Values []Value
+}
+
+// Match implements Expression interface.
+func (e All) Match(payload map[string]interface{}) bool {
+	arr, ok := getField(payload, e.Field).([]interface{})
+	if !ok {
+		return false
+	}
+	for _, v := range e.Values {
+		found := false
+		for _, vv := range arr {
+			if reflect.DeepEqual(v, vv) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Prepare implements Expression interface.
+func (e *All) Prepare(validator schema.Validator) error {
+	if err := validateArrayField(e.Field, validator); err != nil {
+		return err
+	}
+	return prepareValues(e.Field, e.Values, validator)
+}
+
+// String implements Expression interface.
+func (e All) String() string {
+	s := make([]string, 0, len(e.Values))
+	for _, v := range e.Values {
+		s = append(s, valueString(v))
+	}
+	return quoteField(e.Field) + ": {" + opAll + ": [" + strings.Join(s, ", ") + "]}"
+}
+
+// StartsWith matches string values starting with a specified prefix.
+type StartsWith struct {
+	Field string
+	Value string
+}
+
+// Match implements Expression interface.
+func (e StartsWith) Match(payload map[string]interface{}) bool {
+	return matchString(getField(payload, e.Field), func(s string) bool {
+		return strings.HasPrefix(s, e.Value)
+	})
+}
+
+// Prepare implements Expression interface.
+func (e *StartsWith) Prepare(validator schema.Validator) error {
+	return preparePattern(e.Field, &e.Value, validator)
+}
+
+// String implements Expression interface.
+func (e StartsWith) String() string {
+	return quoteField(e.Field) + ": {" + opStartsWith + ": " + valueString(e.Value) + "}"
+}
+
+// EndsWith matches string values ending with a specified suffix.
+type EndsWith struct {
+	Field string
+	Value string
+}
+
+// Match implements Expression interface.
+func (e EndsWith) Match(payload map[string]interface{}) bool {
+	return matchString(getField(payload, e.Field), func(s string) bool {
+		return strings.HasSuffix(s, e.Value)
+	})
+}
+
+// Prepare implements Expression interface.
+func (e *EndsWith) Prepare(validator schema.Validator) error {
+	return preparePattern(e.Field, &e.Value, validator)
+}
+
+// String implements Expression interface.
+func (e EndsWith) String() string {
+	return quoteField(e.Field) + ": {" + opEndsWith + ": " + valueString(e.Value) + "}"
+}
+
+// Contains matches string values containing a specified substring.
+type Contains struct {
+	Field string
+	Value string
+}
+
+// Match implements Expression interface.
+func (e Contains) Match(payload map[string]interface{}) bool {
+	return matchString(getField(payload, e.Field), func(s string) bool {
+		return strings.Contains(s, e.Value)
+	})
+}
+
+// Prepare implements Expression interface.
+func (e *Contains) Prepare(validator schema.Validator) error {
+	return preparePattern(e.Field, &e.Value, validator)
+}
+
+// String implements Expression interface.
+func (e Contains) String() string {
+	return quoteField(e.Field) + ": {" + opContains + ": " + valueString(e.Value) + "}"
+}
+
+// EqualFold matches string values equal to a specified value, ignoring case.
+type EqualFold struct {
+	Field string
+	Value string
+}
+
+// Match implements Expression interface.
+func (e EqualFold) Match(payload map[string]interface{}) bool {
+	return matchString(getField(payload, e.Field), func(s string) bool {
+		return strings.EqualFold(s, e.Value)
+	})
+}
+
+// Prepare implements Expression interface.
+func (e *EqualFold) Prepare(validator schema.Validator) error {
+	return prepareString(e.Field, &e.Value, validator)
+}
+
+// String implements Expression interface.
+func (e EqualFold) String() string {
+	return quoteField(e.Field) + ": {" + opEqualFold + ": " + valueString(e.Value) + "}"
+}
+
+// matchString calls match with value if it's a string, or with each string
+// element of value if it's an array, and returns true on first match.
+func matchString(value interface{}, match func(s string) bool) bool {
+	switch t := value.(type) {
+	case string:
+		return match(t)
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && match(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Mod matches numeric values for which the division by Divisor gives
+// Remainder. Floats are truncated before the division.
+type Mod struct {
+	Field     string
+	Divisor   int64
+	Remainder int64
+}
+
+// Match implements Expression interface.
+func (e Mod) Match(payload map[string]interface{}) bool {
+	n, ok := isNumber(getField(payload, e.Field))
+	if !ok || e.Divisor == 0 {
+		return false
+	}
+	return int64(n)%e.Divisor == e.Remainder
+}
+
+// Prepare implements Expression interface.
+func (e *Mod) Prepare(validator schema.Validator) error {
+	f, err := getValidatorField(e.Field, validator)
+	if err != nil {
+		return err
+	}
+	switch f.Validator.(type) {
+	case nil, schema.Integer, *schema.Integer, schema.Float, *schema.Float:
+		return nil
+	}
+	return fmt.Errorf("%s: field is not numeric", e.Field)
+}
+
+// String implements Expression interface.
+func (e Mod) String() string {
+	return quoteField(e.Field) + ": {" + opMod + ": [" +
+		strconv.FormatInt(e.Divisor, 10) + ", " + strconv.FormatInt(e.Remainder, 10) + "]}"
+}
+
+// Types supported by the Type expression.
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// Type matches values of a specified JSON type. Missing fields never match.
+type Type struct {
+	Field string
+	Type  string
+}
+
+// Match implements Expression interface.
+func (e Type) Match(payload map[string]interface{}) bool {
+	value, found := getFieldExist(payload, e.Field)
+	return found && valueType(value) == e.Type
+}
+
+// Prepare implements Expression interface.
+func (e *Type) Prepare(validator schema.Validator) error {
+	if !isType(e.Type) {
+		return fmt.Errorf("%s: unknown type %q", e.Field, e.Type)
+	}
+	return validateField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Type) String() string {
+	return quoteField(e.Field) + ": {" + opType + ": " + valueString(e.Type) + "}"
+}
+
+// isType returns true if t is one of the types supported by Type.
+func isType(t string) bool {
+	switch t {
+	case TypeNull, TypeBoolean, TypeNumber, TypeString, TypeArray, TypeObject:
+		return true
+	}
+	return false
+}
+
+// valueType returns the JSON type of v, or an empty string if v has no JSON
+// counterpart.
+func valueType(v interface{}) string {
+	if _, ok := isNumber(v); ok {
+		return TypeNumber
+	}
+	switch v.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	}
+	return ""
+}
@@ -3,10 +3,14 @@ package query
 import (
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 )
 
+// maxInteger is the largest integer exactly representable by a parsed number.
+const maxInteger = 1 << 53
+
 type predicateParser struct {
 	query string
 	pos   int
@@ -83,6 +87,7 @@ func (p *predicateParser) parseExpressions() ([]Expression, error) {
 // Examples of expressions:
 //   foo: "bar"
 //   $or: [{foo: "bar"}, {foo: "baz"}]
+//   $text: "foo bar"
 //   foo: {$exists: true}
 func (p *predicateParser) parseExpression() (Expression, error) {
 	oldPos := p.pos
@@ -106,8 +111,16 @@ func (p *predicateParser) parseExpression() (Expression, error) {
 		}
 		or := Or(subExp)
 		return &or, nil
+	case opText:
+		search, err := p.parseString()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", label, err)
+		}
+		return &Text{Search: search}, nil
 	case opExists, opIn, opNotIn, opNotEqual, opRegex, opElemMatch,
-		opLowerThan, opLowerOrEqual, opGreaterThan, opGreaterOrEqual, opNot:
+		opLowerThan, opLowerOrEqual, opGreaterThan, opGreaterOrEqual, opNot,
+		opNear, opWithin, opSize, opAll, opStartsWith, opEndsWith, opContains,
+		opEqualFold, opMod, opType:
 		p.pos = oldPos
 		return nil, fmt.Errorf("%s: invalid placement", label)
 	default:
@@ -165,6 +178,7 @@ func (p *predicateParser) parseSubExpressions() ([]Expression, error) {
 //   {$exist: true}
 //   {$ne: "foo"}
 //   {$in: ["foo", "bar"]}
+//   {$text: "foo bar"}
 func (p *predicateParser) parseCommand(field string) (Expression, error) {
 	oldPos := p.pos
 	if p.expect('{') {
@@ -259,6 +273,114 @@ func (p *predicateParser) parseCommand(field string) (Expression, error) {
 				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
 			}
 			return &ElemMatch{Field: field, Exps: exps}, nil
+		case opText:
+			search, err := p.parseString()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Text{Field: field, Search: search}, nil
+		case opNear, opWithin:
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			var e Expression
+			if label == opNear {
+				e, err = parseNear(field, v)
+			} else {
+				e, err = parseWithin(field, v)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			return e, nil
+		case opSize:
+			n, err := p.parseNumber()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			if n < 0 || n > maxInteger || n != math.Trunc(n) {
+				return nil, fmt.Errorf("%s: expected a positive integer", label)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Size{Field: field, Value: int(n)}, nil
+		case opAll:
+			values, err := p.parseValues()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &All{Field: field, Values: values}, nil
+		case opStartsWith, opEndsWith, opContains, opEqualFold:
+			str, err := p.parseString()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			switch label {
+			case opStartsWith:
+				return &StartsWith{Field: field, Value: str}, nil
+			case opEndsWith:
+				return &EndsWith{Field: field, Value: str}, nil
+			case opContains:
+				return &Contains{Field: field, Value: str}, nil
+			case opEqualFold:
+				return &EqualFold{Field: field, Value: str}, nil
+			}
+		case opMod:
+			values, err := p.parseValues()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			if len(values) != 2 {
+				return nil, fmt.Errorf("%s: expected [divisor, remainder]", label)
+			}
+			var args [2]int64
+			for i, v := range values {
+				n, ok := v.(float64)
+				if !ok || math.Abs(n) > maxInteger || n != math.Trunc(n) {
+					return nil, fmt.Errorf("%s: item #%d: expected an integer", label, i)
+				}
+				args[i] = int64(n)
+			}
+			if args[0] == 0 {
+				return nil, fmt.Errorf("%s: divisor cannot be 0", label)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Mod{Field: field, Divisor: args[0], Remainder: args[1]}, nil
+		case opType:
+			typ, err := p.parseString()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			if !isType(typ) {
+				return nil, fmt.Errorf("%s: unknown type %q", label, typ)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Type{Field: field, Type: typ}, nil
 		}
 	}
 VALUE:
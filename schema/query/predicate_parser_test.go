@@ -143,6 +143,66 @@ func TestParse(t *testing.T) {
 			Predicate{&ElemMatch{Field: "foo", Exps: []Expression{&Equal{Field: "bar", Value: "one"}, &Equal{Field: "baz", Value: "two"}}}},
 			nil,
 		},
+		{
+			`{"$text": "quick fox"}`,
+			Predicate{&Text{Search: "quick fox"}},
+			nil,
+		},
+		{
+			`{"foo": {"$text": "quick fox"}}`,
+			Predicate{&Text{Field: "foo", Search: "quick fox"}},
+			nil,
+		},
+		{
+			`{"loc": {"$near": {"$geometry": {"type": "Point", "coordinates": [2.35, 48.85]}, "$maxDistance": 1000}}}`,
+			Predicate{&Near{Field: "loc", Point: Point{Lon: 2.35, Lat: 48.85}, MaxDistance: float64Ptr(1000)}},
+			nil,
+		},
+		{
+			`{"loc": {"$within": {"$box": [[2, 48], [3, 49]]}}}`,
+			Predicate{&Within{Field: "loc", Shape: Box{Min: Point{Lon: 2, Lat: 48}, Max: Point{Lon: 3, Lat: 49}}}},
+			nil,
+		},
+		{
+			`{"loc": {"$within": {"$polygon": [[0, 0], [1, 0], [1, 1]]}}}`,
+			Predicate{&Within{Field: "loc", Shape: Polygon{{Lon: 0, Lat: 0}, {Lon: 1, Lat: 0}, {Lon: 1, Lat: 1}}}},
+			nil,
+		},
+		{
+			`{"loc": {"$within": {"$circle": [{"lat": 48.85, "lon": 2.35}, 500]}}}`,
+			Predicate{&Within{Field: "loc", Shape: Circle{Center: Point{Lon: 2.35, Lat: 48.85}, Radius: 500}}},
+			nil,
+		},
+		{
+			`{"foo": {"$size": 0}}`,
+			Predicate{&Size{Field: "foo", Value: 0}},
+			nil,
+		},
+		{
+			`{"foo": {"$all": ["bar", 1]}}`,
+			Predicate{&All{Field: "foo", Values: []Value{"bar", 1.0}}},
+			nil,
+		},
+		{
+			`{"foo": {"$startsWith": "bar"}, "bar": {"$endsWith": "baz"}}`,
+			Predicate{&StartsWith{Field: "foo", Value: "bar"}, &EndsWith{Field: "bar", Value: "baz"}},
+			nil,
+		},
+		{
+			`{"foo": {"$contains": "bar"}, "bar": {"$ieq": "Baz"}}`,
+			Predicate{&Contains{Field: "foo", Value: "bar"}, &EqualFold{Field: "bar", Value: "Baz"}},
+			nil,
+		},
+		{
+			`{"foo": {"$mod": [4, 0]}}`,
+			Predicate{&Mod{Field: "foo", Divisor: 4, Remainder: 0}},
+			nil,
+		},
+		{
+			`{"foo": {"$type": "object"}}`,
+			Predicate{&Type{Field: "foo", Type: "object"}},
+			nil,
+		},
 		{
 			`{`,
 			Predicate{},
@@ -371,6 +431,71 @@ func TestParse(t *testing.T) {
 			Predicate{},
 			errors.New("char 1: $not: invalid placement"),
 		},
+		{
+			`{"$near": [0, 0]}`,
+			Predicate{},
+			errors.New("char 1: $near: invalid placement"),
+		},
+		{
+			`{"$size": 1}`,
+			Predicate{},
+			errors.New("char 1: $size: invalid placement"),
+		},
+		{
+			`{"foo": {"$size": 1.5}}`,
+			Predicate{},
+			errors.New("char 21: foo: $size: expected a positive integer"),
+		},
+		{
+			`{"foo": {"$startsWith": 1}}`,
+			Predicate{},
+			errors.New("char 24: foo: $startsWith: not a string"),
+		},
+		{
+			`{"foo": {"$mod": [0, 1]}}`,
+			Predicate{},
+			errors.New("char 23: foo: $mod: divisor cannot be 0"),
+		},
+		{
+			`{"foo": {"$mod": [4]}}`,
+			Predicate{},
+			errors.New("char 20: foo: $mod: expected [divisor, remainder]"),
+		},
+		{
+			`{"foo": {"$mod": [4, 1.5]}}`,
+			Predicate{},
+			errors.New("char 25: foo: $mod: item #1: expected an integer"),
+		},
+		{
+			`{"foo": {"$type": "date"}}`,
+			Predicate{},
+			errors.New("char 24: foo: $type: unknown type \"date\""),
+		},
+		{
+			`{"loc": {"$near": {"$geometry": [2.35, 48.85], "$maxDistance": -1}}}`,
+			Predicate{},
+			errors.New("char 67: loc: $near: $maxDistance: expected a positive number"),
+		},
+		{
+			`{"loc": {"$near": {"$maxDistance": 10}}}`,
+			Predicate{},
+			errors.New("char 39: loc: $near: missing $geometry"),
+		},
+		{
+			`{"loc": {"$near": {"$geometry": [200, 0]}}}`,
+			Predicate{},
+			errors.New("char 42: loc: $near: $geometry: longitude 200 out of bounds [-180, 180]"),
+		},
+		{
+			`{"loc": {"$within": {"$square": [[0, 0], [1, 1]]}}}`,
+			Predicate{},
+			errors.New("char 50: loc: $within: unknown shape $square"),
+		},
+		{
+			`{"loc": {"$within": {"$polygon": [[0, 0], [1, 1]]}}}`,
+			Predicate{},
+			errors.New("char 51: loc: $within: $polygon: expected at least 3 points"),
+		},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -406,3 +531,7 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
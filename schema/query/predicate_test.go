@@ -206,6 +206,86 @@ func TestMatch(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			`{"foo": {$size: 2}}`, []test{
+				{map[string]interface{}{"foo": []interface{}{"bar", "baz"}}, true},
+				{map[string]interface{}{"foo": []interface{}{"bar"}}, false},
+				{map[string]interface{}{"foo": "ba"}, false},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$all: ["bar", "baz"]}}`, []test{
+				{map[string]interface{}{"foo": []interface{}{"baz", "tar", "bar"}}, true},
+				{map[string]interface{}{"foo": []interface{}{"bar"}}, false},
+				{map[string]interface{}{"foo": "bar"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$startsWith: "ba"}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+				{map[string]interface{}{"foo": "Bar"}, false},
+				{map[string]interface{}{"foo": []interface{}{"tar", "baz"}}, true},
+				{map[string]interface{}{"foo": 1}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$endsWith: "ar"}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+				{map[string]interface{}{"foo": "baz"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$contains: "a"}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+				{map[string]interface{}{"foo": "foo"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$ieq: "BAR"}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+				{map[string]interface{}{"foo": "Bar"}, true},
+				{map[string]interface{}{"foo": "baz"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$mod: [4, 1]}}`, []test{
+				{map[string]interface{}{"foo": 5}, true},
+				{map[string]interface{}{"foo": 5.5}, true},
+				{map[string]interface{}{"foo": 8}, false},
+				{map[string]interface{}{"foo": "5"}, false},
+			},
+			&schemaFooInteger,
+		},
+		{
+			`{"foo": {$type: "string"}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+				{map[string]interface{}{"foo": 1}, false},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$type: "null"}}`, []test{
+				{map[string]interface{}{"foo": nil}, true},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {$type: "number"}}`, []test{
+				{map[string]interface{}{"foo": 1}, true},
+				{map[string]interface{}{"foo": 1.5}, true},
+				{map[string]interface{}{"foo": []interface{}{1}}, false},
+			},
+			nil,
+		},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -233,25 +313,42 @@ func TestMatch(t *testing.T) {
 
 func TestString(t *testing.T) {
 	tests := map[string]string{
-		`{"foo": "bar"}`:                                          `{foo: "bar"}`,
-		`{"foo": {"$ne": "bar"}}`:                                 `{foo: {$ne: "bar"}}`,
-		`{"foo": {"$exists": true}}`:                              `{foo: {$exists: true}}`,
-		`{"foo": {"$exists": false}}`:                             `{foo: {$exists: false}}`,
-		`{"bar": {"$gt": 1}}`:                                     `{bar: {$gt: 1}}`,
-		`{"bar": {"$gte": 2}}`:                                    `{bar: {$gte: 2}}`,
-		`{"bar": {"$lt": 2}}`:                                     `{bar: {$lt: 2}}`,
-		`{"bar": {"$lte": 1}}`:                                    `{bar: {$lte: 1}}`,
-		`{"foo": {"$in": ["bar", "baz"]}}`:                        `{foo: {$in: ["bar", "baz"]}}`,
-		`{"foo": {"$nin": ["bar", "baz"]}}`:                       `{foo: {$nin: ["bar", "baz"]}}`,
-		`{"$or": [{"foo": "bar"}, {"bar": 1}]}`:                   `{$or: [{foo: "bar"}, {bar: 1}]}`,
-		`{"$and": [{"foo": "bar"}, {"bar": 1}]}`:                  `{$and: [{foo: "bar"}, {bar: 1}]}`,
-		`{"foo": {"$regex": "rege[x]{1}.+some"}}`:                 `{foo: {$regex: "rege[x]{1}.+some"}}`,
-		`{"foo": {"$regex": "^(?i)my.+-rest.+$"}}`:                `{foo: {$regex: "^(?i)my.+-rest.+$"}}`,
-		`{"$and": [{"foo": "bar"}, {"foo": "baz"}]}`:              `{$and: [{foo: "bar"}, {foo: "baz"}]}`,
-		`{"foo": "bar", "$or": [{"bar": "baz"}, {"bar": "foo"}]}`: `{foo: "bar", $or: [{bar: "baz"}, {bar: "foo"}]}`,
-		`{"foo": ["bar", "baz"]}`:                                 `{foo: ["bar","baz"]}`,
-		`{"foo.bar": "baz"}`:                                      `{foo.bar: "baz"}`,
-		`{"foo":{"$elemMatch":{"a":"bar","b":"baz"}}}`:            `{foo: {$elemMatch: {a: "bar", b: "baz"}}}`,
+		`{"foo": "bar"}`:                                                  `{foo: "bar"}`,
+		`{"foo": {"$ne": "bar"}}`:                                         `{foo: {$ne: "bar"}}`,
+		`{"foo": {"$exists": true}}`:                                      `{foo: {$exists: true}}`,
+		`{"foo": {"$exists": false}}`:                                     `{foo: {$exists: false}}`,
+		`{"bar": {"$gt": 1}}`:                                             `{bar: {$gt: 1}}`,
+		`{"bar": {"$gte": 2}}`:                                            `{bar: {$gte: 2}}`,
+		`{"bar": {"$lt": 2}}`:                                             `{bar: {$lt: 2}}`,
+		`{"bar": {"$lte": 1}}`:                                            `{bar: {$lte: 1}}`,
+		`{"foo": {"$in": ["bar", "baz"]}}`:                                `{foo: {$in: ["bar", "baz"]}}`,
+		`{"foo": {"$nin": ["bar", "baz"]}}`:                               `{foo: {$nin: ["bar", "baz"]}}`,
+		`{"$or": [{"foo": "bar"}, {"bar": 1}]}`:                           `{$or: [{foo: "bar"}, {bar: 1}]}`,
+		`{"$and": [{"foo": "bar"}, {"bar": 1}]}`:                          `{$and: [{foo: "bar"}, {bar: 1}]}`,
+		`{"foo": {"$regex": "rege[x]{1}.+some"}}`:                         `{foo: {$regex: "rege[x]{1}.+some"}}`,
+		`{"foo": {"$regex": "^(?i)my.+-rest.+$"}}`:                        `{foo: {$regex: "^(?i)my.+-rest.+$"}}`,
+		`{"$and": [{"foo": "bar"}, {"foo": "baz"}]}`:                      `{$and: [{foo: "bar"}, {foo: "baz"}]}`,
+		`{"foo": "bar", "$or": [{"bar": "baz"}, {"bar": "foo"}]}`:         `{foo: "bar", $or: [{bar: "baz"}, {bar: "foo"}]}`,
+		`{"foo": ["bar", "baz"]}`:                                         `{foo: ["bar","baz"]}`,
+		`{"foo.bar": "baz"}`:                                              `{foo.bar: "baz"}`,
+		`{"foo":{"$elemMatch":{"a":"bar","b":"baz"}}}`:                    `{foo: {$elemMatch: {a: "bar", b: "baz"}}}`,
+		`{"$text": "quick fox"}`:                                          `{$text: "quick fox"}`,
+		`{"foo": {"$text": "quick fox"}}`:                                 `{foo: {$text: "quick fox"}}`,
+		`{"loc": {"$near": {"$geometry": [2.35, 48.85]}}}`:                `{loc: {$near: {$geometry: [2.35, 48.85]}}}`,
+		`{"loc": {"$near": {"$geometry": [2, 48], "$maxDistance": 1e3}}}`: `{loc: {$near: {$geometry: [2, 48], $maxDistance: 1000}}}`,
+		`{"loc": {"$near": {"$geometry": [2, 48], "$maxDistance": 0}}}`:   `{loc: {$near: {$geometry: [2, 48], $maxDistance: 0}}}`,
+		`{"loc": {"$near": {"$geometry": [2, 48]}}}`:                      `{loc: {$near: {$geometry: [2, 48]}}}`,
+		`{"loc": {"$within": {"$box": [[2, 48], [3, 49]]}}}`:              `{loc: {$within: {$box: [[2, 48], [3, 49]]}}}`,
+		`{"loc": {"$within": {"$polygon": [[0, 0], [1, 0], [1, 1]]}}}`:    `{loc: {$within: {$polygon: [[0, 0], [1, 0], [1, 1]]}}}`,
+		`{"foo": {"$size": 2}}`:                                           `{foo: {$size: 2}}`,
+		`{"foo": {"$all": ["bar", 1]}}`:                                   `{foo: {$all: ["bar", 1]}}`,
+		`{"foo": {"$startsWith": "bar"}}`:                                 `{foo: {$startsWith: "bar"}}`,
+		`{"foo": {"$endsWith": "bar"}}`:                                   `{foo: {$endsWith: "bar"}}`,
+		`{"foo": {"$contains": "bar"}}`:                                   `{foo: {$contains: "bar"}}`,
+		`{"foo": {"$ieq": "Bar"}}`:                                        `{foo: {$ieq: "Bar"}}`,
+		`{"foo": {"$mod": [4, -1]}}`:                                      `{foo: {$mod: [4, -1]}}`,
+		`{"foo": {"$type": "array"}}`:                                     `{foo: {$type: "array"}}`,
+		`{"loc": {"$within": {"$circle": [[2, 48], 500]}}}`:               `{loc: {$within: {$circle: [[2, 48], 500]}}}`,
 	}
 	for query, want := range tests {
 		q, err := ParsePredicate(query)
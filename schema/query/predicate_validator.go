@@ -31,6 +31,19 @@ func validateField(field string, validator schema.Validator) error {
 	return err
 }
 
+// validateArrayField returns an error if field is not an array field.
+func validateArrayField(field string, validator schema.Validator) error {
+	f, err := getValidatorField(field, validator)
+	if err != nil {
+		return err
+	}
+	switch f.Validator.(type) {
+	case schema.Array, *schema.Array:
+		return nil
+	}
+	return fmt.Errorf("%s: not an array field", field)
+}
+
 func prepareValues(field string, values []Value, validator schema.Validator) error {
 	f, err := getValidatorField(field, validator)
 	if err != nil {
@@ -93,3 +106,41 @@ func getLessFunc(field string, validator schema.Validator) (schema.LessFunc, err
 	}
 	return less, nil
 }
+
+func prepareString(field string, value *string, validator schema.Validator) error {
+	nv, err := prepareValue(field, *value, validator)
+	if err != nil {
+		return err
+	}
+	s, ok := nv.(string)
+	if !ok {
+		return fmt.Errorf("%s: invalid query expression: not a string", field)
+	}
+	*value = s
+	return nil
+}
+
+// preparePattern validates the pattern matched against the values of field by
+// the $regex, $startsWith, $endsWith and $contains operators. As a pattern is
+// not a full field value, it is only validated by the validators implementing
+// schema.FieldQueryValidator.
+func preparePattern(field string, value *string, validator schema.Validator) error {
+	f, err := getValidatorField(field, validator)
+	if err != nil {
+		return err
+	}
+	qv, ok := f.Validator.(schema.FieldQueryValidator)
+	if !ok {
+		return nil
+	}
+	nv, err := qv.ValidateQuery(*value)
+	if err != nil {
+		return fmt.Errorf("%s: invalid query expression: %s", field, err)
+	}
+	s, ok := nv.(string)
+	if !ok {
+		return fmt.Errorf("%s: invalid query expression: not a string", field)
+	}
+	*value = s
+	return nil
+}
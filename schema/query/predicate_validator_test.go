@@ -3,6 +3,7 @@ package query
 import (
 	"errors"
 	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ func TestPrepare(t *testing.T) {
 			"bar": schema.Field{Validator: &schema.Integer{Allowed: []int{1, 2}}, Filterable: true},
 			"tar": schema.Field{Validator: &schema.Time{}, Filterable: true},
 			"baz": schema.Field{Validator: &schema.Array{MaxLen: 1, Values: schema.Field{Validator: &schema.Time{}}}, Filterable: true},
+			"url": schema.Field{Validator: &schema.URL{}, Filterable: true},
 		},
 	}
 	s.Compile(nil)
@@ -69,6 +71,21 @@ func TestPrepare(t *testing.T) {
 			Predicate{&Equal{Field: "baz", Value: nil}},
 			errors.New("baz: invalid query expression: invalid value at #2: not a time"),
 		},
+		{
+			`{"url": {"$startsWith": "https://"}, "foo": {"$contains": "Hello"}}`,
+			Predicate{&StartsWith{Field: "url", Value: "https://"}, &Contains{Field: "foo", Value: "Hello"}},
+			nil,
+		},
+		{
+			`{"url": {"$endsWith": ".org/"}}`,
+			Predicate{&EndsWith{Field: "url", Value: ".org/"}},
+			nil,
+		},
+		{
+			`{"url": {"$regex": "^ftp:"}}`,
+			Predicate{&Regex{Field: "url", Value: regexp.MustCompile("^ftp:")}},
+			nil,
+		},
 	}
 	for _, tt := range tests {
 		q, err := ParsePredicate(tt.query)
@@ -91,6 +108,7 @@ func TestPrepareErrors(t *testing.T) {
 			"foo": schema.Field{Validator: schema.String{}, Filterable: true},
 			"bar": schema.Field{Validator: schema.Integer{}, Filterable: true},
 			"baz": schema.Field{Validator: schema.Integer{}, Filterable: false},
+			"qux": schema.Field{Validator: &schema.Array{Values: schema.Field{Validator: schema.Integer{}}}, Filterable: true},
 		},
 	}
 	tests := []struct {
@@ -138,6 +156,34 @@ func TestPrepareErrors(t *testing.T) {
 			`{"$and": [{"foo": "bar"}, {"bar": "baz"}]}`,
 			errors.New("bar: invalid query expression: not an integer"),
 		},
+		{
+			`{"foo": {"$startsWith": "a"}, "bar": {"$contains": "1"}}`,
+			errors.New("bar: invalid query expression: not an integer"),
+		},
+		{
+			`{"bar": {"$all": [1, 2]}}`,
+			errors.New("bar: not an array field"),
+		},
+		{
+			`{"qux": {"$all": [1, "2"]}}`,
+			errors.New("qux: invalid query expression `\"2\"': invalid value at #1: not an integer"),
+		},
+		{
+			`{"foo": {"$size": 1}}`,
+			errors.New("foo: not an array field"),
+		},
+		{
+			`{"foo": {"$mod": [2, 0]}}`,
+			errors.New("foo: field is not numeric"),
+		},
+		{
+			`{"baz": {"$size": 1}}`,
+			errors.New("baz: field is not filterable"),
+		},
+		{
+			`{"unknown": {"$type": "string"}}`,
+			errors.New("unknown: unknown query field"),
+		},
 		// Unfilterable
 		{
 			`{"baz": 1}`,
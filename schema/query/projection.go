@@ -30,6 +30,19 @@ type ProjectionField struct {
 
 	// Children holds references to child projections if any.
 	Children Projection
+
+	// Directives lists the directives to apply on the field value, in order.
+	Directives []ProjectionDirective
+}
+
+// ProjectionDirective describes a directive applied on a projection field,
+// like @skip or @truncate(len:100). See RegisterDirective for more info.
+type ProjectionDirective struct {
+	// Name is the name of the directive, without the @.
+	Name string
+
+	// Args holds the arguments passed to the directive if any.
+	Args map[string]interface{}
 }
 
 // Validate validates the projection against the provided validator.
@@ -60,30 +73,10 @@ func (pf ProjectionField) String() string {
 	}
 	buf.WriteString(pf.Name)
 	if len(pf.Params) > 0 {
-		buf.WriteByte('(')
-		names := make([]string, 0, len(pf.Params))
-		for name := range pf.Params {
-			names = append(names, name)
-		}
-		sort.Strings(names)
-		for _, name := range names {
-			value := pf.Params[name]
-			buf.WriteString(name)
-			buf.WriteByte(':')
-			switch v := value.(type) {
-			case string:
-				buf.WriteString(strconv.Quote(v))
-			case float64:
-				buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
-			case bool:
-				buf.WriteString(fmt.Sprintf("%t", v))
-			default:
-				buf.WriteString(fmt.Sprintf("%q", v))
-			}
-			buf.WriteByte(',')
-		}
-		buf.Truncate(buf.Len() - 1) // remove the trailing coma.
-		buf.WriteByte(')')
+		writeParams(buf, pf.Params)
+	}
+	for _, d := range pf.Directives {
+		buf.WriteString(d.String())
 	}
 	if len(pf.Children) > 0 {
 		buf.WriteByte('{')
@@ -92,3 +85,44 @@ func (pf ProjectionField) String() string {
 	}
 	return buf.String()
 }
+
+// String output the projection directive in its DSL form.
+func (d ProjectionDirective) String() string {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('@')
+	buf.WriteString(d.Name)
+	if len(d.Args) > 0 {
+		writeParams(buf, d.Args)
+	}
+	return buf.String()
+}
+
+// writeParams writes params in their DSL form, enclosed in parenthesizes.
+func writeParams(buf *bytes.Buffer, params map[string]interface{}) {
+	buf.WriteByte('(')
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := params[name]
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		switch v := value.(type) {
+		case string:
+			buf.WriteString(strconv.Quote(v))
+		case float64:
+			buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			buf.WriteString(fmt.Sprintf("%t", v))
+		case Predicate:
+			buf.WriteString(strconv.Quote(v.String()))
+		default:
+			buf.WriteString(fmt.Sprintf("%q", v))
+		}
+		buf.WriteByte(',')
+	}
+	buf.Truncate(buf.Len() - 1) // remove the trailing coma.
+	buf.WriteByte(')')
+}
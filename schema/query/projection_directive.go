@@ -0,0 +1,282 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+// Directive is the logic of a projection directive, conditionally including a
+// field or transforming its value.
+type Directive interface {
+	// Validate checks the arguments of the directive applied on the field def
+	// of fg. The def may be nil if the field has no definition. Like field
+	// params, the arguments may be replaced by their validated value.
+	Validate(fg schema.FieldGetter, def *schema.Field, args map[string]interface{}) error
+
+	// Eval returns the new value of the field, or false if the field must be
+	// removed from the payload. The payload holding the field is provided for
+	// directives depending on other fields.
+	Eval(ctx context.Context, payload map[string]interface{}, value interface{}, args map[string]interface{}) (interface{}, bool, error)
+}
+
+var (
+	directivesMu sync.RWMutex
+	directives   = map[string]Directive{
+		"include":  includeDirective{},
+		"skip":     skipDirective{},
+		"truncate": truncateDirective{},
+		"format":   formatDirective{},
+	}
+)
+
+// RegisterDirective makes the directive d available in projections as
+// @name(arg:value). The following directives are built-in:
+//
+//   - @include(if:condition) keeps the field only if the condition is true;
+//   - @skip(if:condition) removes the field if the condition is true, or
+//     always when used without argument;
+//   - @truncate(len:100) truncates strings to the given number of characters;
+//   - @format(time:"2006-01-02") formats times with the given Go layout.
+//
+// A condition is either a boolean or a predicate, as used by the filter
+// parameter, matched against the item holding the field. The fields of the
+// predicate must be Filterable.
+//
+// RegisterDirective panics if a directive is already registered with the same
+// name.
+func RegisterDirective(name string, d Directive) {
+	directivesMu.Lock()
+	defer directivesMu.Unlock()
+	if _, found := directives[name]; found {
+		panic(fmt.Sprintf("query: directive %q already registered", name))
+	}
+	directives[name] = d
+}
+
+func getDirective(name string) (Directive, error) {
+	directivesMu.RLock()
+	defer directivesMu.RUnlock()
+	d, found := directives[name]
+	if !found {
+		return nil, fmt.Errorf("unknown directive @%s", name)
+	}
+	return d, nil
+}
+
+// validateDirectives validates the directives of pf against its field def.
+func validateDirectives(pf ProjectionField, fg schema.FieldGetter, def *schema.Field) error {
+	for i := range pf.Directives {
+		pd := &pf.Directives[i]
+		d, err := getDirective(pd.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %v", pf.Name, err)
+		}
+		if pd.Args == nil {
+			pd.Args = map[string]interface{}{}
+		}
+		if err = d.Validate(fg, def, pd.Args); err != nil {
+			return fmt.Errorf("%s: @%s: %v", pf.Name, pd.Name, err)
+		}
+	}
+	return nil
+}
+
+// evalDirectives applies the directives of pf on the value of the field
+// contained in payload. It returns false if the field must be removed.
+func evalDirectives(ctx context.Context, pf ProjectionField, payload map[string]interface{}, value interface{}) (interface{}, bool, error) {
+	for _, pd := range pf.Directives {
+		d, err := getDirective(pd.Name)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %v", pf.Name, err)
+		}
+		var keep bool
+		value, keep, err = d.Eval(ctx, payload, value, pd.Args)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: @%s: %v", pf.Name, pd.Name, err)
+		}
+		if !keep {
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// checkArgs returns an error if args contains an argument not listed in names.
+func checkArgs(args map[string]interface{}, names ...string) error {
+	for arg := range args {
+		found := false
+		for _, name := range names {
+			if arg == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unsupported argument: %s", arg)
+		}
+	}
+	return nil
+}
+
+// validateCondition validates the if argument of args, replacing predicates
+// by their parsed form, prepared against fg when possible.
+func validateCondition(fg schema.FieldGetter, args map[string]interface{}, required bool) error {
+	if err := checkArgs(args, "if"); err != nil {
+		return err
+	}
+	switch cond := args["if"].(type) {
+	case nil:
+		if required {
+			return errors.New("missing `if' argument")
+		}
+	case bool:
+	case string:
+		pred, err := ParsePredicate(cond)
+		if err != nil {
+			return fmt.Errorf("invalid `if' argument: %v", err)
+		}
+		if v, ok := fg.(schema.Validator); ok {
+			if err = pred.Prepare(v); err != nil {
+				return fmt.Errorf("invalid `if' argument: %v", err)
+			}
+		}
+		args["if"] = pred
+	default:
+		return errors.New("`if' argument must be a boolean or a predicate")
+	}
+	return nil
+}
+
+// evalCondition returns the value of the if argument of args for payload, or
+// def if the argument is missing.
+func evalCondition(payload map[string]interface{}, args map[string]interface{}, def bool) (bool, error) {
+	switch cond := args["if"].(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return cond, nil
+	case Predicate:
+		return cond.Match(payload), nil
+	case string:
+		// The directive has not been validated.
+		pred, err := ParsePredicate(cond)
+		if err != nil {
+			return false, fmt.Errorf("invalid `if' argument: %v", err)
+		}
+		return pred.Match(payload), nil
+	}
+	return false, errors.New("`if' argument must be a boolean or a predicate")
+}
+
+// includeDirective implements @include(if:condition).
+type includeDirective struct{}
+
+func (includeDirective) Validate(fg schema.FieldGetter, def *schema.Field, args map[string]interface{}) error {
+	return validateCondition(fg, args, true)
+}
+
+func (includeDirective) Eval(ctx context.Context, payload map[string]interface{}, value interface{}, args map[string]interface{}) (interface{}, bool, error) {
+	if _, found := args["if"]; !found {
+		return nil, false, errors.New("missing `if' argument")
+	}
+	include, err := evalCondition(payload, args, false)
+	return value, include, err
+}
+
+// skipDirective implements @skip(if:condition).
+type skipDirective struct{}
+
+func (skipDirective) Validate(fg schema.FieldGetter, def *schema.Field, args map[string]interface{}) error {
+	return validateCondition(fg, args, false)
+}
+
+func (skipDirective) Eval(ctx context.Context, payload map[string]interface{}, value interface{}, args map[string]interface{}) (interface{}, bool, error) {
+	skip, err := evalCondition(payload, args, true)
+	return value, !skip, err
+}
+
+// truncateDirective implements @truncate(len:n).
+type truncateDirective struct{}
+
+func (truncateDirective) Validate(fg schema.FieldGetter, def *schema.Field, args map[string]interface{}) error {
+	if err := checkArgs(args, "len"); err != nil {
+		return err
+	}
+	if _, err := truncateLen(args); err != nil {
+		return err
+	}
+	if def != nil && def.Validator != nil && !isStringValidator(def.Validator) {
+		return errors.New("not a string field")
+	}
+	return nil
+}
+
+func (truncateDirective) Eval(ctx context.Context, payload map[string]interface{}, value interface{}, args map[string]interface{}) (interface{}, bool, error) {
+	l, err := truncateLen(args)
+	if err != nil {
+		return nil, false, err
+	}
+	if s, ok := value.(string); ok {
+		if r := []rune(s); len(r) > l {
+			value = string(r[:l])
+		}
+	}
+	return value, true, nil
+}
+
+func truncateLen(args map[string]interface{}) (int, error) {
+	switch l := args["len"].(type) {
+	case nil:
+		return 0, errors.New("missing `len' argument")
+	case float64:
+		if l >= 0 && l == float64(int(l)) {
+			return int(l), nil
+		}
+	case int:
+		if l >= 0 {
+			return l, nil
+		}
+	}
+	return 0, errors.New("`len' argument must be a positive integer")
+}
+
+// formatDirective implements @format(time:layout).
+type formatDirective struct{}
+
+func (formatDirective) Validate(fg schema.FieldGetter, def *schema.Field, args map[string]interface{}) error {
+	if err := checkArgs(args, "time"); err != nil {
+		return err
+	}
+	if layout, _ := args["time"].(string); layout == "" {
+		return errors.New("missing `time' argument")
+	}
+	if def != nil && def.Validator != nil {
+		switch def.Validator.(type) {
+		case schema.Time, *schema.Time:
+		default:
+			return errors.New("not a time field")
+		}
+	}
+	return nil
+}
+
+func (formatDirective) Eval(ctx context.Context, payload map[string]interface{}, value interface{}, args map[string]interface{}) (interface{}, bool, error) {
+	layout, _ := args["time"].(string)
+	if layout == "" {
+		return nil, false, errors.New("missing `time' argument")
+	}
+	switch t := value.(type) {
+	case time.Time:
+		value = t.Format(layout)
+	case string:
+		if tt, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			value = tt.Format(layout)
+		}
+	}
+	return value, true, nil
+}
@@ -185,9 +185,12 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 		if pf.Alias != "" {
 			name = pf.Alias
 		}
+		// The item holding the field, for the directives evaluated in the
+		// sub-request callbacks where payload is shadowed.
+		item := payload
 		def := fg.GetField(pf.Name)
-		// Skip hidden fields
-		if def != nil && def.Hidden {
+		// Skip hidden fields and fields the client is not allowed to read.
+		if def != nil && (def.Hidden || !def.Readable.Allowed(ctx)) {
 			continue
 		}
 		if val, found := payload[pf.Name]; found {
@@ -202,9 +205,13 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 					if subval, err = evalProjection(ctx, pf.Children, subval, def.Schema, rbr, rsc); err != nil {
 						return nil, fmt.Errorf("%s.%v", pf.Name, err)
 					}
-					if res[name], err = resolveFieldHandler(ctx, pf, def, subval); err != nil {
+					v, keep, err := resolveField(ctx, pf, def, payload, subval)
+					if err != nil {
 						return nil, err
 					}
+					if keep {
+						res[name] = v
+					}
 				} else if ref, ok := def.Validator.(*schema.Reference); ok {
 					// Execute sub-request in batch
 					q := &Query{
@@ -226,21 +233,22 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 								return fmt.Errorf("%s: error resolving field handler on sub-field: %v", name, err)
 							}
 						}
-						resMu.Lock()
-						res[name] = v
-						resMu.Unlock()
-						return nil
+						return setField(ctx, res, &resMu, pf, name, item, v)
 					})
 				} else if array, ok := def.Validator.(*schema.Array); ok {
-					if payload, ok := val.([]interface{}); ok {
+					if values, ok := val.([]interface{}); ok {
 						var err error
 						var subvalp *[]interface{}
-						if subvalp, err = evalProjectionArray(ctx, pf, payload, &array.Values, rbr, rsc); err != nil {
+						if subvalp, err = evalProjectionArray(ctx, pf, values, &array.Values, rbr, rsc); err != nil {
 							return nil, fmt.Errorf("%s: error applying projection on array item #%d: %v", pf.Name, i, err)
 						}
-						if res[name], err = resolveFieldHandler(ctx, pf, &array.Values, subvalp); err != nil {
+						v, keep, err := resolveField(ctx, pf, &array.Values, payload, subvalp)
+						if err != nil {
 							return nil, fmt.Errorf("%s: error resolving field handler on array: %v", name, err)
 						}
+						if keep {
+							res[name] = v
+						}
 					} else {
 						return nil, fmt.Errorf("%s: invalid value: not an array", pf.Name)
 					}
@@ -253,17 +261,24 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 					if subval, err = evalProjection(ctx, pf.Children, subval, fg, rbr, rsc); err != nil {
 						return nil, fmt.Errorf("%s.%v", pf.Name, err)
 					}
-					if res[name], err = resolveFieldHandler(ctx, pf, def, subval); err != nil {
+					v, keep, err := resolveField(ctx, pf, def, payload, subval)
+					if err != nil {
 						return nil, err
 					}
+					if keep {
+						res[name] = v
+					}
 				} else {
 					return nil, fmt.Errorf("%s: field has no children", pf.Name)
 				}
 			} else {
-				var err error
-				if res[name], err = resolveFieldHandler(ctx, pf, def, val); err != nil {
+				v, keep, err := resolveField(ctx, pf, def, payload, val)
+				if err != nil {
 					return nil, err
 				}
+				if keep {
+					res[name] = v
+				}
 			}
 		} else if def != nil {
 			// If field is not found, it may be a connection
@@ -290,10 +305,7 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 					if v, err = resolveFieldHandler(ctx, pf, def, payloads); err != nil {
 						return fmt.Errorf("%s: error resolving field handler on sub-resource: %v", name, err)
 					}
-					resMu.Lock()
-					res[name] = v
-					resMu.Unlock()
-					return nil
+					return setField(ctx, res, &resMu, pf, name, item, v)
 				})
 			}
 		}
@@ -341,6 +353,32 @@ func connectionQuery(pf ProjectionField, field string, id interface{}, validator
 	return q, nil
 }
 
+// resolveField resolves the value of the field contained in payload with
+// resolveFieldHandler and applies the directives of pf on it. It returns false
+// if the field must be removed.
+func resolveField(ctx context.Context, pf ProjectionField, def *schema.Field, payload map[string]interface{}, val interface{}) (interface{}, bool, error) {
+	val, err := resolveFieldHandler(ctx, pf, def, val)
+	if err != nil {
+		return nil, false, err
+	}
+	return evalDirectives(ctx, pf, payload, val)
+}
+
+// setField applies the directives of pf on the value of the field resolved by
+// a sub-request and sets it in res under name, if not removed by a directive.
+func setField(ctx context.Context, res map[string]interface{}, mu *sync.Mutex, pf ProjectionField, name string, payload map[string]interface{}, val interface{}) error {
+	val, keep, err := evalDirectives(ctx, pf, payload, val)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if keep {
+		res[name] = val
+	}
+	return nil
+}
+
 // resolveFieldHandler calls the field handler with the provided params (if any).
 func resolveFieldHandler(ctx context.Context, pf ProjectionField, def *schema.Field, val interface{}) (interface{}, error) {
 	if def == nil {
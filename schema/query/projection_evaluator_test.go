@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/rest-layer/internal/testutil"
 	"github.com/rs/rest-layer/schema"
@@ -564,3 +566,167 @@ func TestProjectionEval(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectionEvalReadable(t *testing.T) {
+	type roleKey struct{}
+	isHR := func(ctx context.Context) bool { return ctx.Value(roleKey{}) == "hr" }
+	userSchema := schema.Schema{Fields: schema.Fields{
+		"id":     {},
+		"name":   {},
+		"salary": {Readable: isHR},
+	}}
+	users := resource{
+		validator: userSchema,
+		payloads: map[string]map[string]interface{}{
+			"1": {"id": "1", "name": "john", "salary": 10},
+		},
+	}
+	r := resource{
+		validator: schema.Schema{Fields: schema.Fields{
+			"id":      {},
+			"manager": {Validator: &schema.Reference{Path: "users", SchemaValidator: userSchema}},
+		}},
+		subResources: map[string]resource{"users": users},
+	}
+	payload := map[string]interface{}{"id": "a", "manager": "1"}
+	pr := MustParseProjection(`id,manager{*}`)
+
+	p, err := pr.Eval(context.Background(), payload, r)
+	if err != nil {
+		t.Fatalf("Eval unexpected error: %v", err)
+	}
+	got, _ := json.Marshal(p)
+	testutil.JSONEq(t, []byte(`{"id":"a","manager":{"id":"1","name":"john"}}`), got)
+
+	p, err = pr.Eval(context.WithValue(context.Background(), roleKey{}, "hr"), payload, r)
+	if err != nil {
+		t.Fatalf("Eval unexpected error: %v", err)
+	}
+	got, _ = json.Marshal(p)
+	testutil.JSONEq(t, []byte(`{"id":"a","manager":{"id":"1","name":"john","salary":10}}`), got)
+}
+
+type upperDirective struct{}
+
+func (upperDirective) Validate(fg schema.FieldGetter, def *schema.Field, args map[string]interface{}) error {
+	if len(args) > 0 {
+		return errors.New("no argument expected")
+	}
+	return nil
+}
+
+func (upperDirective) Eval(ctx context.Context, payload map[string]interface{}, value interface{}, args map[string]interface{}) (interface{}, bool, error) {
+	if s, ok := value.(string); ok {
+		value = strings.ToUpper(s)
+	}
+	return value, true, nil
+}
+
+func TestProjectionEvalDirectives(t *testing.T) {
+	RegisterDirective("upper", upperDirective{})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterDirective did not panic on duplicate")
+			}
+		}()
+		RegisterDirective("skip", upperDirective{})
+	}()
+	userSchema := schema.Schema{Fields: schema.Fields{
+		"id":   {},
+		"name": {},
+	}}
+	users := resource{
+		validator: userSchema,
+		payloads: map[string]map[string]interface{}{
+			"1": {"id": "1", "name": "john"},
+		},
+	}
+	r := resource{
+		validator: schema.Schema{Fields: schema.Fields{
+			"id":      {},
+			"status":  {Filterable: true},
+			"title":   {Validator: &schema.String{}},
+			"created": {Validator: &schema.Time{}},
+			"author":  {Validator: &schema.Reference{Path: "users", SchemaValidator: userSchema}},
+			"meta": {Schema: &schema.Schema{Fields: schema.Fields{
+				"tag": {},
+			}}},
+		}},
+		subResources: map[string]resource{"users": users},
+	}
+	created := time.Date(2018, 3, 12, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		projection string
+		payload    map[string]interface{}
+		want       string
+	}{
+		{
+			"Skip",
+			`id,title @skip`,
+			map[string]interface{}{"id": "a", "title": "foo"},
+			`{"id":"a"}`,
+		},
+		{
+			"Include/true",
+			`id,title @include(if:"{status:\"published\"}")`,
+			map[string]interface{}{"id": "a", "status": "published", "title": "foo"},
+			`{"id":"a","title":"foo"}`,
+		},
+		{
+			"Include/false",
+			`id,title @include(if:"{status:\"published\"}")`,
+			map[string]interface{}{"id": "a", "status": "draft", "title": "foo"},
+			`{"id":"a"}`,
+		},
+		{
+			"Include/reference",
+			`id,author @include(if:"{status:\"published\"}"){name}`,
+			map[string]interface{}{"id": "a", "status": "draft", "author": "1"},
+			`{"id":"a"}`,
+		},
+		{
+			"Truncate",
+			`title @truncate(len:3)`,
+			map[string]interface{}{"title": "héllo"},
+			`{"title":"hél"}`,
+		},
+		{
+			"Format",
+			`created @format(time:"2006-01-02")`,
+			map[string]interface{}{"created": created},
+			`{"created":"2018-03-12"}`,
+		},
+		{
+			"Chain",
+			`title @truncate(len:3) @upper,meta{tag @upper}`,
+			map[string]interface{}{"title": "hello", "meta": map[string]interface{}{"tag": "foo"}},
+			`{"title":"HEL","meta":{"tag":"FOO"}}`,
+		},
+		{
+			"Reference",
+			`author{name @upper}`,
+			map[string]interface{}{"author": "1"},
+			`{"author":{"name":"JOHN"}}`,
+		},
+	}
+	for i := range cases {
+		tc := cases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			pr, err := ParseProjection(tc.projection)
+			if err != nil {
+				t.Fatalf("ParseProjection unexpected error: %v", err)
+			}
+			if err = pr.Validate(r.validator); err != nil {
+				t.Fatalf("Projection.Validate unexpected error: %v", err)
+			}
+			payload, err := pr.Eval(context.Background(), tc.payload, r)
+			if err != nil {
+				t.Fatalf("Eval unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(payload)
+			testutil.JSONEq(t, []byte(tc.want), got)
+		})
+	}
+}
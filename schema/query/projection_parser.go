@@ -40,6 +40,12 @@ Or pass params to sub-fields:
 
 field1{sub-field1(param1:"value"),sub-field2},field2
 
+Directives prefixed by an at sign (@) can follow the field name and params to
+conditionally include the field or to transform its value, with optional
+arguments enclosed in parenthesizes like params (see RegisterDirective):
+
+field1 @include(if:"{status:\"published\"}"),field2 @truncate(len:100)
+
 Fields can also be renamed (aliased). This is useful when you want to have
 several times the same fields with different sets of parameters. To define
 aliases, prepend the field definition by the alias name and a colon (:):
@@ -112,6 +118,15 @@ func (p *projectionParser) parseExpression(opened bool) (Projection, error) {
 				return nil, err
 			}
 			field.Params = params
+		case '@':
+			p.pos++
+			directive, err := p.parseDirective()
+			if err != nil {
+				return nil, err
+			}
+			field.Directives = append(field.Directives, directive)
+			// The cursor is already after the directive.
+			continue
 		case ',':
 			projection = append(projection, *field)
 			field = nil
@@ -172,6 +187,27 @@ func (p *projectionParser) scanFieldParams() (map[string]interface{}, error) {
 	return params, nil
 }
 
+// p.parseDirective parses a directive name with its optional arguments at
+// current position, just after the @, and advance the cursor position "pos"
+// at the next character following the directive.
+func (p *projectionParser) parseDirective() (ProjectionDirective, error) {
+	d := ProjectionDirective{Name: p.scanFieldName()}
+	if d.Name == "" {
+		return d, fmt.Errorf("looking for directive name at char %d", p.pos)
+	}
+	if p.expect('(') {
+		args, err := p.scanFieldParams()
+		if err != nil {
+			return d, err
+		}
+		if !p.expect(')') {
+			return d, fmt.Errorf("looking for `)' at char %d", p.pos)
+		}
+		d.Args = args
+	}
+	return d, nil
+}
+
 // p.scanFieldName captures a field name at current position and advance
 // the cursor position "pos" at the next character following the field name.
 func (p *projectionParser) scanFieldName() string {
@@ -227,6 +227,42 @@ func TestParseProjection(t *testing.T) {
 			errors.New("looking for `,' or ')' at char 16"),
 			Projection{},
 		},
+		{
+			`foo @skip,bar`,
+			nil,
+			Projection{{Name: "foo", Directives: []ProjectionDirective{{Name: "skip"}}}, {Name: "bar"}},
+		},
+		{
+			`foo(bar:1) @truncate(len:10) @include(if:true){baz}`,
+			nil,
+			Projection{{
+				Name:   "foo",
+				Params: map[string]interface{}{"bar": 1.0},
+				Directives: []ProjectionDirective{
+					{Name: "truncate", Args: map[string]interface{}{"len": 10.0}},
+					{Name: "include", Args: map[string]interface{}{"if": true}},
+				},
+				Children: Projection{{Name: "baz"}},
+			}},
+		},
+		{
+			`foo{bar@format(time:"2006-01-02")}`,
+			nil,
+			Projection{{Name: "foo", Children: Projection{{
+				Name:       "bar",
+				Directives: []ProjectionDirective{{Name: "format", Args: map[string]interface{}{"time": "2006-01-02"}}},
+			}}}},
+		},
+		{
+			`foo @`,
+			errors.New("looking for directive name at char 5"),
+			Projection{},
+		},
+		{
+			`foo @skip(if:true`,
+			errors.New("looking for `,' or ')' at char 17"),
+			Projection{},
+		},
 	}
 	normalize := func(p string) string {
 		np := make([]byte, 0, len(p))
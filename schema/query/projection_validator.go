@@ -12,6 +12,9 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 		if pf.Alias != "" {
 			return fmt.Errorf("%s: can't have an alias", pf.Name)
 		}
+		if len(pf.Directives) > 0 {
+			return fmt.Errorf("%s: can't have directives", pf.Name)
+		}
 		return nil
 	}
 
@@ -70,5 +73,5 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 			pf.Params[name] = value
 		}
 	}
-	return nil
+	return validateDirectives(pf, fg, def)
 }
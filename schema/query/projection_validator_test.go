@@ -28,6 +28,8 @@ func TestProjectionValidate(t *testing.T) {
 				},
 			},
 			"simple": schema.Field{},
+			"title":  {Filterable: true, Validator: &schema.String{}},
+			"date":   {Validator: &schema.Time{}},
 			"with_params": {
 				Params: schema.Params{
 					"foo": {
@@ -86,6 +88,22 @@ func TestProjectionValidate(t *testing.T) {
 		{`connection{name}`, nil},
 		{`connection{*}`, nil},
 		{`connection{foo}`, errors.New("connection.foo: unknown field")},
+		{`simple @skip`, nil},
+		{`simple @skip(if:true) @include(if:"{title:\"foo\"}")`, nil},
+		{`simple @include`, errors.New("simple: @include: missing `if' argument")},
+		{`simple @include(if:1)`, errors.New("simple: @include: `if' argument must be a boolean or a predicate")},
+		{`simple @include(if:"{simple:1}")`, errors.New("simple: @include: invalid `if' argument: simple: field is not filterable")},
+		{`simple @skip(unless:true)`, errors.New("simple: @skip: unsupported argument: unless")},
+		{`title @truncate(len:10)`, nil},
+		{`title @truncate(len:1.5)`, errors.New("title: @truncate: `len' argument must be a positive integer")},
+		{`date @truncate(len:10)`, errors.New("date: @truncate: not a string field")},
+		{`date @format(time:"2006")`, nil},
+		{`title @format(time:"2006")`, errors.New("title: @format: not a time field")},
+		{`simple @format`, errors.New("simple: @format: missing `time' argument")},
+		{`simple @foo`, errors.New("simple: unknown directive @foo")},
+		{`* @skip`, errors.New("*: can't have directives")},
+		{`parent{child @skip}`, nil},
+		{`parent{child @foo}`, errors.New("parent.child: unknown directive @foo")},
 	}
 	for i := range cases {
 		tc := cases[i]
@@ -8,16 +8,51 @@ import (
 	"github.com/rs/rest-layer/schema"
 )
 
-// Sort TODO
+// Sort is a list of fields to sort on, by order of precedence.
 type Sort []SortField
 
-// SortField TODO
+// NullOrder defines where the items with a missing or null sort field value
+// are placed.
+type NullOrder int
+
+const (
+	// NullsDefault places null values as if they were lower than any other
+	// value: first in ascending order and last in descending order.
+	NullsDefault NullOrder = iota
+	// NullsFirst places null values first, whatever the sort direction.
+	NullsFirst
+	// NullsLast places null values last, whatever the sort direction.
+	NullsLast
+)
+
+// Collation defines how string values are compared.
+type Collation string
+
+const (
+	// CollationBinary compares strings byte per byte.
+	CollationBinary Collation = ""
+	// CollationNoCase compares strings ignoring their case.
+	CollationNoCase Collation = "nocase"
+)
+
+const (
+	sortNullsFirst = "nullsfirst"
+	sortNullsLast  = "nullslast"
+)
+
+// SortField defines the sort on a field.
 type SortField struct {
 	// Name is the name of the field to sort on.
 	Name string
 
 	// Reversed instruct to reverse the sorting if set to true.
 	Reversed bool
+
+	// Nulls defines where items with a missing or null value are placed.
+	Nulls NullOrder
+
+	// Collation defines how string values are compared.
+	Collation Collation
 }
 
 // MustParseSort parses a sort expression and panics in case of error.
@@ -31,13 +66,21 @@ func MustParseSort(sort string) Sort {
 
 // ParseSort parses a sort expression. A sort expression is a list of fields
 // separated by comas. A field sort is reverse if preceded by a minus sign (-).
+// A field may be followed by modifiers separated by colons (:):
+//
+//   - nullsfirst or nullslast to place the items with a missing or null value
+//     first or last, whatever the sort direction;
+//   - nocase to compare strings ignoring their case.
+//
+// For instance: -age:nullslast,name:nocase.
 func ParseSort(sort string) (Sort, error) {
 	s := Sort{}
 	if strings.Trim(sort, " ") == "" {
 		return s, nil
 	}
 	for _, f := range strings.Split(sort, ",") {
-		sf := SortField{Name: strings.Trim(f, " ")}
+		modifiers := strings.Split(strings.Trim(f, " "), ":")
+		sf := SortField{Name: modifiers[0]}
 		if sf.Name == "" || sf.Name == "-" {
 			return nil, errors.New("empty sort field")
 		}
@@ -47,14 +90,40 @@ func ParseSort(sort string) (Sort, error) {
 			sf.Name = sf.Name[1:]
 			sf.Reversed = true
 		}
+		for _, m := range modifiers[1:] {
+			switch m {
+			case sortNullsFirst, sortNullsLast:
+				if sf.Nulls != NullsDefault {
+					return nil, fmt.Errorf("%s: duplicate null ordering", sf.Name)
+				}
+				sf.Nulls = NullsFirst
+				if m == sortNullsLast {
+					sf.Nulls = NullsLast
+				}
+			case string(CollationNoCase):
+				if sf.Collation != CollationBinary {
+					return nil, fmt.Errorf("%s: duplicate collation", sf.Name)
+				}
+				sf.Collation = Collation(m)
+			default:
+				return nil, fmt.Errorf("%s: unknown sort modifier %q", sf.Name, m)
+			}
+		}
 		s = append(s, sf)
 	}
 	return s, nil
 }
 
-// Validate validates the sort against the provided validator.
+// Validate validates the sort against the provided validator. Like for
+// Predicate.Prepare, fields may be nested in objects, dictionaries or arrays
+// using the dot notation, as in "tags.0" or "address.city".
 func (s Sort) Validate(validator schema.Validator) error {
 	for _, sf := range s {
+		if sf.Name == ScoreField || sf.Name == DistanceField {
+			// The relevance of full-text searches and the distance to a $near
+			// point are not schema fields.
+			continue
+		}
 		// Make sure the field exists.
 		f := validator.GetField(sf.Name)
 		if f == nil {
@@ -63,6 +132,118 @@ func (s Sort) Validate(validator schema.Validator) error {
 		if !f.Sortable {
 			return fmt.Errorf("%s: field is not sortable", sf.Name)
 		}
+		if sf.Collation != CollationBinary && f.Validator != nil && !isStringValidator(f.Validator) {
+			return fmt.Errorf("%s: collation requires a string field", sf.Name)
+		}
 	}
 	return nil
 }
+
+func isStringValidator(v schema.FieldValidator) bool {
+	switch v.(type) {
+	case schema.String, *schema.String:
+		return true
+	}
+	return false
+}
+
+// String returns the sort in the format accepted by ParseSort.
+func (s Sort) String() string {
+	fields := make([]string, len(s))
+	for i, sf := range s {
+		fields[i] = sf.String()
+	}
+	return strings.Join(fields, ",")
+}
+
+// String returns the field sort in the format accepted by ParseSort.
+func (sf SortField) String() string {
+	var b strings.Builder
+	if sf.Reversed {
+		b.WriteByte('-')
+	}
+	b.WriteString(sf.Name)
+	if sf.Collation != CollationBinary {
+		b.WriteByte(':')
+		b.WriteString(string(sf.Collation))
+	}
+	switch sf.Nulls {
+	case NullsFirst:
+		b.WriteString(":" + sortNullsFirst)
+	case NullsLast:
+		b.WriteString(":" + sortNullsLast)
+	}
+	return b.String()
+}
+
+// Compare compares the values a and b of the field, and returns a negative
+// number if a sorts before b, a positive number if a sorts after b or 0 if
+// their order doesn't matter. The direction, the null ordering and the
+// collation of the field sort are taken into account. Values are compared in
+// their natural order, see Comparator to use the FieldComparator of the field.
+func (sf SortField) Compare(a, b interface{}) int {
+	return sf.compare(a, b, nil)
+}
+
+// Comparator returns a function comparing the values of the field like
+// Compare, using the FieldComparator of the field definition found in v if
+// any.
+func (sf SortField) Comparator(v schema.Validator) func(a, b interface{}) int {
+	var less schema.LessFunc
+	if f := v.GetField(sf.Name); f != nil {
+		if fc, ok := f.Validator.(schema.FieldComparator); ok {
+			less = fc.LessFunc()
+		}
+	}
+	return func(a, b interface{}) int {
+		return sf.compare(a, b, less)
+	}
+}
+
+func (sf SortField) compare(a, b interface{}, less schema.LessFunc) int {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0
+		}
+		c := -1
+		if a != nil {
+			c = 1
+		}
+		switch sf.Nulls {
+		case NullsFirst:
+			return c
+		case NullsLast:
+			return -c
+		}
+		if sf.Reversed {
+			return -c
+		}
+		return c
+	}
+	c := sf.compareValues(a, b, less)
+	if sf.Reversed {
+		return -c
+	}
+	return c
+}
+
+// compareValues compares the non nil values a and b in ascending order.
+func (sf SortField) compareValues(a, b interface{}, less schema.LessFunc) int {
+	if sf.Collation == CollationNoCase {
+		if s1, ok := a.(string); ok {
+			if s2, ok := b.(string); ok {
+				return strings.Compare(strings.ToLower(s1), strings.ToLower(s2))
+			}
+		}
+	}
+	if less != nil {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	}
+	return compareValues(a, b)
+}
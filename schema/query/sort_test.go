@@ -27,6 +27,12 @@ func TestParseSort(t *testing.T) {
 		{"   ,   ,", Sort{}, errors.New("empty sort field")},
 		{"-", Sort{}, errors.New("empty sort field")},
 		{"- ", Sort{}, errors.New("empty sort field")},
+		{"-age:nullslast", Sort{SortField{Name: "age", Reversed: true, Nulls: NullsLast}}, nil},
+		{"name:nocase:nullsfirst", Sort{SortField{Name: "name", Nulls: NullsFirst, Collation: CollationNoCase}}, nil},
+		{"age:nullsfirst:nullslast", Sort{}, errors.New("age: duplicate null ordering")},
+		{"name:nocase:nocase", Sort{}, errors.New("name: duplicate collation")},
+		{"age:", Sort{}, errors.New(`age: unknown sort modifier ""`)},
+		{"age:foo", Sort{}, errors.New(`age: unknown sort modifier "foo"`)},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -54,6 +60,21 @@ func TestSortValidate(t *testing.T) {
 	s := schema.Schema{Fields: schema.Fields{
 		"foo": {Sortable: false},
 		"bar": {Sortable: true},
+		"age": {Sortable: true, Validator: &schema.Integer{}},
+		"tags": {Validator: &schema.Array{
+			Values: schema.Field{Sortable: true, Validator: &schema.String{}},
+		}},
+		"items": {Validator: &schema.Array{
+			Values: schema.Field{Validator: &schema.Object{Schema: &schema.Schema{Fields: schema.Fields{
+				"price": {Sortable: true},
+			}}}},
+		}},
+		"meta": {Validator: &schema.Dict{
+			Values: schema.Field{Schema: &schema.Schema{Fields: schema.Fields{
+				"rank": {Sortable: true},
+				"name": {},
+			}}},
+		}},
 	}}
 	tests := []struct {
 		sort string
@@ -62,6 +83,14 @@ func TestSortValidate(t *testing.T) {
 		{"foo", errors.New("foo: field is not sortable")},
 		{"bar", nil},
 		{"baz", errors.New("baz: unknown sort field")},
+		{"-_score,bar", nil},
+		{"tags.0:nocase", nil},
+		{"tags.foo", errors.New("tags.foo: unknown sort field")},
+		{"items.1.price", nil},
+		{"items.1.name", errors.New("items.1.name: unknown sort field")},
+		{"meta.key.rank:nullslast", nil},
+		{"meta.key.name", errors.New("meta.key.name: field is not sortable")},
+		{"age:nocase", errors.New("age: collation requires a string field")},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -76,3 +105,72 @@ func TestSortValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestSortString(t *testing.T) {
+	for _, sort := range []string{"foo", "-foo.bar,baz", "-age:nullslast", "name:nocase:nullsfirst"} {
+		if got := MustParseSort(sort).String(); got != sort {
+			t.Errorf("String() = %q, want %q", got, sort)
+		}
+	}
+}
+
+func TestSortFieldCompare(t *testing.T) {
+	tests := []struct {
+		sort string
+		a, b interface{}
+		want int
+	}{
+		{"age", 1, 2, -1},
+		{"-age", 1, 2, 1},
+		{"age", 2, 2, 0},
+		{"age", nil, 2, -1},
+		{"-age", nil, 2, 1},
+		{"age:nullslast", nil, 2, 1},
+		{"-age:nullslast", nil, 2, 1},
+		{"age:nullsfirst", 2, nil, 1},
+		{"-age:nullsfirst", 2, nil, 1},
+		{"age:nullslast", nil, nil, 0},
+		{"name", "B", "a", -1},
+		{"name:nocase", "B", "a", 1},
+		{"-name:nocase", "B", "a", -1},
+		{"name:nocase", "A", "a", 0},
+	}
+	for _, tt := range tests {
+		sf := MustParseSort(tt.sort)[0]
+		if got := sf.Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: Compare(%v, %v) = %d, want %d", tt.sort, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// reverseString is a string validator sorting strings in reverse order.
+type reverseString struct {
+	schema.String
+}
+
+func (v reverseString) LessFunc() schema.LessFunc {
+	return func(a, b interface{}) bool {
+		return a.(string) > b.(string)
+	}
+}
+
+func TestSortFieldComparator(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"name":  {Sortable: true, Validator: reverseString{}},
+		"other": {Sortable: true},
+	}}
+	sort := MustParseSort("name,other")
+	if err := sort.Validate(s); err != nil {
+		t.Fatal(err)
+	}
+	// Validated sorts remain comparable.
+	if want := (Sort{{Name: "name"}, {Name: "other"}}); !reflect.DeepEqual(sort, want) {
+		t.Errorf("Validate() = %v, want %v", sort, want)
+	}
+	if got := sort[0].Comparator(s)("a", "b"); got != 1 {
+		t.Errorf("name: Comparator()(a, b) = %d, want 1", got)
+	}
+	if got := sort[1].Comparator(s)("a", "b"); got != -1 {
+		t.Errorf("other: Comparator()(a, b) = %d, want -1", got)
+	}
+}
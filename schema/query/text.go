@@ -0,0 +1,159 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rs/rest-layer/schema"
+)
+
+// ScoreField is the sort field ordering items by the relevance computed by the
+// Text expressions of the predicate. Use -_score to get the most relevant
+// items first.
+const ScoreField = "_score"
+
+// Text matches items whose text fields contain at least one of the terms of a
+// search. Terms are the sequences of letters and digits of the search, and are
+// matched case-insensitively against the words of the fields.
+type Text struct {
+	// Field is the searched field. When empty, all the Searchable fields of
+	// the schema are searched.
+	Field string
+	// Search is the text searched.
+	Search string
+	fields []string
+}
+
+// Match implements Expression interface.
+func (e Text) Match(payload map[string]interface{}) bool {
+	return e.Score(payload) > 0
+}
+
+// Score returns the relevance of payload for the search, computed as the
+// number of occurrences of the search terms in the searched fields.
+func (e Text) Score(payload map[string]interface{}) float64 {
+	terms := map[string]bool{}
+	for _, t := range tokenize(e.Search) {
+		terms[t] = true
+	}
+	var values []interface{}
+	switch {
+	case e.Field != "":
+		values = append(values, getField(payload, e.Field))
+	case e.fields != nil:
+		for _, field := range e.fields {
+			values = append(values, getField(payload, field))
+		}
+	default:
+		// Unprepared expressions search all the top level fields.
+		for _, v := range payload {
+			values = append(values, v)
+		}
+	}
+	score := 0.0
+	for _, v := range values {
+		score += termCount(v, terms)
+	}
+	return score
+}
+
+// termCount returns the number of occurrences of terms in v, a string or an
+// array of strings.
+func termCount(v interface{}, terms map[string]bool) float64 {
+	n := 0.0
+	switch t := v.(type) {
+	case string:
+		for _, token := range tokenize(t) {
+			if terms[token] {
+				n++
+			}
+		}
+	case []interface{}:
+		for _, e := range t {
+			n += termCount(e, terms)
+		}
+	}
+	return n
+}
+
+// tokenize splits s into lower-cased words.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Prepare implements Expression interface.
+func (e *Text) Prepare(validator schema.Validator) error {
+	if len(tokenize(e.Search)) == 0 {
+		return fmt.Errorf("%s: empty search", opText)
+	}
+	if e.Field != "" {
+		f := validator.GetField(e.Field)
+		if f == nil {
+			return fmt.Errorf("%s: unknown query field", e.Field)
+		}
+		if !f.Searchable {
+			return fmt.Errorf("%s: field is not searchable", e.Field)
+		}
+		return nil
+	}
+	e.fields = searchableFields(validator)
+	if len(e.fields) == 0 {
+		return fmt.Errorf("%s: no searchable field", opText)
+	}
+	return nil
+}
+
+// searchableFields returns the names of the top level Searchable fields of the
+// schema behind validator.
+func searchableFields(validator schema.Validator) []string {
+	var s schema.Schema
+	switch v := validator.(type) {
+	case schema.Schema:
+		s = v
+	case *schema.Schema:
+		s = *v
+	case interface{ Schema() schema.Schema }:
+		s = v.Schema()
+	}
+	fields := []string{}
+	for name, f := range s.Fields {
+		if f.Searchable {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// String implements Expression interface.
+func (e Text) String() string {
+	if e.Field == "" {
+		return opText + ": " + valueString(e.Search)
+	}
+	return quoteField(e.Field) + ": {" + opText + ": " + valueString(e.Search) + "}"
+}
+
+// Score returns the relevance of payload for the Text expressions of the
+// predicate, as used to sort on ScoreField.
+func (e Predicate) Score(payload map[string]interface{}) float64 {
+	return scoreExpressions(e, payload)
+}
+
+func scoreExpressions(exps []Expression, payload map[string]interface{}) float64 {
+	score := 0.0
+	for _, exp := range exps {
+		switch t := exp.(type) {
+		case *Text:
+			score += t.Score(payload)
+		case *And:
+			score += scoreExpressions(*t, payload)
+		case *Or:
+			score += scoreExpressions(*t, payload)
+		}
+	}
+	return score
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"title": {Searchable: true},
+		"body":  {Searchable: true},
+		"tags":  {Searchable: true},
+		"note":  {Filterable: true},
+	}}
+	payloads := []map[string]interface{}{
+		{"title": "The quick brown fox", "body": "jumps over the lazy dog", "note": "fox"},
+		{"title": "Foxes", "body": "A fox, another FOX.", "tags": []interface{}{"fox", "animal"}},
+		{"title": "Dogs", "note": "fox"},
+	}
+
+	p := MustParsePredicate(`{$text: "fox dog"}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.True(t, p.Match(payloads[0]))
+	assert.True(t, p.Match(payloads[1]))
+	assert.False(t, p.Match(payloads[2]), "only searchable fields are searched")
+	assert.Equal(t, 2.0, p.Score(payloads[0]))
+	assert.Equal(t, 3.0, p.Score(payloads[1]))
+	assert.Equal(t, 0.0, p.Score(payloads[2]))
+
+	p = MustParsePredicate(`{title: {$text: "FOX"}}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.True(t, p.Match(payloads[0]))
+	assert.False(t, p.Match(payloads[1]))
+
+	// Scores of nested expressions are summed.
+	p = MustParsePredicate(`{$or: [{title: {$text: "fox"}}, {body: {$text: "fox"}}]}`)
+	assert.NoError(t, p.Prepare(s))
+	assert.Equal(t, 2.0, p.Score(payloads[1]))
+
+	for query, err := range map[string]string{
+		`{note: {$text: "fox"}}`: "note: field is not searchable",
+		`{foo: {$text: "fox"}}`:  "foo: unknown query field",
+		`{$text: "  "}`:          "$text: empty search",
+	} {
+		assert.EqualError(t, MustParsePredicate(query).Prepare(s), err, query)
+	}
+	assert.EqualError(t, MustParsePredicate(`{$text: "fox"}`).Prepare(schema.Schema{}), "$text: no searchable field")
+}
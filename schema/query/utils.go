@@ -109,15 +109,33 @@ func getFieldExist(payload map[string]interface{}, name string) (interface{}, bo
 	path := strings.SplitN(name, ".", 2)
 	if value, found := payload[path[0]]; found {
 		if len(path) == 2 {
-			if subPayload, ok := value.(map[string]interface{}); ok {
-				// Check next level.
-				return getFieldExist(subPayload, path[1])
-			}
-			// The requested depth does not exist.
-			return nil, false
+			return getSubFieldExist(value, path[1])
 		}
 		// Full path has been found.
 		return value, true
 	}
 	return nil, false
 }
+
+// getSubFieldExist is like getFieldExist for the sub-field name of value,
+// which may be either an object or an array indexed by the first element of
+// the path.
+func getSubFieldExist(value interface{}, name string) (interface{}, bool) {
+	switch t := value.(type) {
+	case map[string]interface{}:
+		// Check next level.
+		return getFieldExist(t, name)
+	case []interface{}:
+		path := strings.SplitN(name, ".", 2)
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(t) {
+			return nil, false
+		}
+		if len(path) == 2 {
+			return getSubFieldExist(t[i], path[1])
+		}
+		return t[i], true
+	}
+	// The requested depth does not exist.
+	return nil, false
+}
@@ -0,0 +1,97 @@
+package query
+
+// opEqual is the name reported by Operator for Equal expressions, which have
+// no operator in the predicate syntax.
+const opEqual = "$eq"
+
+// Walk calls fn for each expression of exps and their sub-expressions, depth
+// first. Top-level expressions have a depth of 1, and the sub-expressions of
+// $and, $or and $elemMatch the depth of their parent plus one. Walk stops and
+// returns the first error returned by fn.
+func Walk(exps []Expression, fn func(exp Expression, depth int) error) error {
+	return walk(exps, 1, fn)
+}
+
+func walk(exps []Expression, depth int, fn func(exp Expression, depth int) error) error {
+	for _, exp := range exps {
+		if err := fn(exp, depth); err != nil {
+			return err
+		}
+		var sub []Expression
+		switch t := exp.(type) {
+		case *And:
+			sub = *t
+		case *Or:
+			sub = *t
+		case *ElemMatch:
+			sub = t.Exps
+		}
+		if err := walk(sub, depth+1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Operator returns the field and the operator of exp as used in the predicate
+// syntax, like "$in" or "$regex". Equality is reported as "$eq". The $and and
+// $or operators, as well as $text when used at the top level, have no field.
+// An empty operator is returned for unknown expression types.
+func Operator(exp Expression) (field, op string) {
+	switch t := exp.(type) {
+	case *And:
+		return "", opAnd
+	case *Or:
+		return "", opOr
+	case *Equal:
+		return t.Field, opEqual
+	case *NotEqual:
+		return t.Field, opNotEqual
+	case *In:
+		return t.Field, opIn
+	case *NotIn:
+		return t.Field, opNotIn
+	case *Exist:
+		return t.Field, opExists
+	case *NotExist:
+		return t.Field, opExists
+	case *GreaterThan:
+		return t.Field, opGreaterThan
+	case *GreaterOrEqual:
+		return t.Field, opGreaterOrEqual
+	case *LowerThan:
+		return t.Field, opLowerThan
+	case *LowerOrEqual:
+		return t.Field, opLowerOrEqual
+	case *Regex:
+		if t.Negated {
+			return t.Field, opNot
+		}
+		return t.Field, opRegex
+	case *ElemMatch:
+		return t.Field, opElemMatch
+	case *Text:
+		return t.Field, opText
+	case *Near:
+		return t.Field, opNear
+	case *Within:
+		return t.Field, opWithin
+	case *Size:
+		return t.Field, opSize
+	case *All:
+		return t.Field, opAll
+	case *StartsWith:
+		return t.Field, opStartsWith
+	case *EndsWith:
+		return t.Field, opEndsWith
+	case *Contains:
+		return t.Field, opContains
+	case *EqualFold:
+		return t.Field, opEqualFold
+	case *Mod:
+		return t.Field, opMod
+	case *Type:
+		return t.Field, opType
+	}
+	return "", ""
+}
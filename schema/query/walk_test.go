@@ -0,0 +1,31 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	p := MustParsePredicate(`{a: 1, $or: [{b: {$gt: 1}}, {c: {$elemMatch: {d: {$regex: "x"}}}}]}`)
+	var visited []string
+	err := Walk(p, func(exp Expression, depth int) error {
+		field, op := Operator(exp)
+		visited = append(visited, field+" "+op+" "+string(rune('0'+depth)))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a $eq 1", " $or 1", "b $gt 2", "c $elemMatch 2", "d $regex 3"}, visited)
+
+	stop := errors.New("stop")
+	n := 0
+	err = Walk(p, func(exp Expression, depth int) error {
+		if n++; depth == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 3, n)
+}
@@ -5,9 +5,29 @@ import (
 	"fmt"
 )
 
+// DeleteAction defines what happens to the items referencing an item when the
+// referenced item is deleted.
+type DeleteAction int
+
+const (
+	// DeleteNoAction leaves the referencing items untouched. This is the
+	// default.
+	DeleteNoAction DeleteAction = iota
+	// DeleteRestrict prevents the deletion of items still referenced.
+	DeleteRestrict
+	// DeleteCascade deletes the referencing items along with the referenced
+	// item.
+	DeleteCascade
+	// DeleteSetNull removes the reference from the referencing items.
+	DeleteSetNull
+)
+
 // Reference validates the ID of a linked resource.
 type Reference struct {
-	Path            string
+	Path string
+	// OnDelete defines what happens to the items holding the reference when
+	// the referenced item is deleted.
+	OnDelete        DeleteAction
 	validator       FieldValidator
 	SchemaValidator Validator
 }
@@ -12,6 +12,16 @@ type internal struct{}
 // Tombstone is used to mark a field for removal.
 var Tombstone = internal{}
 
+// forbiddenChange marks a field changed without write permission in the
+// changes returned by Prepare, so Validate can report it.
+type forbiddenChange struct{}
+
+// forbiddenIssue is the type of the error reported by Validate for fields
+// changed without write permission.
+type forbiddenIssue string
+
+const errForbidden forbiddenIssue = "forbidden"
+
 // Validator is an interface used to validate schema against actual data.
 type Validator interface {
 	GetField(name string) *Field
@@ -99,11 +109,17 @@ func (s Schema) GetField(name string) *Field {
 // being absent). This instruct the validator that the field has been edited, so
 // ReadOnly flag can throw an error and the field will be removed from the
 // output document. The OnInit is also called instead of the OnUpdate.
+//
+// The Readable and Writable permissions of the fields are evaluated with ctx.
+// Changes on fields the client is not allowed to write are reported by
+// Validate, while such fields (and fields the client can't read) missing from
+// a replacing payload keep their original value.
 func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, original *map[string]interface{}, replace bool) (changes map[string]interface{}, base map[string]interface{}) {
 	changes = map[string]interface{}{}
 	base = map[string]interface{}{}
 	for field, def := range s.Fields {
 		value, found := payload[field]
+		writable := def.Writable.Allowed(ctx)
 		if original == nil {
 			if replace == true {
 				log.Panic("Cannot use replace=true without original")
@@ -140,7 +156,10 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 				// ReadOnly and then the field can be removed from the output document.
 				// One exception to that though: if the field is set to hidden and is not readonly, we use
 				// previous value as the client would have no way to resubmit the stored value.
-				if def.Hidden && !def.ReadOnly {
+				if !writable {
+					// The client is not allowed to change the field, leave
+					// the original value in the base.
+				} else if (def.Hidden || !def.Readable.Allowed(ctx)) && !def.ReadOnly {
 					changes[field] = oValue
 				} else if def.Default != nil {
 					changes[field] = def.Default
@@ -209,6 +228,15 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 				base[field] = hook(ctx, base[field])
 			}
 		}
+		if found && !writable {
+			// Mark the fields changed by the client without permission. An
+			// unchanged sub-document is not considered as a change.
+			if c, found := changes[field]; found {
+				if sub, ok := c.(map[string]interface{}); !ok || def.Schema == nil || len(sub) > 0 {
+					changes[field] = forbiddenChange{}
+				}
+			}
+		}
 	}
 	// Assign all out of schema fields to the changes map so Validate() can
 	// complain about it.
@@ -230,6 +258,7 @@ func (s Schema) Validate(changes map[string]interface{}, base map[string]interfa
 func (s Schema) validate(changes map[string]interface{}, base map[string]interface{}, isRoot bool) (doc map[string]interface{}, errs map[string][]interface{}) {
 	doc = map[string]interface{}{}
 	errs = map[string][]interface{}{}
+	changes = withoutForbidden(changes, errs)
 	for field, def := range s.Fields {
 		// Check read only fields.
 		if def.ReadOnly {
@@ -337,6 +366,47 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 	return doc, errs
 }
 
+// withoutForbidden reports the fields marked as changed without permission by
+// Prepare in errs, and returns the changes without them so the original
+// values are used in their place.
+func withoutForbidden(changes map[string]interface{}, errs map[string][]interface{}) map[string]interface{} {
+	var filtered map[string]interface{}
+	for field, value := range changes {
+		if _, ok := value.(forbiddenChange); ok {
+			if filtered == nil {
+				filtered = make(map[string]interface{}, len(changes))
+				for k, v := range changes {
+					filtered[k] = v
+				}
+			}
+			delete(filtered, field)
+			addFieldError(errs, field, errForbidden)
+		}
+	}
+	if filtered == nil {
+		return changes
+	}
+	return filtered
+}
+
+// IsForbidden returns true if the errors returned by Validate report a change
+// on a field the client is not allowed to write (see Field.Writable).
+func IsForbidden(errs map[string][]interface{}) bool {
+	for _, fieldErrs := range errs {
+		for _, err := range fieldErrs {
+			switch e := err.(type) {
+			case forbiddenIssue:
+				return true
+			case map[string][]interface{}:
+				if IsForbidden(e) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func addFieldError(errs map[string][]interface{}, field string, err interface{}) {
 	errs[field] = append(errs[field], err)
 }
@@ -1,6 +1,8 @@
 package schema_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/rs/rest-layer/schema"
@@ -93,3 +95,115 @@ func TestSchemaValidator(t *testing.T) {
 		})
 	}
 }
+
+type roleKey struct{}
+
+// hasRole returns a permission granted to the requests with the role r.
+func hasRole(r string) schema.FieldPermission {
+	return func(ctx context.Context) bool {
+		return ctx.Value(roleKey{}) == r
+	}
+}
+
+func TestSchemaFieldPermissions(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"name":   {Validator: &schema.String{}},
+		"salary": {Readable: hasRole("hr"), Writable: hasRole("admin"), Validator: &schema.Integer{}},
+		"bank": {Writable: hasRole("admin"), Schema: &schema.Schema{Fields: schema.Fields{
+			"iban": {},
+		}}},
+		"contract": {Schema: &schema.Schema{Fields: schema.Fields{
+			"end": {Writable: hasRole("admin")},
+		}}},
+	}}
+	assert.NoError(t, s.Compile(nil))
+	user := context.Background()
+	admin := context.WithValue(user, roleKey{}, "admin")
+	original := map[string]interface{}{"name": "john", "salary": 10, "bank": map[string]interface{}{"iban": "x"}}
+
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		payload   map[string]interface{}
+		original  *map[string]interface{}
+		replace   bool
+		doc       map[string]interface{}
+		errs      map[string][]interface{}
+		forbidden bool
+	}{
+		{
+			name:    "Create",
+			ctx:     user,
+			payload: map[string]interface{}{"name": "john", "salary": 10},
+			errs:    map[string][]interface{}{"salary": {"forbidden"}},
+		},
+		{
+			name:    "Create/Admin",
+			ctx:     admin,
+			payload: map[string]interface{}{"name": "john", "salary": 10},
+			doc:     map[string]interface{}{"name": "john", "salary": 10},
+		},
+		{
+			name:     "Update/Unchanged",
+			ctx:      user,
+			payload:  map[string]interface{}{"name": "paul", "salary": 10},
+			original: &original,
+			doc:      map[string]interface{}{"name": "paul", "salary": 10, "bank": map[string]interface{}{"iban": "x"}},
+		},
+		{
+			name:     "Update/Changed",
+			ctx:      user,
+			payload:  map[string]interface{}{"salary": 20},
+			original: &original,
+			errs:     map[string][]interface{}{"salary": {"forbidden"}},
+		},
+		{
+			name:     "Replace/Missing",
+			ctx:      user,
+			payload:  map[string]interface{}{"name": "paul"},
+			original: &original,
+			replace:  true,
+			doc:      map[string]interface{}{"name": "paul", "salary": 10, "bank": map[string]interface{}{"iban": "x"}},
+		},
+		{
+			// The bank is removed, while the salary, which can't be read by
+			// the admin, is kept.
+			name:     "Replace/Missing/Admin",
+			ctx:      admin,
+			payload:  map[string]interface{}{"name": "paul"},
+			original: &original,
+			replace:  true,
+			doc:      map[string]interface{}{"name": "paul", "salary": 10},
+		},
+		{
+			name:    "SubSchema",
+			ctx:     user,
+			payload: map[string]interface{}{"bank": map[string]interface{}{"iban": "y"}},
+			errs:    map[string][]interface{}{"bank": {"forbidden"}},
+		},
+		{
+			name:    "SubSchema/Field",
+			ctx:     user,
+			payload: map[string]interface{}{"contract": map[string]interface{}{"end": "2020"}},
+			errs:    map[string][]interface{}{"contract": {map[string][]interface{}{"end": {"forbidden"}}}},
+		},
+	}
+	for i := range cases {
+		tc := cases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			changes, base := s.Prepare(tc.ctx, tc.payload, tc.original, tc.replace)
+			doc, errs := s.Validate(changes, base)
+			if tc.errs != nil {
+				assert.True(t, schema.IsForbidden(errs))
+				// Issues are encoded as strings.
+				b, _ := json.Marshal(errs)
+				expected, _ := json.Marshal(tc.errs)
+				assert.JSONEq(t, string(expected), string(b))
+				return
+			}
+			assert.Empty(t, errs)
+			assert.Equal(t, tc.doc, doc)
+		})
+	}
+	assert.False(t, schema.IsForbidden(map[string][]interface{}{"name": {"forbidden"}}))
+}
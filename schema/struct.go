@@ -0,0 +1,710 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotStruct is returned by FromStruct, ToPayload and FromPayload when
+	// passed something else than a struct or a pointer to a struct.
+	ErrNotStruct = errors.New("not a struct")
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// FromStruct derives a Schema from the exported fields of the struct v (or
+// pointer to struct). Field names follow the json tag of the Go fields, fields
+// tagged json:"-" are skipped and anonymous struct fields are flattened, the
+// same way encoding/json does.
+//
+// The field's Go type selects the validator: String for strings, Integer for
+// integers, Float for floats, Bool for booleans, Time for time.Time, Array for
+// slices, Dict for maps with string keys and a sub-schema (Field.Schema) for
+// nested structs. Pointers are dereferenced. Interface fields accept any value.
+//
+// The schema tag holds a comma separated list of options:
+//
+//	required         set Field.Required
+//	readonly         set Field.ReadOnly
+//	hidden           set Field.Hidden
+//	filterable       set Field.Filterable
+//	sortable         set Field.Sortable
+//	id               start from IDField (must be a string)
+//	minlen=N         set String.MinLen or Password.MinLen
+//	maxlen=N         set String.MaxLen or Password.MaxLen
+//	allowed=a|b|c    set the Allowed values of String, Integer or Float
+//	min=N, max=N     set the Boundaries of Integer or Float, integers being
+//	                 bounded by the range of their Go type anyway
+//	url              use the URL validator (must be a string)
+//	ip               use the IP validator (must be a string)
+//	password         use the Password validator (must be a string)
+//	ref=path         use a Reference to the resource at path
+//	minitems=N       set Array.MinLen
+//	maxitems=N       set Array.MaxLen
+//	regexp=expr      set String.Regexp, must be the last option as expr is
+//	                 allowed to contain commas
+//
+// On slices and maps, the validator options apply to the values. The
+// description tag sets the Field.Description.
+//
+//	type User struct {
+//		ID      string    `json:"id" schema:"id"`
+//		Name    string    `json:"name" schema:"required,filterable,maxlen=150"`
+//		Age     int       `json:"age" schema:"min=0,max=150" description:"The age in years"`
+//		Created time.Time `json:"created" schema:"readonly"`
+//		Tags    []string  `json:"tags,omitempty" schema:"maxitems=10,regexp=^[a-z]+$"`
+//	}
+func FromStruct(v interface{}) (Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return Schema{}, ErrNotStruct
+	}
+	b := structBuilder{visiting: map[reflect.Type]bool{}}
+	s, err := b.schema(t, "")
+	if err != nil {
+		return Schema{}, err
+	}
+	return *s, nil
+}
+
+// MustFromStruct is like FromStruct but panics on error.
+func MustFromStruct(v interface{}) Schema {
+	s, err := FromStruct(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// structField describes an exported struct field as seen by encoding/json.
+type structField struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	tag       reflect.StructTag
+	omitEmpty bool
+}
+
+// structFields returns the fields of the struct type t, flattening anonymous
+// struct fields.
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, opts := sf.Name, ""
+		if tag, found := sf.Tag.Lookup("json"); found {
+			if tag == "-" {
+				continue
+			}
+			if i := strings.IndexByte(tag, ','); i >= 0 {
+				tag, opts = tag[:i], tag[i:]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && name == sf.Name {
+			for _, f := range structFields(sf.Type) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     []int{i},
+			typ:       sf.Type,
+			tag:       sf.Tag,
+			omitEmpty: strings.Contains(opts, ",omitempty"),
+		})
+	}
+	return fields
+}
+
+// structOptions holds the parsed content of a schema tag.
+type structOptions map[string]string
+
+func parseStructOptions(tag string) structOptions {
+	opts := structOptions{}
+	for tag != "" {
+		var opt string
+		if strings.HasPrefix(tag, "regexp=") {
+			opt, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			opt, tag = tag[:i], tag[i+1:]
+		} else {
+			opt, tag = tag, ""
+		}
+		if opt == "" {
+			continue
+		}
+		if i := strings.IndexByte(opt, '='); i >= 0 {
+			opts[opt[:i]] = opt[i+1:]
+		} else {
+			opts[opt] = ""
+		}
+	}
+	return opts
+}
+
+func (o structOptions) has(name string) bool {
+	_, found := o[name]
+	return found
+}
+
+// take returns the value of the option name and removes it from o.
+func (o structOptions) take(name string) (string, bool) {
+	v, found := o[name]
+	delete(o, name)
+	return v, found
+}
+
+func (o structOptions) takeInt(name string) (int, error) {
+	v, found := o.take(name)
+	if !found {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s option: %v", name, err)
+	}
+	return i, nil
+}
+
+// takeBoundaries returns the Boundaries defined by the min and max options,
+// or nil if none is set.
+func (o structOptions) takeBoundaries() (*Boundaries, error) {
+	if !o.has("min") && !o.has("max") {
+		return nil, nil
+	}
+	b := &Boundaries{Min: math.Inf(-1), Max: math.Inf(1)}
+	for _, opt := range []struct {
+		name  string
+		value *float64
+	}{{"min", &b.Min}, {"max", &b.Max}} {
+		if v, found := o.take(opt.name); found {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s option: %v", opt.name, err)
+			}
+			*opt.value = f
+		}
+	}
+	return b, nil
+}
+
+// err returns an error if any option remains in o.
+func (o structOptions) err(t reflect.Type) error {
+	for name := range o {
+		return fmt.Errorf("option %s not supported on %s", name, t)
+	}
+	return nil
+}
+
+type structBuilder struct {
+	// visiting holds the struct types being converted, to detect recursive
+	// types.
+	visiting map[reflect.Type]bool
+}
+
+func (b structBuilder) schema(t reflect.Type, path string) (*Schema, error) {
+	if b.visiting[t] {
+		return nil, fmt.Errorf("%s: recursive type %s not supported", path, t)
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+	s := &Schema{Fields: Fields{}}
+	for _, sf := range structFields(t) {
+		fieldPath := joinPath(path, sf.name)
+		if _, found := s.Fields[sf.name]; found {
+			return nil, fmt.Errorf("%s: duplicate field", fieldPath)
+		}
+		f, err := b.field(sf, fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		s.Fields[sf.name] = f
+	}
+	return s, nil
+}
+
+func (b structBuilder) field(sf structField, path string) (Field, error) {
+	opts := parseStructOptions(sf.tag.Get("schema"))
+	var f Field
+	if _, found := opts.take("id"); found {
+		f = IDField
+		if indirect(sf.typ).Kind() != reflect.String {
+			return f, fmt.Errorf("%s: option id not supported on %s", path, sf.typ)
+		}
+	}
+	for _, flag := range []struct {
+		name  string
+		value *bool
+	}{
+		{"required", &f.Required},
+		{"readonly", &f.ReadOnly},
+		{"hidden", &f.Hidden},
+		{"filterable", &f.Filterable},
+		{"sortable", &f.Sortable},
+	} {
+		if _, found := opts.take(flag.name); found {
+			*flag.value = true
+		}
+	}
+	if d, found := sf.tag.Lookup("description"); found {
+		f.Description = d
+	}
+	if f.Validator != nil {
+		// Preset from the id option.
+		return f, wrapPathErr(path, opts.err(sf.typ))
+	}
+	t := indirect(sf.typ)
+	if t.Kind() == reflect.Struct && t != timeType {
+		s, err := b.schema(t, path)
+		if err != nil {
+			return f, err
+		}
+		f.Schema = s
+		return f, wrapPathErr(path, opts.err(t))
+	}
+	v, err := b.validator(t, opts, path)
+	if err != nil {
+		return f, err
+	}
+	f.Validator = v
+	return f, nil
+}
+
+// validator returns the validator for values of type t. Any option not used
+// by the validator results in an error.
+func (b structBuilder) validator(t reflect.Type, opts structOptions, path string) (FieldValidator, error) {
+	t = indirect(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		minLen, err := opts.takeInt("minitems")
+		if err != nil {
+			return nil, wrapPathErr(path, err)
+		}
+		maxLen, err := opts.takeInt("maxitems")
+		if err != nil {
+			return nil, wrapPathErr(path, err)
+		}
+		values, err := b.valuesField(t.Elem(), opts, path)
+		if err != nil {
+			return nil, err
+		}
+		return &Array{Values: values, MinLen: minLen, MaxLen: maxLen}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%s: unsupported map key type %s", path, t.Key())
+		}
+		values, err := b.valuesField(t.Elem(), opts, path)
+		if err != nil {
+			return nil, err
+		}
+		return &Dict{Values: values}, nil
+	}
+	v, err := scalarValidator(t, opts)
+	if err == nil {
+		err = opts.err(t)
+	}
+	return v, wrapPathErr(path, err)
+}
+
+// valuesField returns the field describing the values of a slice or a map.
+func (b structBuilder) valuesField(t reflect.Type, opts structOptions, path string) (Field, error) {
+	t = indirect(t)
+	if t.Kind() == reflect.Struct && t != timeType {
+		s, err := b.schema(t, path)
+		if err != nil {
+			return Field{}, err
+		}
+		return Field{Validator: &Object{Schema: s}}, wrapPathErr(path, opts.err(t))
+	}
+	v, err := b.validator(t, opts, path)
+	return Field{Validator: v}, err
+}
+
+func scalarValidator(t reflect.Type, opts structOptions) (FieldValidator, error) {
+	if path, found := opts.take("ref"); found {
+		return &Reference{Path: path}, nil
+	}
+	if t == timeType {
+		return &Time{}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		minLen, err := opts.takeInt("minlen")
+		if err != nil {
+			return nil, err
+		}
+		maxLen, err := opts.takeInt("maxlen")
+		if err != nil {
+			return nil, err
+		}
+		if _, found := opts.take("password"); found {
+			return &Password{MinLen: minLen, MaxLen: maxLen}, nil
+		}
+		if minLen != 0 || maxLen != 0 || opts.has("regexp") || opts.has("allowed") {
+			v := &String{MinLen: minLen, MaxLen: maxLen}
+			v.Regexp, _ = opts.take("regexp")
+			if a, found := opts.take("allowed"); found {
+				v.Allowed = strings.Split(a, "|")
+			}
+			return v, nil
+		}
+		if _, found := opts.take("url"); found {
+			return &URL{}, nil
+		}
+		if _, found := opts.take("ip"); found {
+			return &IP{}, nil
+		}
+		return &String{}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v := &Integer{}
+		if a, found := opts.take("allowed"); found {
+			for _, s := range strings.Split(a, "|") {
+				i, err := strconv.Atoi(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid allowed option: %v", err)
+				}
+				v.Allowed = append(v.Allowed, i)
+			}
+		}
+		b, err := opts.takeBoundaries()
+		if err != nil {
+			return nil, err
+		}
+		min, max := intRange(t)
+		if b == nil && (!math.IsInf(min, -1) || !math.IsInf(max, 1)) {
+			b = &Boundaries{Min: math.Inf(-1), Max: math.Inf(1)}
+		}
+		if b != nil {
+			b.Min = math.Max(b.Min, min)
+			b.Max = math.Min(b.Max, max)
+		}
+		v.Boundaries = b
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		v := &Float{}
+		if a, found := opts.take("allowed"); found {
+			for _, s := range strings.Split(a, "|") {
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid allowed option: %v", err)
+				}
+				v.Allowed = append(v.Allowed, f)
+			}
+		}
+		var err error
+		v.Boundaries, err = opts.takeBoundaries()
+		return v, err
+	case reflect.Bool:
+		return &Bool{}, nil
+	case reflect.Interface:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// intRange returns the range of the values of the integer type t. Infinite
+// bounds are returned where the type holds any int.
+func intRange(t reflect.Type) (min, max float64) {
+	bits := t.Bits()
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if bits >= strconv.IntSize {
+			return 0, math.Inf(1)
+		}
+		return 0, math.Ldexp(1, bits) - 1
+	}
+	if bits >= strconv.IntSize {
+		return math.Inf(-1), math.Inf(1)
+	}
+	return -math.Ldexp(1, bits-1), math.Ldexp(1, bits-1) - 1
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func wrapPathErr(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %v", path, err)
+}
+
+// ToPayload converts the struct v (or pointer to struct) to a payload suitable
+// for resource.Item.Payload, using the same field naming rules as FromStruct.
+// Nil pointers, nil slices and nil maps are omitted, as well as zero values of
+// fields tagged with the omitempty json option. Integers are converted to int
+// and floats to float64 so they match the values produced by the Integer and
+// Float validators.
+func ToPayload(v interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+	return encodeStruct(rv, "")
+}
+
+func encodeStruct(v reflect.Value, path string) (map[string]interface{}, error) {
+	payload := map[string]interface{}{}
+	for _, sf := range structFields(v.Type()) {
+		fv := v.FieldByIndex(sf.index)
+		if sf.omitEmpty && fv.IsZero() {
+			continue
+		}
+		value, ok, err := encodeValue(fv, joinPath(path, sf.name))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			payload[sf.name] = value
+		}
+	}
+	return payload, nil
+}
+
+// encodeValue returns the payload representation of v, and false if v is nil.
+func encodeValue(v reflect.Value, path string) (interface{}, bool, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		if v.Kind() == reflect.Interface {
+			return v.Interface(), true, nil
+		}
+		return encodeValue(v.Elem(), path)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface(), true, nil
+		}
+		m, err := encodeStruct(v, path)
+		return m, true, err
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, false, nil
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			value, _, err := encodeValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, false, err
+			}
+			values[i] = value
+		}
+		return values, true, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false, fmt.Errorf("%s: unsupported map key type %s", path, v.Type().Key())
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			value, _, err := encodeValue(v.MapIndex(k), joinPath(path, k.String()))
+			if err != nil {
+				return nil, false, err
+			}
+			m[k.String()] = value
+		}
+		return m, true, nil
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return v.Bool(), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint()), true, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true, nil
+	}
+	return nil, false, fmt.Errorf("%s: unsupported type %s", path, v.Type())
+}
+
+// FromPayload stores the content of payload into the struct pointed to by v,
+// using the same field naming rules as FromStruct. Payload keys without a
+// matching struct field are ignored. Numbers are converted to the type of the
+// field as long as no precision is lost, and time.Time fields also accept
+// RFC 3339 strings.
+func FromPayload(payload map[string]interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+	return decodeStruct(payload, rv.Elem(), "")
+}
+
+func decodeStruct(payload map[string]interface{}, v reflect.Value, path string) error {
+	for _, sf := range structFields(v.Type()) {
+		value, found := payload[sf.name]
+		if !found {
+			continue
+		}
+		if err := decodeValue(value, v.FieldByIndex(sf.index), joinPath(path, sf.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeValue(in interface{}, v reflect.Value, path string) error {
+	if in == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		e := reflect.New(v.Type().Elem())
+		if err := decodeValue(in, e.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(e)
+		return nil
+	case reflect.Interface:
+		iv := reflect.ValueOf(in)
+		if !iv.Type().AssignableTo(v.Type()) {
+			break
+		}
+		v.Set(iv)
+		return nil
+	case reflect.Struct:
+		if v.Type() == timeType {
+			switch t := in.(type) {
+			case time.Time:
+				v.Set(reflect.ValueOf(t))
+				return nil
+			case string:
+				tm, err := time.Parse(time.RFC3339Nano, t)
+				if err != nil {
+					return fmt.Errorf("%s: %v", path, err)
+				}
+				v.Set(reflect.ValueOf(tm))
+				return nil
+			}
+			break
+		}
+		m, ok := in.(map[string]interface{})
+		if !ok {
+			break
+		}
+		return decodeStruct(m, v, path)
+	case reflect.Slice, reflect.Array:
+		iv := reflect.ValueOf(in)
+		if iv.Kind() != reflect.Slice && iv.Kind() != reflect.Array {
+			break
+		}
+		n := iv.Len()
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		} else if n > v.Len() {
+			return fmt.Errorf("%s: too many values for %s", path, v.Type())
+		}
+		for i := 0; i < n; i++ {
+			if err := decodeValue(iv.Index(i).Interface(), v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		iv := reflect.ValueOf(in)
+		if iv.Kind() != reflect.Map || iv.Type().Key().Kind() != reflect.String || v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		m := reflect.MakeMapWithSize(v.Type(), iv.Len())
+		for _, k := range iv.MapKeys() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(iv.MapIndex(k).Interface(), e, joinPath(path, k.String())); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k.String()).Convert(v.Type().Key()), e)
+		}
+		v.Set(m)
+		return nil
+	case reflect.String:
+		if s, ok := in.(string); ok {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := in.(bool); ok {
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		iv := reflect.ValueOf(in)
+		if k := iv.Kind(); k >= reflect.Int && k <= reflect.Int64 {
+			if v.OverflowInt(iv.Int()) {
+				break
+			}
+			v.SetInt(iv.Int())
+			return nil
+		}
+		f, ok := toFloat64(in)
+		if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || v.OverflowInt(int64(f)) {
+			break
+		}
+		v.SetInt(int64(f))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := toFloat64(in)
+		if !ok || f < 0 || f != math.Trunc(f) || v.OverflowUint(uint64(f)) {
+			break
+		}
+		v.SetUint(uint64(f))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(in)
+		if !ok || v.OverflowFloat(f) {
+			break
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("%s: cannot store %T into %s", path, in, v.Type())
+}
+
+func toFloat64(in interface{}) (float64, bool) {
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,256 @@
+package schema_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rs/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type structAddress struct {
+	City    string `json:"city" schema:"required"`
+	Country string `json:"country,omitempty" schema:"allowed=fr|us"`
+}
+
+type structMeta struct {
+	Created time.Time `json:"created" schema:"readonly"`
+}
+
+type structUser struct {
+	structMeta
+	ID       string            `json:"id" schema:"id"`
+	Name     string            `json:"name" schema:"required,filterable,sortable,minlen=1,maxlen=150" description:"The user name"`
+	Age      *int              `json:"age,omitempty" schema:"min=0"`
+	Score    float64           `json:"score" schema:"min=0,max=1"`
+	Admin    bool              `json:"admin"`
+	Website  string            `json:"website,omitempty" schema:"url"`
+	IP       string            `json:"ip,omitempty" schema:"ip"`
+	Password string            `json:"password,omitempty" schema:"password,hidden,minlen=8"`
+	Tags     []string          `json:"tags,omitempty" schema:"maxitems=10,regexp=^[a-z,]+$"`
+	Friends  []string          `json:"friends,omitempty" schema:"ref=users"`
+	Address  *structAddress    `json:"address,omitempty"`
+	Previous []structAddress   `json:"previous,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Extra    interface{}       `json:"extra,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestFromStruct(t *testing.T) {
+	s, err := schema.FromStruct(&structUser{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, s.Fields, 15)
+
+	id := s.Fields["id"]
+	assert.True(t, id.Required)
+	assert.True(t, id.ReadOnly)
+	assert.NotNil(t, id.OnInit)
+	assert.Equal(t, schema.IDField.Validator, id.Validator)
+
+	assert.Equal(t, schema.Field{ReadOnly: true, Validator: &schema.Time{}}, s.Fields["created"])
+	assert.Equal(t, schema.Field{
+		Description: "The user name",
+		Required:    true,
+		Filterable:  true,
+		Sortable:    true,
+		Validator:   &schema.String{MinLen: 1, MaxLen: 150},
+	}, s.Fields["name"])
+	assert.Equal(t, schema.Field{
+		Validator: &schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: math.Inf(1)}},
+	}, s.Fields["age"])
+	assert.Equal(t, schema.Field{
+		Validator: &schema.Float{Boundaries: &schema.Boundaries{Min: 0, Max: 1}},
+	}, s.Fields["score"])
+	assert.Equal(t, schema.Field{Validator: &schema.Bool{}}, s.Fields["admin"])
+	assert.Equal(t, schema.Field{Validator: &schema.URL{}}, s.Fields["website"])
+	assert.Equal(t, schema.Field{Validator: &schema.IP{}}, s.Fields["ip"])
+	assert.Equal(t, schema.Field{Hidden: true, Validator: &schema.Password{MinLen: 8}}, s.Fields["password"])
+	assert.Equal(t, schema.Field{
+		Validator: &schema.Array{
+			Values: schema.Field{Validator: &schema.String{Regexp: "^[a-z,]+$"}},
+			MaxLen: 10,
+		},
+	}, s.Fields["tags"])
+	assert.Equal(t, schema.Field{
+		Validator: &schema.Array{Values: schema.Field{Validator: &schema.Reference{Path: "users"}}},
+	}, s.Fields["friends"])
+	address := &schema.Schema{Fields: schema.Fields{
+		"city":    {Required: true, Validator: &schema.String{}},
+		"country": {Validator: &schema.String{Allowed: []string{"fr", "us"}}},
+	}}
+	assert.Equal(t, schema.Field{Schema: address}, s.Fields["address"])
+	assert.Equal(t, schema.Field{
+		Validator: &schema.Array{Values: schema.Field{Validator: &schema.Object{Schema: address}}},
+	}, s.Fields["previous"])
+	assert.Equal(t, schema.Field{
+		Validator: &schema.Dict{Values: schema.Field{Validator: &schema.String{}}},
+	}, s.Fields["labels"])
+	assert.Equal(t, schema.Field{}, s.Fields["extra"])
+}
+
+func TestFromStructIntegers(t *testing.T) {
+	s, err := schema.FromStruct(struct {
+		I   int    `json:"i"`
+		I8  int8   `json:"i8"`
+		I32 int32  `json:"i32" schema:"min=0"`
+		U   uint   `json:"u"`
+		U8  uint8  `json:"u8" schema:"max=100"`
+		U16 uint16 `json:"u16" schema:"min=-1,max=1e6"`
+	}{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	for name, want := range map[string]*schema.Boundaries{
+		"i":   nil,
+		"i8":  {Min: math.MinInt8, Max: math.MaxInt8},
+		"i32": {Min: 0, Max: math.MaxInt32},
+		"u":   {Min: 0, Max: math.Inf(1)},
+		"u8":  {Min: 0, Max: 100},
+		"u16": {Min: 0, Max: math.MaxUint16},
+	} {
+		assert.Equal(t, &schema.Integer{Boundaries: want}, s.Fields[name].Validator, name)
+	}
+	_, err = s.Fields["u8"].Validator.Validate(-1)
+	assert.EqualError(t, err, "is lower than 0")
+}
+
+func TestFromStructErrors(t *testing.T) {
+	type recursive struct {
+		Children []recursive `json:"children"`
+	}
+	cases := []struct {
+		name string
+		v    interface{}
+		err  string
+	}{
+		{"NotStruct", "foo", "not a struct"},
+		{"Nil", nil, "not a struct"},
+		{"UnknownOption", struct {
+			F string `schema:"foo"`
+		}{}, "F: option foo not supported on string"},
+		{"OptionTypeMismatch", struct {
+			F bool `schema:"maxlen=3"`
+		}{}, "F: option maxlen not supported on bool"},
+		{"InvalidInt", struct {
+			F string `schema:"maxlen=x"`
+		}{}, `F: invalid maxlen option: strconv.Atoi: parsing "x": invalid syntax`},
+		{"IDType", struct {
+			F int `schema:"id"`
+		}{}, "F: option id not supported on int"},
+		{"UnsupportedType", struct {
+			F chan int
+		}{}, "F: unsupported type chan int"},
+		{"MapKey", struct {
+			F map[int]string
+		}{}, "F: unsupported map key type int"},
+		{"Duplicate", struct {
+			structMeta
+			F string `json:"created"`
+		}{}, "created: duplicate field"},
+		{"Recursive", recursive{}, "children: recursive type schema_test.recursive not supported"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := schema.FromStruct(tc.v)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestFromStructValidate(t *testing.T) {
+	s := schema.MustFromStruct(structAddress{})
+	assert.NoError(t, s.Compile(nil))
+	_, errs := s.Validate(nil, map[string]interface{}{"country": "de"})
+	assert.Equal(t, map[string][]interface{}{
+		"city":    {"required"},
+		"country": {"not one of [fr, us]"},
+	}, errs)
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	age := 42
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	u := structUser{
+		structMeta: structMeta{Created: now},
+		ID:         "abc",
+		Name:       "John",
+		Age:        &age,
+		Tags:       []string{"a", "b"},
+		Address:    &structAddress{City: "Paris"},
+		Labels:     map[string]string{"k": "v"},
+		Ignored:    "ignored",
+	}
+	p, err := schema.ToPayload(&u)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"created": now,
+		"id":      "abc",
+		"name":    "John",
+		"age":     42,
+		"score":   0.0,
+		"admin":   false,
+		"tags":    []interface{}{"a", "b"},
+		"address": map[string]interface{}{"city": "Paris"},
+		"labels":  map[string]interface{}{"k": "v"},
+	}, p)
+
+	var got structUser
+	assert.NoError(t, schema.FromPayload(p, &got))
+	u.Ignored = ""
+	assert.Equal(t, u, got)
+}
+
+func TestFromPayload(t *testing.T) {
+	var got struct {
+		I  int       `json:"i"`
+		U  uint8     `json:"u"`
+		F  float32   `json:"f"`
+		T  time.Time `json:"t"`
+		P  *string   `json:"p"`
+		A  [2]int    `json:"a"`
+		IF interface{}
+	}
+	err := schema.FromPayload(map[string]interface{}{
+		"i":       float64(3),
+		"u":       int64(255),
+		"f":       1,
+		"t":       "2018-01-02T03:04:05Z",
+		"p":       "foo",
+		"a":       []interface{}{1, 2},
+		"IF":      []interface{}{"x"},
+		"unknown": true,
+	}, &got)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.I)
+	assert.Equal(t, uint8(255), got.U)
+	assert.Equal(t, float32(1), got.F)
+	assert.Equal(t, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC), got.T)
+	assert.Equal(t, "foo", *got.P)
+	assert.Equal(t, [2]int{1, 2}, got.A)
+	assert.Equal(t, []interface{}{"x"}, got.IF)
+
+	cases := []struct {
+		name    string
+		payload map[string]interface{}
+		err     string
+	}{
+		{"Fraction", map[string]interface{}{"i": 1.5}, "i: cannot store float64 into int"},
+		{"Overflow", map[string]interface{}{"u": 256}, "u: cannot store int into uint8"},
+		{"Negative", map[string]interface{}{"u": -1}, "u: cannot store int into uint8"},
+		{"Type", map[string]interface{}{"p": 1}, "p: cannot store int into string"},
+		{"ArrayLen", map[string]interface{}{"a": []interface{}{1, 2, 3}}, "a: too many values for [2]int"},
+		{"Nested", map[string]interface{}{"a": []interface{}{"x"}}, "a[0]: cannot store string into int"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, schema.FromPayload(tc.payload, &got), tc.err)
+		})
+	}
+	assert.Equal(t, schema.ErrNotStruct, schema.FromPayload(nil, got))
+}
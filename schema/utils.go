@@ -14,3 +14,15 @@ func splitFieldPath(name string) (string, string, bool) {
 	}
 	return name, "", false
 }
+
+// getSubField returns the sub-field of f at path name, or nil if f has no such
+// sub-field.
+func getSubField(f *Field, name string) *Field {
+	if f.Schema != nil {
+		return f.Schema.GetField(name)
+	}
+	if fg, ok := f.Validator.(FieldGetter); ok {
+		return fg.GetField(name)
+	}
+	return nil
+}